@@ -43,3 +43,14 @@ func (position TPosition) Merge(other TPosition) TPosition {
 		EColm: other.EColm,
 	}
 }
+
+// Reports whether other starts within the position.
+func (position TPosition) Contains(other TPosition) bool {
+	if other.SLine < position.SLine || other.SLine > position.ELine {
+		return false
+	}
+	if other.SLine == position.SLine && other.SColm < position.SColm {
+		return false
+	}
+	return other.SLine != position.ELine || other.SColm <= position.EColm
+}
@@ -3,18 +3,47 @@ package main
 import (
 	"dev/types"
 	"fmt"
+	gotypes "go/types"
 	"strconv"
 	"strings"
+	"unicode"
+)
+
+// Go names of the equality and hash methods generated for comparable
+// structs, no method of a script is spelled like them in Go.
+const (
+	STRUCT_EQUALS = "ns_equals"
+	STRUCT_HASH   = "ns_hash"
 )
 
 type TAnalyzer struct {
-	state   *TState
-	file    TFileJob
-	scope   *TScope
-	tab     int
-	src     string
-	stack   *TEvaluationStack
-	modules []string
+	state     *TState
+	file      TFileJob
+	scope     *TScope
+	tab       int
+	src       string
+	stack     *TEvaluationStack
+	modules   []string
+	hint      *types.TTyping           // Type the next expression is stored into
+	temp      int                      // Counter for compiler generated names
+	task      *TAst                    // Run expression bound to a local of a parallel block
+	await     *TAst                    // Operand of the await expression being analyzed
+	discard   *TAst                    // Call of an expression statement, its result is not used
+	generic   *TAst                    // Name of the function being called, which may be generic
+	bridges   string                   // Go methods of the structs stored as Go interfaces, see bridge
+	discards  map[string]string        // Go code that replaces each marker written after a local, see markLocals
+	objects   map[*TAst]*types.TTyping // Type of the object of each member expression analyzed, see receiverBinding
+	constants map[string]*TAst         // Global constants not folded yet, nil while one is folded, see globalConstant
+	spelled   map[string]bool          // Identifiers written in the file, see tempName
+	copies    []TCopyBack              // Converted arguments of the call being analyzed, see passConverted
+	packages  map[string]string        // Alias each Go package is imported under, see packageAlias
+}
+
+// An array or map argument converted to a Go slice or map, the Go
+// function changes a copy of it.
+type TCopyBack struct {
+	declaration string // Declares the temporaries the argument is held in
+	copy        string // Copies the elements of the converted argument back
 }
 
 func CreateAnalyzer(state *TState, file TFileJob) *TAnalyzer {
@@ -25,6 +54,10 @@ func CreateAnalyzer(state *TState, file TFileJob) *TAnalyzer {
 	analyzer.tab = 0
 	analyzer.src = ""
 	analyzer.stack = CreateEvaluationStack()
+	analyzer.discards = make(map[string]string)
+	analyzer.objects = make(map[*TAst]*types.TTyping)
+	analyzer.constants = make(map[string]*TAst)
+	analyzer.packages = make(map[string]string)
 	return analyzer
 }
 
@@ -68,9 +101,32 @@ func (analyzer *TAnalyzer) write(part string, newline bool) {
 }
 
 func (analyzer *TAnalyzer) writePosition(position TPosition) {
+	// A library is built on another machine, the path of the script is
+	// meaningless there
+	if analyzer.state.IsLibrary() {
+		return
+	}
 	analyzer.write(fmt.Sprintf("//line %s:%d", analyzer.file.Path, position.SLine), true)
 }
 
+// Writes the documentation of a declaration that a library exports.
+// Documentation that starts with the name starts with the Go name, as
+// Go documentation does.
+func (analyzer *TAnalyzer) writeDoc(node *TAst) {
+	if !analyzer.state.IsLibrary() || !node.Flg1 || node.Str1 == "" {
+		return
+	}
+	doc := node.Str1
+	if name := " " + node.Ast0.Str0 + " "; strings.HasPrefix(doc, name) {
+		doc = " " + ToExportedName(node.Ast0.Str0) + " " + doc[len(name):]
+	}
+	// A blank line keeps the comments of the code above out of it
+	analyzer.srcNl()
+	for _, line := range strings.Split(doc, "\n") {
+		analyzer.write("//"+line, true)
+	}
+}
+
 // Begin
 
 // Should not return nil.
@@ -82,6 +138,7 @@ func (analyzer *TAnalyzer) getType(node *TAst) *types.TTyping {
 		if analyzer.file.Env.HasGlobalSymbol(node.Str0) {
 			symbol := analyzer.file.Env.GetSymbol(node.Str0)
 			analyzer.file.Env.UpdateSymbolIsUsed(node.Str0, true)
+			analyzer.reference(node, symbol)
 			if types.IsStruct(symbol.DataType) {
 				return types.ToInstance(symbol.DataType)
 			}
@@ -104,6 +161,8 @@ func (analyzer *TAnalyzer) getType(node *TAst) *types.TTyping {
 		return analyzer.state.TBit
 	case AstTypeError:
 		return analyzer.state.TErr
+	case AstTypeAny:
+		return analyzer.state.TAny
 	case AstTypeVoid:
 		return analyzer.state.TVoid
 	case AstTypeTuple:
@@ -120,6 +179,7 @@ func (analyzer *TAnalyzer) getType(node *TAst) *types.TTyping {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_TYPE,
 				INVALID_ARRAY_ELEMENT_TYPE,
 				elementAst.Position,
 			)
@@ -128,6 +188,35 @@ func (analyzer *TAnalyzer) getType(node *TAst) *types.TTyping {
 			analyzer.state.AddArrayType(elementType)
 		}
 		return types.TArray(elementType)
+	case AstTypeChan:
+		elementAst := node.Ast0
+		elementType := analyzer.getType(elementAst)
+		if elementType == nil || types.IsVoid(elementType) {
+			RaiseLanguageCompileError(
+				analyzer.file.Path,
+				analyzer.file.Data,
+				CODE_INVALID_TYPE,
+				INVALID_CHAN_ELEMENT_TYPE,
+				elementAst.Position,
+			)
+		}
+		return types.TChan(elementType, types.ChanBoth)
+	case AstTypeFuture:
+		resultAst := node.Ast0
+		resultType := analyzer.getType(resultAst)
+		if resultType == nil {
+			RaiseLanguageCompileError(
+				analyzer.file.Path,
+				analyzer.file.Data,
+				CODE_INVALID_TYPE,
+				INVALID_FUTURE_RESULT_TYPE,
+				resultAst.Position,
+			)
+		}
+		if !analyzer.state.FutureTypeExists(resultType) {
+			analyzer.state.AddFutureType(resultType)
+		}
+		return types.TFuture(resultType, node.Flg0)
 	case AstTypeHashMap:
 		keyAst := node.Ast0
 		valAst := node.Ast1
@@ -137,6 +226,7 @@ func (analyzer *TAnalyzer) getType(node *TAst) *types.TTyping {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_TYPE,
 				INVALID_HASHMAP_KEY_TYPE,
 				keyAst.Position,
 			)
@@ -145,7 +235,8 @@ func (analyzer *TAnalyzer) getType(node *TAst) *types.TTyping {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
-				INVALID_HASHMAP_KEY_TYPE,
+				CODE_INVALID_TYPE,
+				GetMapKeyError(keyType),
 				keyAst.Position,
 			)
 		}
@@ -153,6 +244,7 @@ func (analyzer *TAnalyzer) getType(node *TAst) *types.TTyping {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_TYPE,
 				INVALID_HASHMAP_VALUE_TYPE,
 				valAst.Position,
 			)
@@ -161,6 +253,7 @@ func (analyzer *TAnalyzer) getType(node *TAst) *types.TTyping {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_TYPE,
 				INVALID_HASHMAP_VALUE_TYPE,
 				valAst.Position,
 			)
@@ -176,6 +269,7 @@ func (analyzer *TAnalyzer) getType(node *TAst) *types.TTyping {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_TYPE,
 				"invalid pointer element type",
 				elementAst.Position,
 			)
@@ -189,6 +283,7 @@ func (analyzer *TAnalyzer) getType(node *TAst) *types.TTyping {
 				RaiseLanguageCompileError(
 					analyzer.file.Path,
 					analyzer.file.Data,
+					CODE_INVALID_TYPE,
 					"invalid function argument type",
 					argumentAst.Position,
 				)
@@ -200,6 +295,7 @@ func (analyzer *TAnalyzer) getType(node *TAst) *types.TTyping {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_TYPE,
 				"missing return type",
 				node.Position,
 			)
@@ -209,6 +305,7 @@ func (analyzer *TAnalyzer) getType(node *TAst) *types.TTyping {
 		RaiseLanguageCompileError(
 			analyzer.file.Path,
 			analyzer.file.Data,
+			CODE_INVALID_TYPE,
 			"invalid data type",
 			node.Position,
 		)
@@ -223,27 +320,44 @@ func (analyzer *TAnalyzer) expressionAssignLeft(node *TAst) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_UNDEFINED_SYMBOL,
 				fmt.Sprintf("undefined symbol: %s", node.Str0),
 				node.Position,
 			)
 		}
 		symbol := analyzer.scope.Env.GetSymbol(node.Str0)
+		analyzer.reference(node, symbol)
+		if types.IsInvalid(symbol.DataType) {
+			Abort()
+		}
 		if symbol.IsConst {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_ASSIGN_CONSTANT,
 				fmt.Sprintf("cannot assign to constant symbol: %s", node.Str0),
 				node.Position,
+				NoteDeclaredAt(symbol),
 			)
 		}
 		if types.IsStruct(symbol.DataType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_ASSIGN_CONSTANT,
 				fmt.Sprintf("cannot assign to struct symbol: %s", node.Str0),
 				node.Position,
 			)
 		}
+		if symbol.IsTask {
+			RaiseLanguageCompileError(
+				analyzer.file.Path,
+				analyzer.file.Data,
+				CODE_CONCURRENCY,
+				fmt.Sprintf("cannot assign to future %s started in a parallel block", node.Str0),
+				node.Position,
+			)
+		}
 		analyzer.write(symbol.NameSpace, false)
 		analyzer.stack.Push(CreateValue(
 			symbol.DataType,
@@ -252,27 +366,51 @@ func (analyzer *TAnalyzer) expressionAssignLeft(node *TAst) {
 	case AstMember:
 		objectNode := node.Ast0
 		memberNode := node.Ast1
+		if _, ok := analyzer.namespaceMember(node); ok {
+			RaiseLanguageCompileError(
+				analyzer.file.Path,
+				analyzer.file.Data,
+				CODE_ASSIGN_CONSTANT,
+				fmt.Sprintf("cannot assign to constant symbol: %s", memberNode.Str0),
+				node.Position,
+			)
+		}
 		if memberNode.Ttype != AstIDN {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_MISSING_NAME,
 				"member name must be an identifier",
 				memberNode.Position,
 			)
 		}
 		analyzer.expression(objectNode)
 		objectType := analyzer.stack.Pop().DataType
+		analyzer.objects[node] = objectType
 		if !objectType.HasMember(memberNode.Str0) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_UNKNOWN_MEMBER,
 				fmt.Sprintf("object %s has no member %s", objectType.ToString(), memberNode.Str0),
 				memberNode.Position,
 			)
 		}
+		if !types.IsPointer(objectType) {
+			if _, symbol := analyzer.receiverBinding(objectNode); symbol != nil && symbol.IsConst {
+				RaiseLanguageCompileError(
+					analyzer.file.Path,
+					analyzer.file.Data,
+					CODE_ASSIGN_CONSTANT,
+					fmt.Sprintf("cannot assign to member of constant symbol: %s", symbol.Name),
+					memberNode.Position,
+				)
+			}
+		}
 		analyzer.write(".", false)
 		analyzer.write(memberNode.Str0, false)
 		member := objectType.GetMember(memberNode.Str0)
+		analyzer.memberReference(memberNode, objectType, member, false)
 		analyzer.stack.Push(CreateValue(
 			member.DataType,
 			nil,
@@ -295,24 +433,26 @@ func (analyzer *TAnalyzer) expressionAssignLeft(node *TAst) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_ASSIGN_CONSTANT,
 				fmt.Sprintf("cannot assign to %s", objectType.ToString()),
 				node.Position,
 			)
 		}
 		analyzer.write("[", false)
-		analyzer.expression(indexNode)
-		indexType := analyzer.stack.Pop().DataType
-		if types.IsArray(indexType) && !types.IsAnyInt(indexType) {
+		indexType := analyzer.indexExpression(indexNode, objectType).DataType
+		if types.IsArray(objectType) && !types.IsAnyInt(indexType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_TYPE_MISMATCH,
 				fmt.Sprintf("array index must be an integer, got %s", indexType.ToString()),
 				node.Position,
 			)
-		} else if types.IsMap(objectType) && !types.IsTheSameInstance(indexType, objectType.GetInternal0()) {
+		} else if types.IsMap(objectType) && !types.CanStore(objectType.GetInternal0(), indexType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_TYPE_MISMATCH,
 				fmt.Sprintf("map index must be %s, got %s", objectType.GetInternal0().ToString(), indexType.ToString()),
 				node.Position,
 			)
@@ -339,166 +479,1455 @@ func (analyzer *TAnalyzer) expressionAssignLeft(node *TAst) {
 		RaiseLanguageCompileError(
 			analyzer.file.Path,
 			analyzer.file.Data,
+			CODE_INVALID_OPERATION,
 			"invalid left-hand side of assignment",
 			node.Position,
 		)
 	}
 }
 
-func (analyzer *TAnalyzer) expression(node *TAst) {
-	switch node.Ttype {
-	case AstIDN:
-		if !analyzer.scope.Env.HasGlobalSymbol(node.Str0) {
+// Calling a function that may panic is only allowed
+// from a function that declares 'panics'.
+func (analyzer *TAnalyzer) checkPanics(funcType *types.TTyping, node *TAst) {
+	action := "call function"
+	if types.IsFuture(funcType) {
+		action = "await future"
+	}
+	if funcType.Panics() && analyzer.scope.InFunction() {
+		current := analyzer.scope
+		for current.Type != ScopeFunction {
+			current = current.Parent
+		}
+		if current.Type == ScopeFunction && !current.Panics {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
-				fmt.Sprintf("undefined symbol: %s", node.Str0),
+				CODE_PANICS,
+				fmt.Sprintf("cannot %s '%s' that may panic from a function that does not declare 'panics'", action, funcType.ToString()),
 				node.Position,
 			)
 		}
+		current.HasPanic = true
+	} else if funcType.Panics() && analyzer.scope.InGlobal() {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_PANICS,
+			fmt.Sprintf("cannot %s that may panic from the global scope", action),
+			node.Position,
+		)
+	}
+}
+
+// Evaluates the expression into a separate buffer,
+// so that the caller decides how the operand is written.
+func (analyzer *TAnalyzer) captureExpression(node *TAst) (string, TValue) {
+	saveSrc := analyzer.src
+	analyzer.src = ""
+	analyzer.expression(node)
+	value := analyzer.stack.Pop()
+	captured := analyzer.src
+	analyzer.src = saveSrc
+	return captured, value
+}
+
+// Resolves the storage behind a method receiver or assignment target.
+// Temporaries (calls, literals, indexing) are not addressable. The returned
+// symbol is the binding that owns the value, or nil once a pointer is crossed.
+func (analyzer *TAnalyzer) receiverBinding(node *TAst) (bool, *TSymbol) {
+	switch node.Ttype {
+	case AstIDN:
+		if !analyzer.scope.Env.HasGlobalSymbol(node.Str0) {
+			return false, nil
+		}
 		symbol := analyzer.scope.Env.GetSymbol(node.Str0)
-		analyzer.scope.Env.UpdateSymbolIsUsed(node.Str0, true)
-		analyzer.write(symbol.NameSpace, false)
-		// Struct cannot be used as a value.
-		if types.IsStruct(symbol.DataType) {
-			RaiseLanguageCompileError(
-				analyzer.file.Path,
-				analyzer.file.Data,
-				fmt.Sprintf("struct %s cannot be used as a value", symbol.DataType.ToString()),
-				node.Position,
-			)
+		if types.IsStruct(symbol.DataType) || types.IsFunc(symbol.DataType) {
+			return false, nil
 		}
-		analyzer.stack.Push(CreateValue(
-			symbol.DataType,
-			nil,
-		))
-	case AstInt:
-		i64, err := strconv.ParseInt(node.Str0, 10, 64)
-		if err != nil {
+		return true, &symbol
+	case AstMember:
+		if symbol, ok := analyzer.namespaceMember(node); ok {
+			if types.IsStruct(symbol.DataType) || types.IsFunc(symbol.DataType) {
+				return false, nil
+			}
+			return true, &symbol
+		}
+		// The member is analyzed already, its object is not analyzed again
+		if objectType, ok := analyzer.objects[node]; ok && types.IsPointer(objectType) {
+			return true, nil
+		}
+		return analyzer.receiverBinding(node.Ast0)
+	default:
+		return false, nil
+	}
+}
+
+// Evaluates an expression that is stored into dataType and returns its source.
+// Literals adopt the destination type, and numbers are converted where Go
+// would not widen them implicitly.
+func (analyzer *TAnalyzer) captureStore(node *TAst, dataType *types.TTyping) (string, TValue) {
+	analyzer.hint = dataType
+	src, value := analyzer.captureExpression(node)
+	return analyzer.convert(src, value, dataType), value
+}
+
+func (analyzer *TAnalyzer) storeExpression(node *TAst, dataType *types.TTyping) TValue {
+	src, value := analyzer.captureStore(node, dataType)
+	analyzer.write(src, false)
+	return value
+}
+
+// Emits an array index as i64 and a map key as the key type of the map.
+func (analyzer *TAnalyzer) indexExpression(node *TAst, objectType *types.TTyping) TValue {
+	if types.IsArray(objectType) {
+		return analyzer.storeExpression(node, analyzer.state.TI64)
+	}
+	if types.IsMap(objectType) {
+		return analyzer.storeExpression(node, objectType.GetInternal0())
+	}
+	analyzer.expression(node)
+	return analyzer.stack.Pop()
+}
+
+// Wraps a number in a conversion to the Go type of dataType.
+// Untyped literals are left alone, since Go types them from context.
+func (analyzer *TAnalyzer) convert(src string, value TValue, dataType *types.TTyping) string {
+	if types.GoInterface(dataType) != nil && types.IsScriptStruct(value.DataType) && types.ImplementsGo(dataType, value.DataType) == nil {
+		analyzer.bridge(value.DataType, dataType)
+	}
+	if types.IsAny(dataType) && types.IsAnyInt(value.DataType) && value.Untyped {
+		// Go would box an untyped integer as int, which no type test
+		// matches, the script's integer is i64
+		return fmt.Sprintf("%s(%s)", types.TInt64().GoValueType(), src)
+	}
+	if !types.IsAnyNumber(value.DataType) || !types.IsAnyNumber(dataType) {
+		return src
+	}
+	goType := dataType.GoValueType()
+	if value.DataType.GoValueType() == goType {
+		return src
+	}
+	if value.Untyped {
+		return src
+	}
+	return fmt.Sprintf("%s(%s)", goType, src)
+}
+
+// Spells the package of a Go type written in Go source, and imports it
+// under an alias, which no local of the script shadows.
+func (analyzer *TAnalyzer) qualifier(pkg *gotypes.Package) string {
+	return analyzer.packageAlias(pkg.Path(), pkg.Name())
+}
+
+// Declares the Go methods through which Go calls the methods of the struct
+// structType, which is stored as the Go interface dataType. They convert
+// the Go values like a call to Go does, in the other direction.
+func (analyzer *TAnalyzer) bridge(structType *types.TTyping, dataType *types.TTyping) {
+	if types.IsPointer(structType) {
+		structType = structType.GetInternal0()
+	}
+	structName := structType.ToGoType()
+	iface := types.GoInterface(dataType)
+	for i := 0; i < iface.NumMethods(); i++ {
+		goMethod := iface.Method(i)
+		if analyzer.state.HasBridge(structName, goMethod.Name()) {
+			continue
+		}
+		analyzer.state.AddBridge(structName, goMethod.Name())
+		method := structType.GetMethod(goMethod.Name())
+		if method.Namespace == goMethod.Name() {
+			// Exported by a library, the method already is the Go one
+			continue
+		}
+		signature := goMethod.Type().(*gotypes.Signature)
+		goType := analyzer.state.GoTypes.FromType(signature)
+
+		receiver := structName
+		if method.DataType.PointerReceiver() {
+			receiver = "*" + structName
+		}
+		parameters := make([]string, 0)
+		arguments := make([]string, 0)
+		for j := 0; j < signature.Params().Len(); j++ {
+			name := fmt.Sprintf("p%d", j)
+			parameters = append(parameters, name+" "+gotypes.TypeString(signature.Params().At(j).Type(), analyzer.qualifier))
+			arguments = append(arguments, analyzer.fromGo(name, goType.GetMembers()[j].DataType))
+		}
+		call := fmt.Sprintf("instance.%s(%s)", method.Namespace, strings.Join(arguments, ", "))
+		results := make([]string, 0)
+		for j := 0; j < signature.Results().Len(); j++ {
+			results = append(results, gotypes.TypeString(signature.Results().At(j).Type(), analyzer.qualifier))
+		}
+
+		analyzer.bridges += fmt.Sprintf("\nfunc (instance %s) %s(%s) (%s) {\n", receiver, goMethod.Name(), strings.Join(parameters, ", "), strings.Join(results, ", "))
+		returnType := goType.GetReturnType()
+		switch len(results) {
+		case 0:
+			analyzer.bridges += fmt.Sprintf("\t%s\n", call)
+		case 1:
+			analyzer.bridges += fmt.Sprintf("\treturn %s\n", analyzer.toGo(call, returnType))
+		default:
+			names := make([]string, 0)
+			values := make([]string, 0)
+			for j, element := range returnType.GetElements() {
+				names = append(names, fmt.Sprintf("r%d", j))
+				values = append(values, analyzer.toGo(names[j], element))
+			}
+			analyzer.bridges += fmt.Sprintf("\t%s := %s\n", strings.Join(names, ", "), call)
+			analyzer.bridges += fmt.Sprintf("\treturn %s\n", strings.Join(values, ", "))
+		}
+		analyzer.bridges += "}\n"
+	}
+}
+
+// Returns the type a Go value has in a script. Slices and maps become
+// arrays and maps, whose numbers have the width the script tracks.
+func (analyzer *TAnalyzer) nativeType(dataType *types.TTyping) *types.TTyping {
+	switch {
+	case types.IsGoArray(dataType):
+		elementType := analyzer.nativeType(dataType.GetInternal0())
+		if !analyzer.state.ArrayTypeExists(elementType) {
+			analyzer.state.AddArrayType(elementType)
+		}
+		return types.TArray(elementType)
+	case types.IsGoMap(dataType):
+		keyType := analyzer.nativeType(dataType.GetInternal0())
+		valueType := analyzer.nativeType(dataType.GetInternal1())
+		if !analyzer.state.MapTypeExists(keyType, valueType) {
+			analyzer.state.AddMapType(keyType, valueType)
+		}
+		return types.THashMap(keyType, valueType)
+	case types.IsInt08(dataType):
+		return analyzer.state.TI08
+	case types.IsInt16(dataType):
+		return analyzer.state.TI16
+	case types.IsInt32(dataType):
+		return analyzer.state.TI32
+	case types.IsInt64(dataType):
+		return analyzer.state.TI64
+	case types.IsNum(dataType):
+		return analyzer.state.TNum
+	case types.IsTuple(dataType):
+		elements := make([]*types.TTyping, 0)
+		for _, element := range dataType.GetElements() {
+			elements = append(elements, analyzer.nativeType(element))
+		}
+		return types.TTuple(elements)
+	default:
+		return dataType
+	}
+}
+
+// Spells the Go type of a value imported from Go, with the basic
+// type its numbers have in Go, such as "[]int" rather than "[]int64".
+func goValueType(dataType *types.TTyping) string {
+	switch {
+	case types.IsGoArray(dataType):
+		return "[]" + goValueType(dataType.GetInternal0())
+	case types.IsGoMap(dataType):
+		return "map[" + goValueType(dataType.GetInternal0()) + "]" + goValueType(dataType.GetInternal1())
+	default:
+		return dataType.GoValueType()
+	}
+}
+
+// Reports whether a Go value of dataType is converted where it enters a script.
+func needsNative(dataType *types.TTyping) bool {
+	if types.IsTuple(dataType) {
+		for _, element := range dataType.GetElements() {
+			if needsNative(element) {
+				return true
+			}
+		}
+		return false
+	}
+	return types.IsGoArray(dataType) || types.IsGoMap(dataType)
+}
+
+// Converts src, a Go value of dataType, to its type in a script.
+// A slice or map is wrapped without a copy when its elements already have
+// the Go type of the script, so Go and the script share the elements.
+// Otherwise the elements are converted into a new array or map.
+func (analyzer *TAnalyzer) fromGo(src string, dataType *types.TTyping) string {
+	nativeType := analyzer.nativeType(dataType)
+	switch {
+	case types.IsGoArray(dataType):
+		elementType := nativeType.GetInternal0()
+		element := analyzer.fromGo("v", dataType.GetInternal0())
+		if element == "v" {
+			return fmt.Sprintf("%s(%s)", GetArrayWrapper(elementType), src)
+		}
+		return fmt.Sprintf(
+			"func() %s { s := %s; r := make([]%s, len(s)); for i, v := range s { r[i] = %s }; return %s(r) }()",
+			nativeType.ToGoType(), src, elementType.ToGoType(), element, GetArrayWrapper(elementType),
+		)
+	case types.IsGoMap(dataType):
+		keyType := nativeType.GetInternal0()
+		valueType := nativeType.GetInternal1()
+		key := analyzer.fromGo("k", dataType.GetInternal0())
+		value := analyzer.fromGo("v", dataType.GetInternal1())
+		if key == "k" && value == "v" {
+			return fmt.Sprintf("%s(%s)", GetMapWrapper(keyType, valueType), src)
+		}
+		return fmt.Sprintf(
+			"func() %s { m := %s; r := make(map[%s]%s, len(m)); for k, v := range m { r[%s] = %s }; return %s(r) }()",
+			nativeType.ToGoType(), src, keyType.ToGoType(), valueType.ToGoType(), key, value, GetMapWrapper(keyType, valueType),
+		)
+	case types.IsTuple(dataType):
+		if !needsNative(dataType) {
+			return src
+		}
+		results := make([]string, 0)
+		values := make([]string, 0)
+		for index, element := range dataType.GetElements() {
+			result := fmt.Sprintf("r%d", index)
+			results = append(results, result)
+			values = append(values, analyzer.fromGo(result, element))
+		}
+		return fmt.Sprintf(
+			"func() %s { %s := %s; return %s }()",
+			nativeType.ToGoType(), strings.Join(results, ", "), src, strings.Join(values, ", "),
+		)
+	case types.IsAnyNumber(dataType) && dataType.GoValueType() != nativeType.ToGoType():
+		return fmt.Sprintf("%s(%s)", nativeType.ToGoType(), src)
+	default:
+		return src
+	}
+}
+
+// Converts src, a value of the type fromGo returns for dataType, back
+// to the Go value of dataType. The inverse of fromGo, it only copies
+// the elements when their Go type differs from the one of the script.
+func (analyzer *TAnalyzer) toGo(src string, dataType *types.TTyping) string {
+	switch {
+	case types.IsGoArray(dataType):
+		elementType := dataType.GetInternal0()
+		element := analyzer.toGo("v", elementType)
+		if element == "v" {
+			return fmt.Sprintf("%s.elements", src)
+		}
+		return fmt.Sprintf(
+			"func() %s { s := %s; r := make(%s, s.length); for i, v := range s.elements { r[i] = %s }; return r }()",
+			goValueType(dataType), src, goValueType(dataType), element,
+		)
+	case types.IsGoMap(dataType):
+		key := analyzer.toGo("k", dataType.GetInternal0())
+		value := analyzer.toGo("v", dataType.GetInternal1())
+		if key == "k" && value == "v" {
+			return fmt.Sprintf("%s.elements", src)
+		}
+		return fmt.Sprintf(
+			"func() %s { m := %s; r := make(%s, len(m.elements)); for k, v := range m.elements { r[%s] = %s }; return r }()",
+			goValueType(dataType), src, goValueType(dataType), key, value,
+		)
+	case types.IsAnyNumber(dataType) && dataType.GoValueType() != analyzer.nativeType(dataType).ToGoType():
+		return fmt.Sprintf("%s(%s)", dataType.GoValueType(), src)
+	default:
+		return src
+	}
+}
+
+// Reports whether node names the given built-in function,
+// which is not shadowed by a symbol of the script.
+func (analyzer *TAnalyzer) isBuiltin(node *TAst, name string) bool {
+	if node.Ttype != AstIDN || !analyzer.scope.Env.HasGlobalSymbol(node.Str0) {
+		return false
+	}
+	symbol := analyzer.scope.Env.GetSymbol(node.Str0)
+	return symbol.Module == MODULE_GLOBAL && symbol.NameSpace == name
+}
+
+// Emits the function being called and returns its value.
+// A method is written as "receiver.Method", which Go evaluates
+// to a method value when it is not called right away.
+func (analyzer *TAnalyzer) callee(objectNode *TAst) TValue {
+	// A member of a namespace import is called like any other symbol
+	if _, ok := analyzer.namespaceMember(objectNode); objectNode.Ttype == AstMember && !ok {
+		member_obj := objectNode.Ast0
+		member_name := objectNode.Ast1
+		if member_name.Ttype != AstIDN {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
-				fmt.Sprintf("invalid integer value %s", node.Str0),
-				node.Position,
+				CODE_MISSING_NAME,
+				"member name must be an identifier",
+				member_name.Position,
 			)
 		}
-		analyzer.write(node.Str0, false)
-		switch SizeOfInt(i64) {
-		case 8:
-			analyzer.stack.Push(CreateValue(
-				analyzer.state.TI08,
-				int8(i64),
-			))
-		case 16:
-			analyzer.stack.Push(CreateValue(
-				analyzer.state.TI16,
-				int16(i64),
-			))
-		case 32:
-			analyzer.stack.Push(CreateValue(
-				analyzer.state.TI32,
-				int32(i64),
-			))
-		case 64:
-			analyzer.stack.Push(CreateValue(
-				analyzer.state.TI64,
-				i64,
-			))
-		default:
-			analyzer.stack.Push(CreateValue(
-				analyzer.state.TI08,
-				int8(i64),
-			))
-		}
-	case AstNum:
-		f64, err := strconv.ParseFloat(node.Str0, 64)
-		if err != nil {
+		analyzer.expression(member_obj)
+		member_obj_value := analyzer.stack.Pop()
+		if !member_obj_value.DataType.HasMethod(member_name.Str0) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
-				fmt.Sprintf("invalid float value %s", node.Str0),
-				node.Position,
+				CODE_UNKNOWN_MEMBER,
+				fmt.Sprintf("object %s has no method %s", member_obj_value.DataType.ToString(), member_name.Str0),
+				member_name.Position,
 			)
 		}
-		analyzer.write(node.Str0, false)
-		analyzer.stack.Push(CreateValue(
-			analyzer.state.TNum,
-			f64,
-		))
-	case AstStr:
-		analyzer.write(fmt.Sprintf("\"%s\"", node.Str0), false)
-		analyzer.stack.Push(CreateValue(
-			analyzer.state.TStr,
-			node.Str0,
-		))
-	case AstBool:
-		analyzer.write(node.Str0, false)
-		analyzer.stack.Push(CreateValue(
-			analyzer.state.TBit,
-			node.Str0 == "true",
-		))
-	case AstNull:
-		analyzer.write("nil", false)
-		analyzer.stack.Push(CreateValue(
-			analyzer.state.TNil,
-			nil,
-		))
-	case AstTupleExpression:
-		tupleTypes := make([]*types.TTyping, 0)
-		for index, childNode := range node.AstArr0 {
-			analyzer.expression(childNode)
-			dataType := analyzer.stack.Pop().DataType
-			tupleTypes = append(tupleTypes, dataType)
-			if index < len(node.AstArr0)-1 {
-				analyzer.write(", ", false)
+		method := member_obj_value.DataType.GetMethod(member_name.Str0)
+		analyzer.memberReference(member_name, member_obj_value.DataType, method, false)
+		if method.DataType.PointerReceiver() && !types.IsPointer(member_obj_value.DataType) {
+			// Go takes the address of addressable receivers implicitly,
+			// so only temporaries and constants have to be rejected here.
+			addressable, symbol := analyzer.receiverBinding(member_obj)
+			if !addressable {
+				RaiseLanguageCompileError(
+					analyzer.file.Path,
+					analyzer.file.Data,
+					CODE_INVALID_OPERATION,
+					fmt.Sprintf("cannot call pointer method %s on non-addressable %s", member_name.Str0, member_obj_value.DataType.ToString()),
+					member_name.Position,
+				)
+			} else if symbol != nil && symbol.IsConst {
+				RaiseLanguageCompileError(
+					analyzer.file.Path,
+					analyzer.file.Data,
+					CODE_ASSIGN_CONSTANT,
+					fmt.Sprintf("cannot call mutating method %s on constant symbol: %s", member_name.Str0, symbol.Name),
+					member_name.Position,
+				)
 			}
 		}
+		analyzer.write(".", false)
+		analyzer.write(method.Namespace, false)
 		analyzer.stack.Push(CreateValue(
-			types.TTuple(tupleTypes),
+			method.DataType,
 			nil,
 		))
-	case AstArray:
-		saveSrc := analyzer.src
-		saveStack := analyzer.stack
-		analyzer.src = ""
-		analyzer.stack = CreateEvaluationStack()
-		elementsNode := node.AstArr0
-		var elementType *types.TTyping = nil // Default type.
-		for index, childNode := range elementsNode {
-			analyzer.expression(childNode)
-			topType := analyzer.stack.Pop().DataType
-			if elementType != nil {
-				if !types.CanStore(elementType, topType) {
-					RaiseLanguageCompileError(
-						analyzer.file.Path,
-						analyzer.file.Data,
-						fmt.Sprintf("cannot store %s in array of [%s]", topType.ToString(), elementType.ToString()),
-						childNode.Position,
-					)
-				}
-				elementType = types.WhichBigger(elementType, topType)
+	} else {
+		analyzer.expression(objectNode)
+	}
+	objectValue := analyzer.stack.Pop()
+	if !types.IsFunc(objectValue.DataType) {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_INVALID_OPERATION,
+			fmt.Sprintf("cannot call %s", objectValue.DataType.ToString()),
+			objectNode.Position,
+		)
+	}
+	return objectValue
+}
+
+// Checks the arguments of a call against the parameters of funcType
+// and returns each argument converted to the type it is passed as.
+func (analyzer *TAnalyzer) callArguments(funcType *types.TTyping, objectNode *TAst, parametersNode []*TAst) []string {
+	arguments := make([]string, 0)
+	members := funcType.GetMembers()
+	requiredParameters := members
+	if funcType.Variadic() {
+		requiredParameters = requiredParameters[:len(requiredParameters)-1]
+	}
+	if !funcType.Variadic() && len(requiredParameters) == len(parametersNode) {
+		for index, childNode := range parametersNode {
+			arguments = append(arguments, analyzer.argument(childNode, requiredParameters[index].DataType))
+		}
+	} else if funcType.Variadic() && len(requiredParameters) <= len(parametersNode) {
+		theVariadictParmeter := members[len(members)-1]
+		for index, childNode := range parametersNode {
+			if index < len(requiredParameters) {
+				arguments = append(arguments, analyzer.argument(childNode, requiredParameters[index].DataType))
 			} else {
+				arguments = append(arguments, analyzer.argument(childNode, theVariadictParmeter.DataType))
+			}
+		}
+	} else {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_ARGUMENT_COUNT,
+			fmt.Sprintf("expected %d parameters, got %d", len(requiredParameters), len(parametersNode)),
+			objectNode.Position,
+		)
+	}
+	return arguments
+}
+
+// Checks an argument against the parameter type and returns it converted.
+// An array or map passed to a Go slice or map is unwrapped, see toGo.
+func (analyzer *TAnalyzer) argument(childNode *TAst, requiredType *types.TTyping) string {
+	src, value := analyzer.captureStore(childNode, analyzer.parameterType(requiredType))
+	return analyzer.passArgument(childNode, src, value, requiredType)
+}
+
+// Type an argument has in the script when it is passed as requiredType.
+func (analyzer *TAnalyzer) parameterType(requiredType *types.TTyping) *types.TTyping {
+	if needsNative(requiredType) {
+		return analyzer.nativeType(requiredType)
+	}
+	return requiredType
+}
+
+// Checks the analyzed argument src and returns it converted, see argument.
+func (analyzer *TAnalyzer) passArgument(childNode *TAst, src string, value TValue, requiredType *types.TTyping) string {
+	parameterType := analyzer.parameterType(requiredType)
+	actualType := value.DataType
+	if !types.CanStore(parameterType, actualType) {
+		message := fmt.Sprintf("expected %s, got %s", parameterType.ToString(), actualType.ToString())
+		if types.GoInterface(parameterType) != nil && types.IsScriptStruct(actualType) {
+			message += ": " + types.ImplementsGo(parameterType, actualType).Error()
+		}
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_TYPE_MISMATCH,
+			message,
+			childNode.Position,
+		)
+	}
+	if needsNative(requiredType) {
+		return analyzer.passConverted(src, requiredType)
+	}
+	return src
+}
+
+// Converts an array or map argument to the Go slice or map requiredType.
+// When its elements are converted the Go function gets a copy, which it
+// may change in place, the call copies the elements back when it returns.
+func (analyzer *TAnalyzer) passConverted(src string, requiredType *types.TTyping) string {
+	if !types.IsGoArray(requiredType) && !types.IsGoMap(requiredType) {
+		return analyzer.toGo(src, requiredType)
+	}
+	object := analyzer.tempName("object")
+	converted := analyzer.toGo(object, requiredType)
+	if converted == object+".elements" {
+		// The Go function shares the elements of the array or map
+		return analyzer.toGo(src, requiredType)
+	}
+	argument := analyzer.tempName("arg")
+	copy := fmt.Sprintf("for i, v := range %s { %s.elements[i] = %s }", argument, object, analyzer.fromGo("v", requiredType.GetInternal0()))
+	if types.IsGoMap(requiredType) {
+		copy = fmt.Sprintf(
+			"if %s != nil { clear(%s.elements); for k, v := range %s { %s.elements[%s] = %s } }",
+			argument, object, argument, object, analyzer.fromGo("k", requiredType.GetInternal0()), analyzer.fromGo("v", requiredType.GetInternal1()),
+		)
+	}
+	analyzer.copies = append(analyzer.copies, TCopyBack{
+		declaration: fmt.Sprintf("var %s %s; var %s %s", object, analyzer.nativeType(requiredType).ToGoType(), argument, goValueType(requiredType)),
+		copy:        copy,
+	})
+	return fmt.Sprintf("func() %s { %s = %s; %s = %s; return %s }()", goValueType(requiredType), object, src, argument, converted, argument)
+}
+
+// Wraps a call whose arguments are converted copies, see passConverted,
+// to copy their elements back once it returns, or panics.
+func (analyzer *TAnalyzer) copyBackCall(call string, funcType *types.TTyping, copies []TCopyBack) string {
+	statements := make([]string, 0)
+	for _, copyBack := range copies {
+		if copyBack.declaration != "" {
+			statements = append(statements, copyBack.declaration)
+		}
+		statements = append(statements, fmt.Sprintf("defer func() { %s }()", copyBack.copy))
+	}
+	returnType := funcType.GetReturnType()
+	if types.IsVoid(returnType) {
+		return fmt.Sprintf("func() { %s; %s }()", strings.Join(statements, "; "), call)
+	}
+	// Types of Go results are spelled as Go does, their structs may not
+	// be imported by the script
+	results := returnType.ToGoType()
+	if signature, ok := types.GoType(funcType).(*gotypes.Signature); ok {
+		spelled := make([]string, 0)
+		for i := 0; i < signature.Results().Len(); i++ {
+			spelled = append(spelled, gotypes.TypeString(signature.Results().At(i).Type(), analyzer.qualifier))
+		}
+		results = strings.Join(spelled, ", ")
+		if len(spelled) > 1 {
+			results = "(" + results + ")"
+		}
+	}
+	return fmt.Sprintf("func() %s { %s; return %s }()", results, strings.Join(statements, "; "), call)
+}
+
+// Instantiates a call to a generic Go function from the types of its
+// arguments. Emits the type arguments after the function, which has been
+// written already, and returns the instantiated function type and the
+// converted arguments.
+func (analyzer *TAnalyzer) genericCall(funcType *types.TTyping, objectNode *TAst, parametersNode []*TAst) (*types.TTyping, []string) {
+	sources := make([]string, 0)
+	values := make([]TValue, 0)
+	argumentTypes := make([]*types.TTyping, 0)
+	for _, childNode := range parametersNode {
+		src, value := analyzer.captureExpression(childNode)
+		sources = append(sources, src)
+		values = append(values, value)
+		argumentTypes = append(argumentTypes, analyzer.nativeType(value.DataType))
+	}
+	instance, typeArguments, err := types.Instantiate(funcType, argumentTypes)
+	if err != nil {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_GENERIC,
+			fmt.Sprintf(INVALID_GENERIC_CALL, err.Error()),
+			objectNode.Position,
+		)
+	}
+	goTypes := make([]string, 0)
+	for _, typeArgument := range typeArguments {
+		goTypes = append(goTypes, typeArgument.ToGoType())
+	}
+	analyzer.write(fmt.Sprintf("[%s]", strings.Join(goTypes, ", ")), false)
+
+	members := instance.GetMembers()
+	requiredParameters := members
+	if instance.Variadic() {
+		requiredParameters = requiredParameters[:len(requiredParameters)-1]
+	}
+	if len(parametersNode) < len(requiredParameters) || (!instance.Variadic() && len(parametersNode) > len(requiredParameters)) {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_ARGUMENT_COUNT,
+			fmt.Sprintf("expected %d parameters, got %d", len(requiredParameters), len(parametersNode)),
+			objectNode.Position,
+		)
+	}
+	arguments := make([]string, 0)
+	for index, childNode := range parametersNode {
+		requiredType := members[len(members)-1].DataType
+		if index < len(requiredParameters) {
+			requiredType = requiredParameters[index].DataType
+		}
+		src := analyzer.convert(sources[index], values[index], analyzer.parameterType(requiredType))
+		arguments = append(arguments, analyzer.passArgument(childNode, src, values[index], requiredType))
+	}
+	return instance, arguments
+}
+
+// Binds the function value and the arguments of a run call to temporaries.
+// Like Go's go statement, they are evaluated before the task starts, so the
+// returned call, which the task closes over, only refers to the temporaries.
+func (analyzer *TAnalyzer) runCall(node *TAst) ([]string, string, *types.TTyping) {
+	callNode := node.Ast0
+	if callNode.Ttype != AstCall {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_CONCURRENCY,
+			"invalid run expression, run expression must be a function call",
+			node.Position,
+		)
+	}
+	objectNode := callNode.Ast0
+	if analyzer.isBuiltin(objectNode, GLOBAL_CLOSE) {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_CONCURRENCY,
+			"cannot run close, close channels from a function instead",
+			objectNode.Position,
+		)
+	}
+	saveSrc := analyzer.src
+	analyzer.src = ""
+	funcType := analyzer.callee(objectNode).DataType
+	calleeSrc := analyzer.src
+	analyzer.src = saveSrc
+	copies := analyzer.copies
+	analyzer.copies = nil
+	arguments := analyzer.callArguments(funcType, objectNode, callNode.AstArr0)
+
+	bindings := make([]string, 0)
+	// The temporaries of converted arguments are bound before the task
+	// starts, the task copies the elements back when its call returns
+	deferred := make([]TCopyBack, 0)
+	for _, copyBack := range analyzer.copies {
+		bindings = append(bindings, copyBack.declaration)
+		deferred = append(deferred, TCopyBack{copy: copyBack.copy})
+	}
+	analyzer.copies = copies
+	// Builtins are not values in Go, they are called by name
+	if !analyzer.isBuiltin(objectNode, objectNode.Str0) {
+		callee := analyzer.tempName("callee")
+		bindings = append(bindings, fmt.Sprintf("%s := %s", callee, calleeSrc))
+		calleeSrc = callee
+	}
+	parameters := funcType.GetMembers()
+	for index, argument := range arguments {
+		parameter := parameters[min(index, len(parameters)-1)]
+		name := analyzer.tempName("arg")
+		bindings = append(bindings, fmt.Sprintf("var %s %s = %s", name, goValueType(parameter.DataType), argument))
+		arguments[index] = name
+	}
+	call := fmt.Sprintf("%s(%s)", calleeSrc, strings.Join(arguments, ", "))
+	if len(deferred) > 0 {
+		call = analyzer.copyBackCall(call, funcType, deferred)
+	}
+	return bindings, call, funcType
+}
+
+// Emits "run f(x)" as a future, which only re-raises a panic of the
+// task once it is awaited. Within a parallel block the future must be
+// bound to a local of the block, and the block also waits for it.
+func (analyzer *TAnalyzer) runExpression(node *TAst) {
+	parallel := analyzer.scope.Parallel()
+	if parallel != nil && analyzer.task != node {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_CONCURRENCY,
+			"a future started in a parallel block must be bound to a local variable of the block",
+			node.Position,
+		)
+	}
+	bindings, call, funcType := analyzer.runCall(node)
+	resultType := funcType.GetReturnType()
+	if types.IsTuple(resultType) {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_CONCURRENCY,
+			fmt.Sprintf("cannot run %s, a future holds a single result", funcType.ToString()),
+			node.Ast0.Position,
+		)
+	}
+	if !analyzer.state.FutureTypeExists(resultType) {
+		analyzer.state.AddFutureType(resultType)
+	}
+	futureType := types.TFuture(resultType, funcType.Panics())
+
+	task := fmt.Sprintf("func() { %s }", call)
+	if !types.IsVoid(resultType) {
+		task = fmt.Sprintf("func() %s { return %s }", resultType.ToGoType(), call)
+	}
+	if parallel != nil {
+		// The block re-raises the panic when it waits for the future
+		analyzer.checkPanics(funcType, node.Ast0)
+		future := analyzer.tempName("future")
+		bindings = append(bindings, fmt.Sprintf("%s := %s(%s)", future, GetFutureRunner(resultType), task))
+		bindings = append(bindings, fmt.Sprintf("%s.Run(func() { %s.Await() })", parallel.Group, future))
+		bindings = append(bindings, fmt.Sprintf("return %s", future))
+	} else {
+		bindings = append(bindings, fmt.Sprintf("return %s(%s)", GetFutureRunner(resultType), task))
+	}
+	analyzer.write(fmt.Sprintf("func() %s { %s }()", futureType.ToGoType(), strings.Join(bindings, "; ")), false)
+	analyzer.stack.Push(CreateValue(
+		futureType,
+		nil,
+	))
+}
+
+// Emits "close(ch)". Only channels the script may send to can be closed.
+func (analyzer *TAnalyzer) closeCall(node *TAst) {
+	if len(node.AstArr0) != 1 {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_ARGUMENT_COUNT,
+			fmt.Sprintf("expected 1 parameters, got %d", len(node.AstArr0)),
+			node.Position,
+		)
+	}
+	src, value := analyzer.captureExpression(node.AstArr0[0])
+	if !types.IsChan(value.DataType) {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_CONCURRENCY,
+			fmt.Sprintf("cannot close %s, only channels can be closed", value.DataType.ToString()),
+			node.AstArr0[0].Position,
+		)
+	}
+	if !value.DataType.CanSend() {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_CONCURRENCY,
+			fmt.Sprintf("cannot close receive-only channel %s", value.DataType.ToString()),
+			node.AstArr0[0].Position,
+		)
+	}
+	analyzer.write(fmt.Sprintf("%s(%s)", GLOBAL_CLOSE, src), false)
+	analyzer.stack.Push(CreateValue(
+		analyzer.state.TVoid,
+		nil,
+	))
+}
+
+// Analyzes the operand and the type of "x is T". Returns the operand as Go
+// source that can be asserted on, together with the tested type.
+func (analyzer *TAnalyzer) typeTest(node *TAst) (string, *types.TTyping) {
+	src, value := analyzer.captureExpression(node.Ast0)
+	dataType := analyzer.getType(node.Ast1)
+	if !types.IsAny(value.DataType) {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_INVALID_OPERATION,
+			fmt.Sprintf("cannot test the type of %s, only any values have a dynamic type", value.DataType.ToString()),
+			node.Position,
+		)
+	}
+	if types.IsVoid(dataType) {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_INVALID_TYPE,
+			fmt.Sprintf("invalid type in type test: %s", dataType.ToString()),
+			node.Ast1.Position,
+		)
+	}
+	if types.IsGoInterface(value.DataType) {
+		// Go rejects assertions to types that cannot implement the interface
+		src = fmt.Sprintf("any(%s)", src)
+	}
+	return src, dataType
+}
+
+// Analyzes a branch of a conditional statement. A narrowed symbol, if any,
+// shadows the original symbol within the branch. Reports whether the
+// narrowed symbol was used.
+func (analyzer *TAnalyzer) visitBranch(node *TAst, narrowed *TSymbol) bool {
+	analyzer.scope = CreateScope(analyzer.scope, ScopeConditional)
+	if narrowed != nil {
+		analyzer.scope.Env.AddSymbol(*narrowed)
+	}
+	analyzer.scope = CreateScope(analyzer.scope, ScopeSingle)
+	if node.Ttype == AstCodeBlock {
+		analyzer.statement(node)
+	} else {
+		analyzer.write("{", true)
+		analyzer.incTb()
+		analyzer.statement(node)
+		analyzer.decTb()
+		analyzer.srcNl()
+		analyzer.srcTb()
+		analyzer.write("}", false)
+	}
+	analyzer.scope = analyzer.scope.Parent // Leave the single scope
+	used := narrowed != nil && analyzer.scope.Env.GetSymbol(narrowed.Name).IsUsed
+	analyzer.scope = analyzer.scope.Parent // Leave the conditional scope
+	return used
+}
+
+// Returns a Go name that cannot clash with names written in source,
+// numbered past any identifier the file spells.
+func (analyzer *TAnalyzer) tempName(prefix string) string {
+	if analyzer.spelled == nil {
+		analyzer.spelled = spelledNames(analyzer.file.Data)
+	}
+	for {
+		analyzer.temp++
+		name := fmt.Sprintf("%s_%d", prefix, analyzer.temp)
+		if !analyzer.spelled[name] {
+			return name
+		}
+	}
+}
+
+// Collects every word of the source that may be an identifier, words in
+// strings and comments included.
+func spelledNames(data []rune) map[string]bool {
+	names := make(map[string]bool)
+	start := -1
+	for index := 0; index <= len(data); index++ {
+		if index < len(data) && (unicode.IsLetter(data[index]) || unicode.IsDigit(data[index]) || data[index] == '_') {
+			if start < 0 {
+				start = index
+			}
+			continue
+		}
+		if start >= 0 {
+			names[string(data[start:index])] = true
+			start = -1
+		}
+	}
+	return names
+}
+
+// Imports the Go package path under an alias made from its name, which
+// no local of the script shadows, and returns the alias.
+func (analyzer *TAnalyzer) packageAlias(path string, name string) string {
+	alias, ok := analyzer.packages[path]
+	if !ok {
+		alias = analyzer.tempName(name)
+		analyzer.packages[path] = alias
+	}
+	analyzer.addModule(fmt.Sprintf("%s \"%s\"", alias, path))
+	return alias
+}
+
+// Creates the symbol that stands for a narrowed "x" within a branch.
+func (analyzer *TAnalyzer) narrowSymbol(node *TAst, namespace string, dataType *types.TTyping) *TSymbol {
+	symbol := analyzer.scope.Env.GetSymbol(node.Str0)
+	return &TSymbol{
+		Name:         symbol.Name,
+		NameSpace:    namespace,
+		Module:       symbol.Module,
+		DataType:     dataType,
+		Position:     node.Position,
+		IsGlobal:     false,
+		IsConst:      true,
+		IsUsed:       false,
+		IsInitialize: true,
+		File:         symbol.File,
+		Origin:       &symbol,
+	}
+}
+
+// Emits a binary operation over already captured operands. When both operands
+// are constants the operation is folded, and integer results are widened to
+// the smallest type that can hold them.
+func (analyzer *TAnalyzer) binaryExpression(node *TAst, opt string, lhsSrc string, lhsValue TValue, rhsSrc string, rhsValue TValue, dataType *types.TTyping) {
+	if (opt == "/" || opt == "%") && IsZeroConstant(rhsValue.Data) {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_CONSTANT_EXPRESSION,
+			ErrDivisionByZero.Error(),
+			node.Position,
+		)
+	}
+	folded, err := FoldBinary(opt, lhsValue.Data, rhsValue.Data)
+	if err != nil {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_CONSTANT_EXPRESSION,
+			fmt.Sprintf("%s: %s %s %s", err.Error(), lhsSrc, opt, rhsSrc),
+			node.Position,
+		)
+	}
+	if folded == nil {
+		// Mixed widths are converted to the common type first. A shift
+		// keeps the type of its left operand and accepts any count type.
+		if opt != "<<" && opt != ">>" && types.IsAnyNumber(lhsValue.DataType) && types.IsAnyNumber(rhsValue.DataType) {
+			operandType := types.BinaryResult(lhsValue.DataType, rhsValue.DataType)
+			lhsSrc = analyzer.convert(lhsSrc, lhsValue, operandType)
+			rhsSrc = analyzer.convert(rhsSrc, rhsValue, operandType)
+		}
+		analyzer.write(lhsSrc+" "+opt+" "+rhsSrc, false)
+		analyzer.stack.Push(CreateValue(
+			dataType,
+			nil,
+		))
+		return
+	}
+	if !lhsValue.Untyped || !rhsValue.Untyped {
+		// A typed constant keeps its type, as a variable would
+		analyzer.checkConstantFits(dataType, CreateValue(dataType, folded), node.Position)
+		analyzer.write(typedConstant(folded, dataType), false)
+		analyzer.stack.Push(CreateValue(
+			dataType,
+			folded,
+		))
+		return
+	}
+	analyzer.write(ConstantToGo(folded), false)
+	analyzer.stack.Push(CreateUntypedValue(
+		analyzer.constantType(folded, dataType),
+		folded,
+	))
+}
+
+// Emits a unary operation, folding it when the operand is a constant.
+func (analyzer *TAnalyzer) unaryExpression(node *TAst, opt string) {
+	src, value := analyzer.captureExpression(node.Ast0)
+	folded, err := FoldUnary(opt, value.Data)
+	if err != nil {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_CONSTANT_EXPRESSION,
+			fmt.Sprintf("%s: %s%s", err.Error(), opt, src),
+			node.Position,
+		)
+	}
+	if folded == nil {
+		analyzer.write(opt+src, false)
+		analyzer.stack.Push(CreateValue(
+			value.DataType,
+			nil,
+		))
+		return
+	}
+	if value.Untyped {
+		analyzer.write(ConstantToGo(folded), false)
+		analyzer.stack.Push(CreateUntypedValue(
+			analyzer.constantType(folded, value.DataType),
+			folded,
+		))
+		return
+	}
+	analyzer.checkConstantFits(value.DataType, CreateValue(value.DataType, folded), node.Position)
+	analyzer.write(typedConstant(folded, value.DataType), false)
+	analyzer.stack.Push(CreateValue(
+		value.DataType,
+		folded,
+	))
+}
+
+// Spells a constant of a number type, a bare literal is untyped in Go.
+func typedConstant(value interface{}, dataType *types.TTyping) string {
+	if !types.IsAnyNumber(dataType) {
+		return ConstantToGo(value)
+	}
+	return fmt.Sprintf("%s(%s)", dataType.GoValueType(), ConstantToGo(value))
+}
+
+// Folded integers are typed like literals, by the smallest type that holds them.
+func (analyzer *TAnalyzer) constantType(value interface{}, dataType *types.TTyping) *types.TTyping {
+	if !types.IsAnyInt(dataType) {
+		return dataType
+	}
+	v, _ := ConstantInt(value)
+	switch SizeOfInt(v) {
+	case 8:
+		return analyzer.state.TI08
+	case 16:
+		return analyzer.state.TI16
+	case 32:
+		return analyzer.state.TI32
+	default:
+		return analyzer.state.TI64
+	}
+}
+
+// Reports integer constants that do not fit the type they are stored in.
+func (analyzer *TAnalyzer) checkConstantFits(dataType *types.TTyping, value TValue, position TPosition) {
+	if types.IsAnyInt(dataType) && !ConstantFits(value.Data, dataType) {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_CONSTANT_EXPRESSION,
+			fmt.Sprintf("constant %s overflows %s", ConstantToGo(value.Data), dataType.ToString()),
+			position,
+		)
+	}
+}
+
+// Struct instances opt into binary operators by defining
+// methods with reserved names (Add, Sub, Mul, Div, Mod, Eq, Less).
+// Returns false if the operands do not overload the operator.
+func (analyzer *TAnalyzer) operatorOverload(node *TAst, opt string, lhsSrc string, lhsValue TValue, rhsSrc string, rhsValue TValue) bool {
+	lhsType := lhsValue.DataType
+	rhsType := rhsValue.DataType
+	method := types.GetOperatorMethod(opt, lhsType, rhsType)
+	if method == nil {
+		return false
+	}
+	if !types.CanOverloadOperator(opt, lhsType, rhsType) {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_INVALID_OPERATION,
+			fmt.Sprintf("operator '%s' cannot be applied to %s and %s, method %s has type %s", opt, lhsType.ToString(), rhsType.ToString(), method.Name, method.DataType.ToString()),
+			node.Position,
+		)
+	}
+	analyzer.checkPanics(method.DataType, node)
+	switch opt {
+	case "!=":
+		analyzer.write(fmt.Sprintf("!(%s).%s(%s)", lhsSrc, method.Namespace, rhsSrc), false)
+	case ">":
+		analyzer.write(fmt.Sprintf("(%s).%s(%s)", rhsSrc, method.Namespace, lhsSrc), false)
+	case "<=":
+		analyzer.write(fmt.Sprintf("!(%s).%s(%s)", rhsSrc, method.Namespace, lhsSrc), false)
+	case ">=":
+		analyzer.write(fmt.Sprintf("!(%s).%s(%s)", lhsSrc, method.Namespace, rhsSrc), false)
+	default:
+		analyzer.write(fmt.Sprintf("(%s).%s(%s)", lhsSrc, method.Namespace, rhsSrc), false)
+	}
+	analyzer.stack.Push(CreateValue(
+		method.DataType.GetReturnType(),
+		nil,
+	))
+	return true
+}
+
+// Struct instances declared in ns are compared member by member
+// using the generated equality method.
+// Returns false if the operands are not ns struct instances.
+func (analyzer *TAnalyzer) structuralEquality(node *TAst, opt string, lhsSrc string, lhsValue TValue, rhsSrc string, rhsValue TValue) bool {
+	lhsType := lhsValue.DataType
+	rhsType := rhsValue.DataType
+	if !types.IsStructInstance(lhsType) || !types.IsStructInstance(rhsType) || !lhsType.HasConstructor() {
+		return false
+	}
+	if !types.IsTheSameInstance(lhsType, rhsType) {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_INVALID_OPERATION,
+			fmt.Sprintf("cannot compare %s and %s", lhsType.ToString(), rhsType.ToString()),
+			node.Position,
+		)
+	}
+	if member := types.GetNonComparableMember(lhsType); member != nil {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_INVALID_OPERATION,
+			fmt.Sprintf("cannot compare %s, member %s has non-comparable type %s", lhsType.ToString(), member.Name, member.DataType.ToString()),
+			node.Position,
+		)
+	}
+	if opt == "!=" {
+		analyzer.write("!", false)
+	}
+	analyzer.write(fmt.Sprintf("(%s).%s(%s)", lhsSrc, STRUCT_EQUALS, rhsSrc), false)
+	analyzer.stack.Push(CreateValue(
+		analyzer.state.TBit,
+		nil,
+	))
+	return true
+}
+
+// Emits a symbol that is read as a value.
+func (analyzer *TAnalyzer) symbolValue(node *TAst, symbol TSymbol) {
+	// The error of its declaration is already reported
+	if types.IsInvalid(symbol.DataType) {
+		Abort()
+	}
+	// A Go constant no type of the script holds is rejected where it is used
+	if constant, ok := symbol.Object.(*gotypes.Const); ok && symbol.Value == nil {
+		if _, _, err := GoConstant(analyzer.state, constant); err != nil {
+			RaiseLanguageCompileError(
+				analyzer.file.Path,
+				analyzer.file.Data,
+				CODE_CONSTANT_EXPRESSION,
+				fmt.Sprintf(INVALID_IMPORT_CONSTANT_OVERFLOW, symbol.Name),
+				node.Position,
+			)
+		}
+	}
+	// A slice or map variable imported from Go is wrapped where it is read
+	if needsNative(symbol.DataType) {
+		analyzer.write(analyzer.fromGo(symbol.NameSpace, symbol.DataType), false)
+		symbol.DataType = analyzer.nativeType(symbol.DataType)
+	} else {
+		analyzer.write(symbol.NameSpace, false)
+	}
+	if symbol.Members != nil {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_IMPORT_SYMBOL,
+			fmt.Sprintf(INVALID_IMPORT_NAMESPACE_VALUE, symbol.Name),
+			node.Position,
+		)
+	}
+	// Go has no values of generic functions, only of their instantiations
+	if types.IsGeneric(symbol.DataType) && analyzer.generic != node {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_GENERIC,
+			fmt.Sprintf(INVALID_GENERIC_VALUE, symbol.Name),
+			node.Position,
+		)
+	}
+	if symbol.Package != "" && symbol.Members == nil {
+		// A generic function, written through the import of its package
+		alias := symbol.NameSpace[:strings.LastIndex(symbol.NameSpace, ".")]
+		analyzer.addModule(fmt.Sprintf("%s \"%s\"", alias, symbol.Package))
+	}
+	// Struct cannot be used as a value.
+	if types.IsStruct(symbol.DataType) {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_INVALID_OPERATION,
+			fmt.Sprintf("struct %s cannot be used as a value", symbol.DataType.ToString()),
+			node.Position,
+		)
+	}
+	// A function literal may run after the block, even awaiting there escapes it
+	if symbol.IsTask && !analyzer.scope.DeclaresLocal(node.Str0) {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_CONCURRENCY,
+			fmt.Sprintf("future %s started in a parallel block cannot be captured by a function literal", node.Str0),
+			node.Position,
+		)
+	}
+	// Only awaiting keeps a future of a parallel block from escaping it
+	if symbol.IsTask && analyzer.await != node {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_CONCURRENCY,
+			fmt.Sprintf("future %s started in a parallel block can only be awaited, it cannot escape the block", node.Str0),
+			node.Position,
+		)
+	}
+	if constant, ok := symbol.Object.(*gotypes.Const); ok && symbol.Value != nil && IsUntypedConstant(constant) {
+		analyzer.stack.Push(CreateUntypedValue(
+			symbol.DataType,
+			symbol.Value,
+		))
+		return
+	}
+	analyzer.stack.Push(CreateValue(
+		symbol.DataType,
+		symbol.Value,
+	))
+}
+
+// Records the symbol that node names, for the language server.
+func (analyzer *TAnalyzer) reference(node *TAst, symbol TSymbol) {
+	if analyzer.state.Index == nil {
+		return
+	}
+	analyzer.state.Index.Add(TReference{
+		File:     analyzer.file.Path,
+		Position: node.Position,
+		Name:     node.Str0,
+		DataType: symbol.DataType,
+		Symbol:   &symbol,
+	})
+}
+
+// Records the member or method of owner that node names or declares.
+func (analyzer *TAnalyzer) memberReference(node *TAst, owner *types.TTyping, member *types.TPair, declaration bool) {
+	if analyzer.state.Index == nil || member == nil {
+		return
+	}
+	analyzer.state.Index.Add(TReference{
+		File:          analyzer.file.Path,
+		Position:      node.Position,
+		Name:          node.Str0,
+		DataType:      member.DataType,
+		Owner:         owner,
+		IsDeclaration: declaration,
+	})
+}
+
+// Declares a symbol in the current scope.
+func (analyzer *TAnalyzer) declare(symbol TSymbol) {
+	analyzer.scope.Env.AddSymbol(symbol)
+	if analyzer.state.Index == nil {
+		return
+	}
+	analyzer.state.Index.Add(TReference{
+		File:          analyzer.file.Path,
+		Position:      symbol.Position,
+		Name:          symbol.Name,
+		DataType:      symbol.DataType,
+		Symbol:        &symbol,
+		IsDeclaration: true,
+	})
+}
+
+// Returns the symbol of a namespace import that node names, if it does.
+func (analyzer *TAnalyzer) namespace(node *TAst) (TSymbol, bool) {
+	switch node.Ttype {
+	case AstIDN:
+		if !analyzer.scope.Env.HasGlobalSymbol(node.Str0) {
+			return TSymbol{}, false
+		}
+		symbol := analyzer.scope.Env.GetSymbol(node.Str0)
+		if symbol.Members == nil {
+			return TSymbol{}, false
+		}
+		analyzer.reference(node, symbol)
+		return symbol, true
+	case AstMember:
+		symbol, ok := analyzer.namespaceMember(node)
+		return symbol, ok && symbol.Members != nil
+	default:
+		return TSymbol{}, false
+	}
+}
+
+// Resolves "module.Name" to the symbol Name of a namespace import.
+// The Go package of the module is imported by the file that uses it.
+func (analyzer *TAnalyzer) namespaceMember(node *TAst) (TSymbol, bool) {
+	if node.Ttype != AstMember || node.Ast1.Ttype != AstIDN {
+		return TSymbol{}, false
+	}
+	namespace, ok := analyzer.namespace(node.Ast0)
+	if !ok {
+		return TSymbol{}, false
+	}
+	memberNode := node.Ast1
+	if !namespace.Members.HasLocalSymbol(memberNode.Str0) {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_IMPORT_SYMBOL,
+			fmt.Sprintf(INVALID_IMPORT_NAMESPACE_MEMBER, memberNode.Str0, namespace.Name),
+			memberNode.Position,
+		)
+	}
+	if namespace.Package != "" {
+		analyzer.addModule(fmt.Sprintf("%s \"%s\"", namespace.NameSpace, namespace.Package))
+	}
+	symbol := namespace.Members.GetSymbol(memberNode.Str0)
+	analyzer.reference(memberNode, symbol)
+	return symbol, true
+}
+
+func (analyzer *TAnalyzer) expression(node *TAst) {
+	// The hint only applies to the outermost expression
+	hint := analyzer.hint
+	analyzer.hint = nil
+	switch node.Ttype {
+	case AstIDN:
+		if !analyzer.scope.Env.HasGlobalSymbol(node.Str0) {
+			RaiseLanguageCompileError(
+				analyzer.file.Path,
+				analyzer.file.Data,
+				CODE_UNDEFINED_SYMBOL,
+				fmt.Sprintf("undefined symbol: %s", node.Str0),
+				node.Position,
+			)
+		}
+		symbol := analyzer.scope.Env.GetSymbol(node.Str0)
+		if symbol.IsGlobal && symbol.IsConst && symbol.Value == nil {
+			analyzer.globalConstant(node)
+			symbol = analyzer.scope.Env.GetSymbol(node.Str0)
+		}
+		analyzer.scope.Env.UpdateSymbolIsUsed(node.Str0, true)
+		analyzer.reference(node, symbol)
+		analyzer.symbolValue(node, symbol)
+	case AstInt:
+		i64, err := strconv.ParseInt(node.Str0, 10, 64)
+		if err != nil {
+			RaiseLanguageCompileError(
+				analyzer.file.Path,
+				analyzer.file.Data,
+				CODE_INVALID_NUMBER,
+				fmt.Sprintf("invalid integer value %s", node.Str0),
+				node.Position,
+			)
+		}
+		analyzer.write(ConstantToGo(i64), false)
+		switch SizeOfInt(i64) {
+		case 8:
+			analyzer.stack.Push(CreateUntypedValue(
+				analyzer.state.TI08,
+				int8(i64),
+			))
+		case 16:
+			analyzer.stack.Push(CreateUntypedValue(
+				analyzer.state.TI16,
+				int16(i64),
+			))
+		case 32:
+			analyzer.stack.Push(CreateUntypedValue(
+				analyzer.state.TI32,
+				int32(i64),
+			))
+		case 64:
+			analyzer.stack.Push(CreateUntypedValue(
+				analyzer.state.TI64,
+				i64,
+			))
+		default:
+			analyzer.stack.Push(CreateUntypedValue(
+				analyzer.state.TI08,
+				int8(i64),
+			))
+		}
+	case AstNum:
+		f64, err := strconv.ParseFloat(node.Str0, 64)
+		if err != nil {
+			RaiseLanguageCompileError(
+				analyzer.file.Path,
+				analyzer.file.Data,
+				CODE_INVALID_NUMBER,
+				fmt.Sprintf("invalid float value %s", node.Str0),
+				node.Position,
+			)
+		}
+		analyzer.write(ConstantToGo(f64), false)
+		analyzer.stack.Push(CreateUntypedValue(
+			analyzer.state.TNum,
+			f64,
+		))
+	case AstStr:
+		analyzer.write(fmt.Sprintf("\"%s\"", node.Str0), false)
+		analyzer.stack.Push(CreateUntypedValue(
+			analyzer.state.TStr,
+			node.Str0,
+		))
+	case AstBool:
+		analyzer.write(node.Str0, false)
+		analyzer.stack.Push(CreateUntypedValue(
+			analyzer.state.TBit,
+			node.Str0 == "true",
+		))
+	case AstNull:
+		analyzer.write("nil", false)
+		analyzer.stack.Push(CreateValue(
+			analyzer.state.TNil,
+			nil,
+		))
+	case AstTupleExpression:
+		tupleTypes := make([]*types.TTyping, 0)
+		for index, childNode := range node.AstArr0 {
+			analyzer.expression(childNode)
+			dataType := analyzer.stack.Pop().DataType
+			tupleTypes = append(tupleTypes, dataType)
+			if index < len(node.AstArr0)-1 {
+				analyzer.write(", ", false)
+			}
+		}
+		analyzer.stack.Push(CreateValue(
+			types.TTuple(tupleTypes),
+			nil,
+		))
+	case AstArray:
+		saveSrc := analyzer.src
+		saveStack := analyzer.stack
+		analyzer.src = ""
+		analyzer.stack = CreateEvaluationStack()
+		elementsNode := node.AstArr0
+		var elementType *types.TTyping = nil // Default type.
+		hinted := hint != nil && types.IsArray(hint)
+		if hinted {
+			// Adopt the element type of the destination
+			elementType = hint.GetInternal0()
+		}
+		for _, childNode := range elementsNode {
+			analyzer.expression(childNode)
+			topType := analyzer.stack.Pop().DataType
+			if elementType == nil {
 				elementType = topType
-			}
-			if index < len(elementsNode)-1 {
-				analyzer.write(", ", false)
+			} else if !types.CanStore(elementType, topType) {
+				if !hinted && types.CanStore(topType, elementType) {
+					// Widen to the element that needs the larger type
+					elementType = topType
+					continue
+				}
+				RaiseLanguageCompileError(
+					analyzer.file.Path,
+					analyzer.file.Data,
+					CODE_TYPE_MISMATCH,
+					fmt.Sprintf("cannot store %s in array of [%s]", topType.ToString(), elementType.ToString()),
+					childNode.Position,
+				)
 			}
 		}
 		// Restore
 		analyzer.src = saveSrc
 		analyzer.stack = saveStack
+		if elementType == nil {
+			RaiseLanguageCompileError(
+				analyzer.file.Path,
+				analyzer.file.Data,
+				CODE_INVALID_TYPE,
+				"cannot infer the element type of an empty array",
+				node.Position,
+			)
+		}
 		analyzer.write(GetArrayConstructor(elementType), false)
 		analyzer.write("(", false)
 		analyzer.write("[]", false)
 		analyzer.write(elementType.ToGoType(), false)
 		analyzer.write("{", false)
 		for index, childNode := range elementsNode {
-			analyzer.expression(childNode)
-			actualType := analyzer.stack.Pop().DataType
+			actualType := analyzer.storeExpression(childNode, elementType).DataType
 			if !types.CanStore(elementType, actualType) {
 				RaiseLanguageCompileError(
 					analyzer.file.Path,
 					analyzer.file.Data,
+					CODE_TYPE_MISMATCH,
 					fmt.Sprintf("cannot store %s in array of [%s]", actualType.ToString(), elementType.ToString()),
 					childNode.Position,
 				)
@@ -521,6 +1950,12 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 		valuesNode := node.AstArr1
 		var keyType *types.TTyping = nil
 		var valueType *types.TTyping = nil
+		hinted := hint != nil && types.IsMap(hint)
+		if hinted {
+			// Adopt the key and value types of the destination
+			keyType = hint.GetInternal0()
+			valueType = hint.GetInternal1()
+		}
 		// Save
 		saveSrc := analyzer.src
 		analyzer.src = ""
@@ -534,28 +1969,40 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 				RaiseLanguageCompileError(
 					analyzer.file.Path,
 					analyzer.file.Data,
+					CODE_TYPE_MISMATCH,
 					fmt.Sprintf("cannot store %s in map of [%s]", newKeyType.ToString(), keyType.ToString()),
 					keyNode.Position,
 				)
 			}
-			analyzer.write(":", false)
 			analyzer.expression(valueNode)
 			newValueType := analyzer.stack.Pop().DataType
 			if valueType == nil {
 				valueType = newValueType
 			} else if !types.CanStore(valueType, newValueType) {
+				if !hinted && types.CanStore(newValueType, valueType) {
+					// Widen to the value that needs the larger type
+					valueType = newValueType
+					continue
+				}
 				RaiseLanguageCompileError(
 					analyzer.file.Path,
 					analyzer.file.Data,
+					CODE_TYPE_MISMATCH,
 					fmt.Sprintf("cannot store %s in map of [%s]", newValueType.ToString(), valueType.ToString()),
 					valueNode.Position,
 				)
 			}
-			if index < len(keysNode)-1 {
-				analyzer.write(", ", false)
-			}
 		}
 		analyzer.src = saveSrc
+		if keyType == nil || valueType == nil {
+			RaiseLanguageCompileError(
+				analyzer.file.Path,
+				analyzer.file.Data,
+				CODE_INVALID_TYPE,
+				"cannot infer the key and value types of an empty map",
+				node.Position,
+			)
+		}
 		// Finalize
 		analyzer.write(GetMapConstructor(keyType, valueType), false)
 		analyzer.write("(", false)
@@ -567,31 +2014,9 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 		analyzer.write("{", false)
 		for index, keyNode := range keysNode {
 			valueNode := valuesNode[index]
-			analyzer.expression(keyNode)
-			newKeyType := analyzer.stack.Pop().DataType
-			if keyType == nil {
-				keyType = newKeyType
-			} else if !types.CanStore(keyType, newKeyType) {
-				RaiseLanguageCompileError(
-					analyzer.file.Path,
-					analyzer.file.Data,
-					fmt.Sprintf("cannot store %s in map of [%s]", newKeyType.ToString(), keyType.ToString()),
-					keyNode.Position,
-				)
-			}
+			analyzer.storeExpression(keyNode, keyType)
 			analyzer.write(":", false)
-			analyzer.expression(valueNode)
-			newValueType := analyzer.stack.Pop().DataType
-			if valueType == nil {
-				valueType = newValueType
-			} else if !types.CanStore(valueType, newValueType) {
-				RaiseLanguageCompileError(
-					analyzer.file.Path,
-					analyzer.file.Data,
-					fmt.Sprintf("cannot store %s in map of [%s]", newValueType.ToString(), valueType.ToString()),
-					valueNode.Position,
-				)
-			}
+			analyzer.storeExpression(valueNode, valueType)
 			if index < len(keysNode)-1 {
 				analyzer.write(", ", false)
 			}
@@ -625,19 +2050,11 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 				RaiseLanguageCompileError(
 					analyzer.file.Path,
 					analyzer.file.Data,
+					CODE_MISSING_NAME,
 					INVALID_FUNCTION_PARAM_NAME,
 					paramNameNode.Position,
 				)
 			}
-			// Parameter name must use camel case
-			if !IsCamelCase(paramNameNode.Str0) {
-				RaiseLanguageCompileError(
-					analyzer.file.Path,
-					analyzer.file.Data,
-					"invalid parameter name, parameter name must be in a form of camel case",
-					paramNameNode.Position,
-				)
-			}
 			parameterType := analyzer.getType(paramTypeNode)
 			parametersTypesPair = append(parametersTypesPair, types.CreatePair(paramNameNode.Str0, parameterType))
 			analyzer.write(fmt.Sprintf("%s %s", paramNameNode.Str0, parameterType.ToGoType()), false)
@@ -648,11 +2065,12 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 				RaiseLanguageCompileError(
 					analyzer.file.Path,
 					analyzer.file.Data,
+					CODE_DUPLICATE,
 					INVALID_FUNCTION_PARAM_NAME_DUPLICATE,
 					paramNameNode.Position,
 				)
 			}
-			analyzer.scope.Env.AddSymbol(TSymbol{
+			analyzer.declare(TSymbol{
 				Name:         paramNameNode.Str0,
 				NameSpace:    paramNameNode.Str0,
 				Module:       "",
@@ -666,6 +2084,7 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 		}
 		analyzer.write(")", false)
 		returnType := analyzer.getType(returnTypeNode)
+		functionScope.ReturnType = returnType
 		analyzer.srcSp()
 		analyzer.write(returnType.ToGoType(), false)
 		analyzer.srcSp()
@@ -689,14 +2108,17 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_TYPE_MISMATCH,
 				fmt.Sprintf("cannot return %s, return type must be %s", functionScope.Return.ToString(), returnType.ToString()),
 				node.Position,
 			)
 		}
 		if functionScope.Panics && !functionScope.HasPanic {
-			RaiseLanguageCompileError(
+			ReportLanguageWarning(
+				CHECK_PANICS,
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_PANICS,
 				"function declared to panic, but it does not actually panic",
 				node.Position,
 			)
@@ -710,12 +2132,7 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 		env := localScope.Env
 		for _, symbol := range env.Symbols {
 			if !symbol.IsUsed {
-				RaiseLanguageCompileError(
-					analyzer.file.Path,
-					analyzer.file.Data,
-					fmt.Sprintf("unused variable: %s", symbol.Name),
-					symbol.Position,
-				)
+				analyzer.unusedVariable(symbol)
 			}
 		}
 		dataType := types.TFunc(false, parametersTypesPair, returnType, panics)
@@ -731,6 +2148,7 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_OPERATION,
 				fmt.Sprintf("cannot increment %s", leftType.ToString()),
 				node.Position,
 			)
@@ -747,6 +2165,7 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_OPERATION,
 				fmt.Sprintf("cannot decrement %s", leftType.ToString()),
 				node.Position,
 			)
@@ -758,20 +2177,27 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 	case AstMember:
 		objectNode := node.Ast0
 		memberNode := node.Ast1
+		if symbol, ok := analyzer.namespaceMember(node); ok {
+			analyzer.symbolValue(memberNode, symbol)
+			break
+		}
 		if memberNode.Ttype != AstIDN {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_MISSING_NAME,
 				"member name must be an identifier",
 				memberNode.Position,
 			)
 		}
 		analyzer.expression(objectNode)
 		objectValue := analyzer.stack.Pop()
+		analyzer.objects[node] = objectValue.DataType
 		if !objectValue.DataType.HasMember(memberNode.Str0) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_UNKNOWN_MEMBER,
 				fmt.Sprintf("object %s has no member %s", objectValue.DataType.ToString(), memberNode.Str0),
 				objectNode.Position,
 			)
@@ -779,6 +2205,7 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 		analyzer.write(".", false)
 		analyzer.write(memberNode.Str0, false)
 		member := objectValue.DataType.GetMember(memberNode.Str0)
+		analyzer.memberReference(memberNode, objectValue.DataType, member, false)
 		analyzer.stack.Push(CreateValue(
 			member.DataType,
 			nil,
@@ -804,6 +2231,7 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_ASSIGN_CONSTANT,
 				fmt.Sprintf("cannot assign to %s", objectType.ToString()),
 				node.Position,
 			)
@@ -813,19 +2241,20 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 		} else {
 			analyzer.write("[", false)
 		}
-		analyzer.expression(indexNode)
-		indexType := analyzer.stack.Pop().DataType
+		indexType := analyzer.indexExpression(indexNode, objectType).DataType
 		if types.IsArray(objectType) && !types.IsAnyInt(indexType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_TYPE_MISMATCH,
 				fmt.Sprintf("array index must be an integer, got %s", indexType.ToString()),
 				node.Position,
 			)
-		} else if types.IsMap(objectType) && !types.IsTheSameInstance(indexType, objectType.GetInternal0()) {
+		} else if types.IsMap(objectType) && !types.CanStore(objectType.GetInternal0(), indexType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_TYPE_MISMATCH,
 				fmt.Sprintf("map index must be %s, got %s", objectType.GetInternal0().ToString(), indexType.ToString()),
 				node.Position,
 			)
@@ -833,6 +2262,7 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_TYPE_MISMATCH,
 				fmt.Sprintf("string index must be an integer, got %s", indexType.ToString()),
 				node.Position,
 			)
@@ -849,133 +2279,54 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 	case AstCall:
 		objectNode := node.Ast0
 		parametersNode := node.AstArr0
-		if objectNode.Ttype == AstMember {
-			member_obj := objectNode.Ast0
-			member_name := objectNode.Ast1
-			if member_name.Ttype != AstIDN {
-				RaiseLanguageCompileError(
-					analyzer.file.Path,
-					analyzer.file.Data,
-					"member name must be an identifier",
-					member_name.Position,
-				)
-			}
-			analyzer.expression(member_obj)
-			member_obj_value := analyzer.stack.Pop()
-			if !member_obj_value.DataType.HasMethod(member_name.Str0) {
-				RaiseLanguageCompileError(
-					analyzer.file.Path,
-					analyzer.file.Data,
-					fmt.Sprintf("object %s has no method %s", member_obj_value.DataType.ToString(), member_name.Str0),
-					member_name.Position,
-				)
-			}
-			analyzer.write(".", false)
-			method := member_obj_value.DataType.GetMethod(member_name.Str0)
-			analyzer.write(method.Namespace, false)
-			analyzer.stack.Push(CreateValue(
-				method.DataType,
-				nil,
-			))
-		} else {
-			analyzer.expression(objectNode)
-		}
-		objectValue := analyzer.stack.Pop()
-		if !types.IsFunc(objectValue.DataType) {
-			RaiseLanguageCompileError(
-				analyzer.file.Path,
-				analyzer.file.Data,
-				fmt.Sprintf("cannot call %s", objectValue.DataType.ToString()),
-				objectNode.Position,
-			)
+		if analyzer.isBuiltin(objectNode, GLOBAL_CLOSE) {
+			analyzer.closeCall(node)
+			break
 		}
-		if objectValue.DataType.Panics() && analyzer.scope.InFunction() {
-			current := analyzer.scope
-			for current.Type != ScopeFunction {
-				current = current.Parent
-			}
-			if current.Type == ScopeFunction && !current.Panics {
-				RaiseLanguageCompileError(
-					analyzer.file.Path,
-					analyzer.file.Data,
-					fmt.Sprintf("cannot call function '%s' that may panic from a function that does not declare 'panics'", objectValue.DataType.ToString()),
-					objectNode.Position,
-				)
-			}
-			current.HasPanic = true
-		} else if objectValue.DataType.Panics() && analyzer.scope.InGlobal() {
+		if analyzer.isBuiltin(objectNode, GLOBAL_TIMEOUT) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
-				"cannot call function that may panic from the global scope",
+				CODE_CONCURRENCY,
+				"timeout is only allowed as a case of a select",
 				objectNode.Position,
 			)
 		}
-		analyzer.write("(", false)
-		members := objectValue.DataType.GetMembers()
-		requiredParameters := members
-		if objectValue.DataType.Variadic() {
-			requiredParameters = requiredParameters[:len(requiredParameters)-1]
-		}
-		if !objectValue.DataType.Variadic() && len(requiredParameters) == len(parametersNode) {
-			for index, childNode := range parametersNode {
-				requiredType := requiredParameters[index].DataType
-				analyzer.expression(childNode)
-				actualType := analyzer.stack.Pop().DataType
-				if !types.CanStore(requiredType, actualType) {
-					RaiseLanguageCompileError(
-						analyzer.file.Path,
-						analyzer.file.Data,
-						fmt.Sprintf("expected %s, got %s", requiredType.ToString(), actualType.ToString()),
-						childNode.Position,
-					)
-				}
-				if index < len(parametersNode)-1 {
-					analyzer.write(", ", false)
-				}
-			}
-		} else if objectValue.DataType.Variadic() && len(requiredParameters) < len(parametersNode) {
-			theVariadictParmeter := members[len(members)-1]
-			for index, childNode := range parametersNode {
-				analyzer.expression(childNode)
-				if index < len(requiredParameters) {
-					requiredType := requiredParameters[index].DataType
-					actualType := analyzer.stack.Pop().DataType
-					if !types.CanStore(requiredType, actualType) {
-						RaiseLanguageCompileError(
-							analyzer.file.Path,
-							analyzer.file.Data,
-							fmt.Sprintf("expected %s, got %s", requiredType.ToString(), actualType.ToString()),
-							childNode.Position,
-						)
-					}
-				} else {
-					top := analyzer.stack.Pop()
-					if !types.CanStore(theVariadictParmeter.DataType, top.DataType) {
-						RaiseLanguageCompileError(
-							analyzer.file.Path,
-							analyzer.file.Data,
-							fmt.Sprintf("expected %s, got %s", theVariadictParmeter.DataType.ToString(), top.DataType.ToString()),
-							childNode.Position,
-						)
-					}
-				}
-				if index < len(parametersNode)-1 {
-					analyzer.write(", ", false)
-				}
-			}
+		saveSrc := analyzer.src
+		analyzer.src = ""
+		analyzer.generic = objectNode
+		if objectNode.Ttype == AstMember {
+			analyzer.generic = objectNode.Ast1
+		}
+		objectValue := analyzer.callee(objectNode)
+		analyzer.checkPanics(objectValue.DataType, objectNode)
+		copies := analyzer.copies
+		analyzer.copies = nil
+		var arguments []string
+		if types.IsGeneric(objectValue.DataType) {
+			objectValue.DataType, arguments = analyzer.genericCall(objectValue.DataType, objectNode, parametersNode)
 		} else {
-			RaiseLanguageCompileError(
-				analyzer.file.Path,
-				analyzer.file.Data,
-				fmt.Sprintf("expected %d parameters, got %d", len(requiredParameters), len(parametersNode)),
-				objectNode.Position,
-			)
+			arguments = analyzer.callArguments(objectValue.DataType, objectNode, parametersNode)
 		}
-
+		analyzer.write("(", false)
+		analyzer.write(strings.Join(arguments, ", "), false)
 		analyzer.write(")", false)
+		callSrc := analyzer.src
+		analyzer.src = saveSrc
+		// Slices and maps returned from Go become arrays and maps,
+		// unless the result of the call is not used at all
+		returnType := objectValue.DataType.GetReturnType()
+		if len(analyzer.copies) > 0 {
+			callSrc = analyzer.copyBackCall(callSrc, objectValue.DataType, analyzer.copies)
+		}
+		analyzer.copies = copies
+		if needsNative(returnType) && analyzer.discard != node {
+			callSrc = analyzer.fromGo(callSrc, returnType)
+			returnType = analyzer.nativeType(returnType)
+		}
+		analyzer.write(callSrc, false)
 		analyzer.stack.Push(CreateValue(
-			objectValue.DataType.GetReturnType(),
+			returnType,
 			nil,
 		))
 	case AstStruct:
@@ -986,6 +2337,7 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_MISSING_NAME,
 				"struct name must be an identifier",
 				objectNode.Position,
 			)
@@ -994,6 +2346,7 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_STRUCT,
 				"struct name and values must have the same length",
 				objectNode.Position,
 			)
@@ -1002,16 +2355,19 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_UNDEFINED_SYMBOL,
 				fmt.Sprintf("struct %s not found", objectNode.Str0),
 				objectNode.Position,
 			)
 		}
 		objectInfo := analyzer.scope.Env.GetSymbol(objectNode.Str0)
+		analyzer.reference(objectNode, objectInfo)
 		objDataType := objectInfo.DataType
 		if !types.IsStruct(objDataType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_UNDEFINED_SYMBOL,
 				fmt.Sprintf("struct %s is not a struct", objectNode.Str0),
 				objectNode.Position,
 			)
@@ -1024,6 +2380,7 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 				RaiseLanguageCompileError(
 					analyzer.file.Path,
 					analyzer.file.Data,
+					CODE_MISSING_NAME,
 					"struct name must be an identifier",
 					childNode.Position,
 				)
@@ -1032,6 +2389,7 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 				RaiseLanguageCompileError(
 					analyzer.file.Path,
 					analyzer.file.Data,
+					CODE_UNKNOWN_MEMBER,
 					fmt.Sprintf("struct %s has no member %s", objectNode.Str0, childNode.Str0),
 					childNode.Position,
 				)
@@ -1040,12 +2398,12 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 			analyzer.write(":", false)
 			analyzer.srcSp()
 			memberType := objDataType.GetMember(childNode.Str0).DataType
-			analyzer.expression(valuesNode[index])
-			actualType := analyzer.stack.Pop().DataType
+			actualType := analyzer.storeExpression(valuesNode[index], memberType).DataType
 			if !types.CanStore(memberType, actualType) {
 				RaiseLanguageCompileError(
 					analyzer.file.Path,
 					analyzer.file.Data,
+					CODE_TYPE_MISMATCH,
 					fmt.Sprintf("cannot store %s in %s", actualType.ToString(), memberType.ToString()),
 					childNode.Position,
 				)
@@ -1111,6 +2469,7 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_TYPE_MISMATCH,
 				fmt.Sprintf("else branch must return %s, got %s", expectedType.ToString(), elseType.ToString()),
 				node.Position,
 			)
@@ -1120,43 +2479,142 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 			nil,
 		))
 	case AstPlus:
-		analyzer.write("+", false)
-		analyzer.expression(node.Ast0)
-		value := analyzer.stack.Pop()
+		analyzer.unaryExpression(node, "+")
+	case AstMinus:
+		analyzer.unaryExpression(node, "-")
+	case AstNot:
+		analyzer.unaryExpression(node, "!")
+		if value := analyzer.stack.Peek(); !types.IsBool(value.DataType) {
+			RaiseLanguageCompileError(
+				analyzer.file.Path,
+				analyzer.file.Data,
+				CODE_TYPE_MISMATCH,
+				"expected bool, got "+value.DataType.ToString(),
+				node.Position,
+			)
+		}
+	case AstBitNot:
+		analyzer.unaryExpression(node, "^")
+	case AstChan:
+		elementType := analyzer.getType(node.Ast0)
+		if types.IsVoid(elementType) {
+			RaiseLanguageCompileError(
+				analyzer.file.Path,
+				analyzer.file.Data,
+				CODE_INVALID_TYPE,
+				INVALID_CHAN_ELEMENT_TYPE,
+				node.Ast0.Position,
+			)
+		}
+		dataType := types.TChan(elementType, types.ChanBoth)
+		if node.Ast1 == nil {
+			analyzer.write(fmt.Sprintf("make(%s)", dataType.ToGoType()), false)
+		} else {
+			src, value := analyzer.captureExpression(node.Ast1)
+			if !types.IsAnyInt(value.DataType) {
+				RaiseLanguageCompileError(
+					analyzer.file.Path,
+					analyzer.file.Data,
+					CODE_CONCURRENCY,
+					fmt.Sprintf("channel buffer size must be an integer, got %s", value.DataType.ToString()),
+					node.Ast1.Position,
+				)
+			}
+			if size, ok := ConstantInt(value.Data); ok && size < 0 {
+				RaiseLanguageCompileError(
+					analyzer.file.Path,
+					analyzer.file.Data,
+					CODE_CONSTANT_EXPRESSION,
+					fmt.Sprintf("negative channel buffer size %d", size),
+					node.Ast1.Position,
+				)
+			}
+			analyzer.write(fmt.Sprintf("make(%s, %s)", dataType.ToGoType(), src), false)
+		}
 		analyzer.stack.Push(CreateValue(
-			value.DataType,
+			dataType,
 			nil,
 		))
-	case AstMinus:
-		analyzer.write("-", false)
-		analyzer.expression(node.Ast0)
-		value := analyzer.stack.Pop()
+	case AstRun:
+		analyzer.runExpression(node)
+	case AstAwait:
+		analyzer.await = node.Ast0
+		src, value := analyzer.captureExpression(node.Ast0)
+		if !types.IsFuture(value.DataType) {
+			RaiseLanguageCompileError(
+				analyzer.file.Path,
+				analyzer.file.Data,
+				CODE_CONCURRENCY,
+				fmt.Sprintf("cannot await %s, only futures can be awaited", value.DataType.ToString()),
+				node.Position,
+			)
+		}
+		analyzer.checkPanics(value.DataType, node)
+		analyzer.write(fmt.Sprintf("%s.Await()", src), false)
 		analyzer.stack.Push(CreateValue(
-			value.DataType,
+			value.DataType.GetInternal0(),
 			nil,
 		))
-	case AstNot:
-		analyzer.write("!", false)
-		analyzer.expression(node.Ast0)
-		value := analyzer.stack.Pop()
-		if !types.IsBool(value.DataType) {
+	case AstReceive:
+		src, value := analyzer.captureExpression(node.Ast0)
+		if !types.IsChan(value.DataType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
-				"expected bool, got "+value.DataType.ToString(),
+				CODE_CONCURRENCY,
+				fmt.Sprintf("cannot receive from %s", value.DataType.ToString()),
+				node.Position,
+			)
+		}
+		if !value.DataType.CanReceive() {
+			RaiseLanguageCompileError(
+				analyzer.file.Path,
+				analyzer.file.Data,
+				CODE_CONCURRENCY,
+				fmt.Sprintf("cannot receive from send-only channel %s", value.DataType.ToString()),
 				node.Position,
 			)
 		}
+		analyzer.write(fmt.Sprintf("<-%s", src), false)
 		analyzer.stack.Push(CreateValue(
-			analyzer.state.TBit,
+			value.DataType.GetInternal0(),
 			nil,
 		))
-	case AstBitNot:
-		analyzer.write("^", false)
-		analyzer.expression(node.Ast0)
-		value := analyzer.stack.Pop()
+	case AstSend:
+		chanSrc, chanValue := analyzer.captureExpression(node.Ast0)
+		if !types.IsChan(chanValue.DataType) {
+			RaiseLanguageCompileError(
+				analyzer.file.Path,
+				analyzer.file.Data,
+				CODE_CONCURRENCY,
+				fmt.Sprintf("cannot send to %s", chanValue.DataType.ToString()),
+				node.Position,
+			)
+		}
+		if !chanValue.DataType.CanSend() {
+			RaiseLanguageCompileError(
+				analyzer.file.Path,
+				analyzer.file.Data,
+				CODE_CONCURRENCY,
+				fmt.Sprintf("cannot send to receive-only channel %s", chanValue.DataType.ToString()),
+				node.Position,
+			)
+		}
+		elementType := chanValue.DataType.GetInternal0()
+		src, value := analyzer.captureStore(node.Ast1, elementType)
+		analyzer.checkConstantFits(elementType, value, node.Ast1.Position)
+		if !types.CanStore(elementType, value.DataType) {
+			RaiseLanguageCompileError(
+				analyzer.file.Path,
+				analyzer.file.Data,
+				CODE_CONCURRENCY,
+				fmt.Sprintf("cannot send %s to %s", value.DataType.ToString(), chanValue.DataType.ToString()),
+				node.Ast1.Position,
+			)
+		}
+		analyzer.write(fmt.Sprintf("%s <- %s", chanSrc, src), false)
 		analyzer.stack.Push(CreateValue(
-			value.DataType,
+			analyzer.state.TVoid,
 			nil,
 		))
 	case AstAllocation:
@@ -1169,6 +2627,7 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_UNDEFINED_SYMBOL,
 				"allocation expression must be a struct",
 				objectNode.Position,
 			)
@@ -1178,6 +2637,7 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_UNDEFINED_SYMBOL,
 				"struct has no constructor",
 				objectNode.Position,
 			)
@@ -1191,376 +2651,401 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 			types.ToPointer(value.DataType),
 			nil,
 		))
+	case AstCast:
+		src, value := analyzer.captureExpression(node.Ast0)
+		dataType := analyzer.getType(node.Ast1)
+		switch types.GetCastKind(dataType, value.DataType) {
+		case types.CastIdentity:
+			analyzer.write(analyzer.convert(src, value, dataType), false)
+			analyzer.stack.Push(CreateValue(
+				dataType,
+				value.Data,
+			))
+		case types.CastNumeric:
+			if constant := CastConstant(value.Data, dataType); constant != nil {
+				analyzer.write(ConstantToGo(constant), false)
+				analyzer.stack.Push(CreateValue(
+					dataType,
+					constant,
+				))
+				break
+			}
+			analyzer.write(fmt.Sprintf("%s(%s)", dataType.GoValueType(), src), false)
+			analyzer.stack.Push(CreateValue(
+				dataType,
+				nil,
+			))
+		case types.CastStrToBytes:
+			if !analyzer.state.ArrayTypeExists(dataType.GetInternal0()) {
+				analyzer.state.AddArrayType(dataType.GetInternal0())
+			}
+			analyzer.write(GetStrToBytesCode(src), false)
+			analyzer.stack.Push(CreateValue(
+				dataType,
+				nil,
+			))
+		case types.CastBytesToStr:
+			if types.IsGoBytes(value.DataType) {
+				analyzer.write(fmt.Sprintf("string(%s)", src), false)
+			} else {
+				analyzer.write(GetBytesToStrCode(src), false)
+			}
+			analyzer.stack.Push(CreateValue(
+				dataType,
+				nil,
+			))
+		case types.CastNominal:
+			analyzer.write(fmt.Sprintf("%s(%s)", dataType.ToGoType(), src), false)
+			analyzer.stack.Push(CreateValue(
+				dataType,
+				nil,
+			))
+		case types.CastAssertion:
+			if types.IsGoInterface(value.DataType) {
+				src = fmt.Sprintf("any(%s)", src)
+			}
+			analyzer.write(fmt.Sprintf("%s.(%s)", src, dataType.ToGoType()), false)
+			analyzer.stack.Push(CreateValue(
+				dataType,
+				nil,
+			))
+		default:
+			RaiseLanguageCompileError(
+				analyzer.file.Path,
+				analyzer.file.Data,
+				CODE_INVALID_OPERATION,
+				fmt.Sprintf("cannot cast %s to %s", value.DataType.ToString(), dataType.ToString()),
+				node.Position,
+			)
+		}
+	case AstIs:
+		src, dataType := analyzer.typeTest(node)
+		analyzer.write(fmt.Sprintf("func() bool { _, ok := %s.(%s); return ok }()", src, dataType.ToGoType()), false)
+		analyzer.stack.Push(CreateValue(
+			analyzer.state.TBit,
+			nil,
+		))
 	case AstMul:
 		lhsNode := node.Ast0
 		rhsNode := node.Ast1
-		analyzer.expression(lhsNode)
-		lhsValue := analyzer.stack.Pop()
-		analyzer.write(" * ", false)
-		analyzer.expression(rhsNode)
-		rhsValue := analyzer.stack.Pop()
+		lhsSrc, lhsValue := analyzer.captureExpression(lhsNode)
+		rhsSrc, rhsValue := analyzer.captureExpression(rhsNode)
+		if analyzer.operatorOverload(node, "*", lhsSrc, lhsValue, rhsSrc, rhsValue) {
+			break
+		}
 		if !types.CanDoArithmetic("*", lhsValue.DataType, rhsValue.DataType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_OPERATION,
 				fmt.Sprintf("cannot multiply %s and %s", lhsValue.DataType.ToString(), rhsValue.DataType.ToString()),
 				node.Position,
 			)
 		}
-		analyzer.stack.Push(CreateValue(
-			types.WhichBigger(lhsValue.DataType, rhsValue.DataType),
-			nil,
-		))
+		analyzer.binaryExpression(node, "*", lhsSrc, lhsValue, rhsSrc, rhsValue, types.BinaryResult(lhsValue.DataType, rhsValue.DataType))
 	case AstDiv:
 		lhsNode := node.Ast0
 		rhsNode := node.Ast1
-		analyzer.expression(lhsNode)
-		lhsValue := analyzer.stack.Pop()
-		analyzer.write(" / ", false)
-		analyzer.expression(rhsNode)
-		rhsValue := analyzer.stack.Pop()
+		lhsSrc, lhsValue := analyzer.captureExpression(lhsNode)
+		rhsSrc, rhsValue := analyzer.captureExpression(rhsNode)
+		if analyzer.operatorOverload(node, "/", lhsSrc, lhsValue, rhsSrc, rhsValue) {
+			break
+		}
 		if !types.CanDoArithmetic("/", lhsValue.DataType, rhsValue.DataType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_OPERATION,
 				fmt.Sprintf("cannot divide %s by %s", lhsValue.DataType.ToString(), rhsValue.DataType.ToString()),
 				node.Position,
 			)
 		}
-		analyzer.stack.Push(CreateValue(
-			types.WhichBigger(lhsValue.DataType, rhsValue.DataType),
-			nil,
-		))
+		analyzer.binaryExpression(node, "/", lhsSrc, lhsValue, rhsSrc, rhsValue, types.BinaryResult(lhsValue.DataType, rhsValue.DataType))
 	case AstMod:
 		lhsNode := node.Ast0
 		rhsNode := node.Ast1
-		analyzer.expression(lhsNode)
-		lhsValue := analyzer.stack.Pop()
-		analyzer.write(" % ", false)
-		analyzer.expression(rhsNode)
-		rhsValue := analyzer.stack.Pop()
+		lhsSrc, lhsValue := analyzer.captureExpression(lhsNode)
+		rhsSrc, rhsValue := analyzer.captureExpression(rhsNode)
+		if analyzer.operatorOverload(node, "%", lhsSrc, lhsValue, rhsSrc, rhsValue) {
+			break
+		}
 		if !types.CanDoArithmetic("%", lhsValue.DataType, rhsValue.DataType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_OPERATION,
 				fmt.Sprintf("cannot modulo %s by %s", lhsValue.DataType.ToString(), rhsValue.DataType.ToString()),
 				node.Position,
 			)
 		}
-		analyzer.stack.Push(CreateValue(
-			types.WhichBigger(lhsValue.DataType, rhsValue.DataType),
-			nil,
-		))
+		analyzer.binaryExpression(node, "%", lhsSrc, lhsValue, rhsSrc, rhsValue, types.BinaryResult(lhsValue.DataType, rhsValue.DataType))
 	case AstAdd:
 		lhsNode := node.Ast0
 		rhsNode := node.Ast1
-		analyzer.expression(lhsNode)
-		lhsValue := analyzer.stack.Pop()
-		analyzer.write(" + ", false)
-		analyzer.expression(rhsNode)
-		rhsValue := analyzer.stack.Pop()
+		lhsSrc, lhsValue := analyzer.captureExpression(lhsNode)
+		rhsSrc, rhsValue := analyzer.captureExpression(rhsNode)
+		if analyzer.operatorOverload(node, "+", lhsSrc, lhsValue, rhsSrc, rhsValue) {
+			break
+		}
 		if !types.CanDoArithmetic("+", lhsValue.DataType, rhsValue.DataType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_OPERATION,
 				fmt.Sprintf("cannot add %s and %s", lhsValue.DataType.ToString(), rhsValue.DataType.ToString()),
 				node.Position,
 			)
 		}
-		analyzer.stack.Push(CreateValue(
-			types.WhichBigger(lhsValue.DataType, rhsValue.DataType),
-			nil,
-		))
+		analyzer.binaryExpression(node, "+", lhsSrc, lhsValue, rhsSrc, rhsValue, types.BinaryResult(lhsValue.DataType, rhsValue.DataType))
 	case AstSub:
 		lhsNode := node.Ast0
 		rhsNode := node.Ast1
-		analyzer.expression(lhsNode)
-		lhsValue := analyzer.stack.Pop()
-		analyzer.write(" - ", false)
-		analyzer.expression(rhsNode)
-		rhsValue := analyzer.stack.Pop()
+		lhsSrc, lhsValue := analyzer.captureExpression(lhsNode)
+		rhsSrc, rhsValue := analyzer.captureExpression(rhsNode)
+		if analyzer.operatorOverload(node, "-", lhsSrc, lhsValue, rhsSrc, rhsValue) {
+			break
+		}
 		if !types.CanDoArithmetic("-", lhsValue.DataType, rhsValue.DataType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_OPERATION,
 				fmt.Sprintf("cannot subtract %s and %s", lhsValue.DataType.ToString(), rhsValue.DataType.ToString()),
 				node.Position,
 			)
 		}
-		analyzer.stack.Push(CreateValue(
-			types.WhichBigger(lhsValue.DataType, rhsValue.DataType),
-			nil,
-		))
+		analyzer.binaryExpression(node, "-", lhsSrc, lhsValue, rhsSrc, rhsValue, types.BinaryResult(lhsValue.DataType, rhsValue.DataType))
 	case AstShl:
 		lhsNode := node.Ast0
 		rhsNode := node.Ast1
-		analyzer.expression(lhsNode)
-		lhsValue := analyzer.stack.Pop()
-		analyzer.write(" << ", false)
-		analyzer.expression(rhsNode)
-		rhsValue := analyzer.stack.Pop()
+		lhsSrc, lhsValue := analyzer.captureExpression(lhsNode)
+		rhsSrc, rhsValue := analyzer.captureExpression(rhsNode)
 		if !types.CanDoArithmetic("<<", lhsValue.DataType, rhsValue.DataType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_OPERATION,
 				fmt.Sprintf("cannot shift left %s by %s", lhsValue.DataType.ToString(), rhsValue.DataType.ToString()),
 				node.Position,
 			)
 		}
-		analyzer.stack.Push(CreateValue(
-			types.WhichBigger(lhsValue.DataType, rhsValue.DataType),
-			nil,
-		))
+		analyzer.binaryExpression(node, "<<", lhsSrc, lhsValue, rhsSrc, rhsValue, lhsValue.DataType)
 	case AstShr:
 		lhsNode := node.Ast0
 		rhsNode := node.Ast1
-		analyzer.expression(lhsNode)
-		lhsValue := analyzer.stack.Pop()
-		analyzer.write(" >> ", false)
-		analyzer.expression(rhsNode)
-		rhsValue := analyzer.stack.Pop()
+		lhsSrc, lhsValue := analyzer.captureExpression(lhsNode)
+		rhsSrc, rhsValue := analyzer.captureExpression(rhsNode)
 		if !types.CanDoArithmetic(">>", lhsValue.DataType, rhsValue.DataType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_OPERATION,
 				fmt.Sprintf("cannot shift right %s by %s", lhsValue.DataType.ToString(), rhsValue.DataType.ToString()),
 				node.Position,
 			)
 		}
-		analyzer.stack.Push(CreateValue(
-			types.WhichBigger(lhsValue.DataType, rhsValue.DataType),
-			nil,
-		))
+		analyzer.binaryExpression(node, ">>", lhsSrc, lhsValue, rhsSrc, rhsValue, lhsValue.DataType)
 	case AstLt:
 		lhsNode := node.Ast0
 		rhsNode := node.Ast1
-		analyzer.expression(lhsNode)
-		lhsValue := analyzer.stack.Pop()
-		analyzer.write(" < ", false)
-		analyzer.expression(rhsNode)
-		rhsValue := analyzer.stack.Pop()
+		lhsSrc, lhsValue := analyzer.captureExpression(lhsNode)
+		rhsSrc, rhsValue := analyzer.captureExpression(rhsNode)
+		if analyzer.operatorOverload(node, "<", lhsSrc, lhsValue, rhsSrc, rhsValue) {
+			break
+		}
 		if !types.CanDoArithmetic("<", lhsValue.DataType, rhsValue.DataType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_OPERATION,
 				fmt.Sprintf("cannot compare %s and %s", lhsValue.DataType.ToString(), rhsValue.DataType.ToString()),
 				node.Position,
 			)
 		}
-		analyzer.stack.Push(CreateValue(
-			analyzer.state.TBit,
-			nil,
-		))
+		analyzer.binaryExpression(node, "<", lhsSrc, lhsValue, rhsSrc, rhsValue, analyzer.state.TBit)
 	case AstLe:
 		lhsNode := node.Ast0
 		rhsNode := node.Ast1
-		analyzer.expression(lhsNode)
-		lhsValue := analyzer.stack.Pop()
-		analyzer.write(" <= ", false)
-		analyzer.expression(rhsNode)
-		rhsValue := analyzer.stack.Pop()
+		lhsSrc, lhsValue := analyzer.captureExpression(lhsNode)
+		rhsSrc, rhsValue := analyzer.captureExpression(rhsNode)
+		if analyzer.operatorOverload(node, "<=", lhsSrc, lhsValue, rhsSrc, rhsValue) {
+			break
+		}
 		if !types.CanDoArithmetic("<=", lhsValue.DataType, rhsValue.DataType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_OPERATION,
 				fmt.Sprintf("cannot compare %s and %s", lhsValue.DataType.ToString(), rhsValue.DataType.ToString()),
 				node.Position,
 			)
 		}
-		analyzer.stack.Push(CreateValue(
-			analyzer.state.TBit,
-			nil,
-		))
+		analyzer.binaryExpression(node, "<=", lhsSrc, lhsValue, rhsSrc, rhsValue, analyzer.state.TBit)
 	case AstGt:
 		lhsNode := node.Ast0
 		rhsNode := node.Ast1
-		analyzer.expression(lhsNode)
-		lhsValue := analyzer.stack.Pop()
-		analyzer.write(" > ", false)
-		analyzer.expression(rhsNode)
-		rhsValue := analyzer.stack.Pop()
+		lhsSrc, lhsValue := analyzer.captureExpression(lhsNode)
+		rhsSrc, rhsValue := analyzer.captureExpression(rhsNode)
+		if analyzer.operatorOverload(node, ">", lhsSrc, lhsValue, rhsSrc, rhsValue) {
+			break
+		}
 		if !types.CanDoArithmetic(">", lhsValue.DataType, rhsValue.DataType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_OPERATION,
 				fmt.Sprintf("cannot compare %s and %s", lhsValue.DataType.ToString(), rhsValue.DataType.ToString()),
 				node.Position,
 			)
 		}
-		analyzer.stack.Push(CreateValue(
-			analyzer.state.TBit,
-			nil,
-		))
+		analyzer.binaryExpression(node, ">", lhsSrc, lhsValue, rhsSrc, rhsValue, analyzer.state.TBit)
 	case AstGe:
 		lhsNode := node.Ast0
 		rhsNode := node.Ast1
-		analyzer.expression(lhsNode)
-		lhsValue := analyzer.stack.Pop()
-		analyzer.write(" >= ", false)
-		analyzer.expression(rhsNode)
-		rhsValue := analyzer.stack.Pop()
+		lhsSrc, lhsValue := analyzer.captureExpression(lhsNode)
+		rhsSrc, rhsValue := analyzer.captureExpression(rhsNode)
+		if analyzer.operatorOverload(node, ">=", lhsSrc, lhsValue, rhsSrc, rhsValue) {
+			break
+		}
 		if !types.CanDoArithmetic(">=", lhsValue.DataType, rhsValue.DataType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_OPERATION,
 				fmt.Sprintf("cannot compare %s and %s", lhsValue.DataType.ToString(), rhsValue.DataType.ToString()),
 				node.Position,
 			)
 		}
-		analyzer.stack.Push(CreateValue(
-			analyzer.state.TBit,
-			nil,
-		))
+		analyzer.binaryExpression(node, ">=", lhsSrc, lhsValue, rhsSrc, rhsValue, analyzer.state.TBit)
 	case AstEq:
 		lhsNode := node.Ast0
 		rhsNode := node.Ast1
-		analyzer.expression(lhsNode)
-		lhsValue := analyzer.stack.Pop()
-		analyzer.write(" == ", false)
-		analyzer.expression(rhsNode)
-		rhsValue := analyzer.stack.Pop()
+		lhsSrc, lhsValue := analyzer.captureExpression(lhsNode)
+		rhsSrc, rhsValue := analyzer.captureExpression(rhsNode)
+		if analyzer.operatorOverload(node, "==", lhsSrc, lhsValue, rhsSrc, rhsValue) {
+			break
+		}
+		if analyzer.structuralEquality(node, "==", lhsSrc, lhsValue, rhsSrc, rhsValue) {
+			break
+		}
 		if !types.CanDoArithmetic("==", lhsValue.DataType, rhsValue.DataType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_OPERATION,
 				fmt.Sprintf("cannot compare %s and %s", lhsValue.DataType.ToString(), rhsValue.DataType.ToString()),
 				node.Position,
 			)
 		}
-		analyzer.stack.Push(CreateValue(
-			analyzer.state.TBit,
-			nil,
-		))
+		analyzer.binaryExpression(node, "==", lhsSrc, lhsValue, rhsSrc, rhsValue, analyzer.state.TBit)
 	case AstNe:
 		lhsNode := node.Ast0
 		rhsNode := node.Ast1
-		analyzer.expression(lhsNode)
-		lhsValue := analyzer.stack.Pop()
-		analyzer.write(" != ", false)
-		analyzer.expression(rhsNode)
-		rhsValue := analyzer.stack.Pop()
+		lhsSrc, lhsValue := analyzer.captureExpression(lhsNode)
+		rhsSrc, rhsValue := analyzer.captureExpression(rhsNode)
+		if analyzer.operatorOverload(node, "!=", lhsSrc, lhsValue, rhsSrc, rhsValue) {
+			break
+		}
+		if analyzer.structuralEquality(node, "!=", lhsSrc, lhsValue, rhsSrc, rhsValue) {
+			break
+		}
 		if !types.CanDoArithmetic("!=", lhsValue.DataType, rhsValue.DataType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_OPERATION,
 				fmt.Sprintf("cannot compare %s and %s", lhsValue.DataType.ToString(), rhsValue.DataType.ToString()),
 				node.Position,
 			)
 		}
-		analyzer.stack.Push(CreateValue(
-			analyzer.state.TBit,
-			nil,
-		))
+		analyzer.binaryExpression(node, "!=", lhsSrc, lhsValue, rhsSrc, rhsValue, analyzer.state.TBit)
 	case AstAnd:
 		lhsNode := node.Ast0
 		rhsNode := node.Ast1
-		analyzer.expression(lhsNode)
-		lhsValue := analyzer.stack.Pop()
-		analyzer.write(" && ", false)
-		analyzer.expression(rhsNode)
-		rhsValue := analyzer.stack.Pop()
-		if !types.CanDoArithmetic("&&", lhsValue.DataType, rhsValue.DataType) {
+		lhsSrc, lhsValue := analyzer.captureExpression(lhsNode)
+		rhsSrc, rhsValue := analyzer.captureExpression(rhsNode)
+		if !types.CanDoArithmetic("&", lhsValue.DataType, rhsValue.DataType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_OPERATION,
 				fmt.Sprintf("cannot compare %s and %s", lhsValue.DataType.ToString(), rhsValue.DataType.ToString()),
 				node.Position,
 			)
 		}
-		analyzer.stack.Push(CreateValue(
-			types.WhichBigger(lhsValue.DataType, rhsValue.DataType),
-			nil,
-		))
+		analyzer.binaryExpression(node, "&", lhsSrc, lhsValue, rhsSrc, rhsValue, types.BinaryResult(lhsValue.DataType, rhsValue.DataType))
 	case AstOr:
 		lhsNode := node.Ast0
 		rhsNode := node.Ast1
-		analyzer.expression(lhsNode)
-		lhsValue := analyzer.stack.Pop()
-		analyzer.write(" | ", false)
-		analyzer.expression(rhsNode)
-		rhsValue := analyzer.stack.Pop()
+		lhsSrc, lhsValue := analyzer.captureExpression(lhsNode)
+		rhsSrc, rhsValue := analyzer.captureExpression(rhsNode)
 		if !types.CanDoArithmetic("|", lhsValue.DataType, rhsValue.DataType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_OPERATION,
 				fmt.Sprintf("cannot compare %s and %s", lhsValue.DataType.ToString(), rhsValue.DataType.ToString()),
 				node.Position,
 			)
 		}
-		analyzer.stack.Push(CreateValue(
-			analyzer.state.TI64,
-			nil,
-		))
+		analyzer.binaryExpression(node, "|", lhsSrc, lhsValue, rhsSrc, rhsValue, types.BinaryResult(lhsValue.DataType, rhsValue.DataType))
 	case AstXor:
 		lhsNode := node.Ast0
 		rhsNode := node.Ast1
-		analyzer.expression(lhsNode)
-		lhsValue := analyzer.stack.Pop()
-		analyzer.write(" ^ ", false)
-		analyzer.expression(rhsNode)
-		rhsValue := analyzer.stack.Pop()
+		lhsSrc, lhsValue := analyzer.captureExpression(lhsNode)
+		rhsSrc, rhsValue := analyzer.captureExpression(rhsNode)
 		if !types.CanDoArithmetic("^", lhsValue.DataType, rhsValue.DataType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_OPERATION,
 				fmt.Sprintf("cannot compare %s and %s", lhsValue.DataType.ToString(), rhsValue.DataType.ToString()),
 				node.Position,
 			)
 		}
-		analyzer.stack.Push(CreateValue(
-			types.WhichBigger(lhsValue.DataType, rhsValue.DataType),
-			nil,
-		))
+		analyzer.binaryExpression(node, "^", lhsSrc, lhsValue, rhsSrc, rhsValue, types.BinaryResult(lhsValue.DataType, rhsValue.DataType))
 	case AstLogAnd:
 		lhsNode := node.Ast0
 		rhsNode := node.Ast1
-		analyzer.expression(lhsNode)
-		lhsValue := analyzer.stack.Pop()
-		analyzer.write(" && ", false)
-		analyzer.expression(rhsNode)
-		rhsValue := analyzer.stack.Pop()
+		lhsSrc, lhsValue := analyzer.captureExpression(lhsNode)
+		rhsSrc, rhsValue := analyzer.captureExpression(rhsNode)
 		if !types.CanDoArithmetic("&&", lhsValue.DataType, rhsValue.DataType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_OPERATION,
 				fmt.Sprintf("cannot compare %s and %s", lhsValue.DataType.ToString(), rhsValue.DataType.ToString()),
 				node.Position,
 			)
 		}
-		analyzer.stack.Push(CreateValue(
-			analyzer.state.TBit,
-			nil,
-		))
+		analyzer.binaryExpression(node, "&&", lhsSrc, lhsValue, rhsSrc, rhsValue, analyzer.state.TBit)
 	case AstLogOr:
 		lhsNode := node.Ast0
 		rhsNode := node.Ast1
-		analyzer.expression(lhsNode)
-		lhsValue := analyzer.stack.Pop()
-		analyzer.write(" || ", false)
-		analyzer.expression(rhsNode)
-		rhsValue := analyzer.stack.Pop()
+		lhsSrc, lhsValue := analyzer.captureExpression(lhsNode)
+		rhsSrc, rhsValue := analyzer.captureExpression(rhsNode)
 		if !types.CanDoArithmetic("||", lhsValue.DataType, rhsValue.DataType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_OPERATION,
 				fmt.Sprintf("cannot compare %s and %s", lhsValue.DataType.ToString(), rhsValue.DataType.ToString()),
 				node.Position,
 			)
 		}
-		analyzer.stack.Push(CreateValue(
-			analyzer.state.TBit,
-			nil,
-		))
+		analyzer.binaryExpression(node, "||", lhsSrc, lhsValue, rhsSrc, rhsValue, analyzer.state.TBit)
 	case AstAssign:
 		analyzer.expressionAssignLeft(node.Ast0)
 		leftType := analyzer.stack.Pop().DataType
 		analyzer.write(" = ", false)
-		analyzer.expression(node.Ast1)
-		rightType := analyzer.stack.Pop().DataType
+		rightValue := analyzer.storeExpression(node.Ast1, leftType)
+		rightType := rightValue.DataType
+		analyzer.checkConstantFits(leftType, rightValue, node.Position)
 		if !types.CanStore(leftType, rightType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_TYPE_MISMATCH,
 				fmt.Sprintf("cannot assign %s to %s", rightType.ToString(), leftType.ToString()),
 				node.Position,
 			)
@@ -1573,12 +3058,12 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 		analyzer.expressionAssignLeft(node.Ast0)
 		leftType := analyzer.stack.Pop().DataType
 		analyzer.write(" *= ", false)
-		analyzer.expression(node.Ast1)
-		rightType := analyzer.stack.Pop().DataType
+		rightType := analyzer.storeExpression(node.Ast1, leftType).DataType
 		if !types.CanDoArithmetic("*", leftType, rightType) || !types.CanStore(leftType, rightType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_TYPE_MISMATCH,
 				fmt.Sprintf("cannot assign %s to %s", rightType.ToString(), leftType.ToString()),
 				node.Position,
 			)
@@ -1591,12 +3076,22 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 		analyzer.expressionAssignLeft(node.Ast0)
 		leftType := analyzer.stack.Pop().DataType
 		analyzer.write(" /= ", false)
-		analyzer.expression(node.Ast1)
-		rightType := analyzer.stack.Pop().DataType
+		rightValue := analyzer.storeExpression(node.Ast1, leftType)
+		rightType := rightValue.DataType
+		if IsZeroConstant(rightValue.Data) {
+			RaiseLanguageCompileError(
+				analyzer.file.Path,
+				analyzer.file.Data,
+				CODE_CONSTANT_EXPRESSION,
+				ErrDivisionByZero.Error(),
+				node.Position,
+			)
+		}
 		if !types.CanDoArithmetic("/", leftType, rightType) || !types.CanStore(leftType, rightType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_TYPE_MISMATCH,
 				fmt.Sprintf("cannot assign %s to %s", rightType.ToString(), leftType.ToString()),
 				node.Position,
 			)
@@ -1609,12 +3104,22 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 		analyzer.expressionAssignLeft(node.Ast0)
 		leftType := analyzer.stack.Pop().DataType
 		analyzer.write(" %= ", false)
-		analyzer.expression(node.Ast1)
-		rightType := analyzer.stack.Pop().DataType
+		rightValue := analyzer.storeExpression(node.Ast1, leftType)
+		rightType := rightValue.DataType
+		if IsZeroConstant(rightValue.Data) {
+			RaiseLanguageCompileError(
+				analyzer.file.Path,
+				analyzer.file.Data,
+				CODE_CONSTANT_EXPRESSION,
+				ErrDivisionByZero.Error(),
+				node.Position,
+			)
+		}
 		if !types.CanDoArithmetic("%", leftType, rightType) || !types.CanStore(leftType, rightType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_TYPE_MISMATCH,
 				fmt.Sprintf("cannot assign %s to %s", rightType.ToString(), leftType.ToString()),
 				node.Position,
 			)
@@ -1627,12 +3132,12 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 		analyzer.expressionAssignLeft(node.Ast0)
 		leftType := analyzer.stack.Pop().DataType
 		analyzer.write(" += ", false)
-		analyzer.expression(node.Ast1)
-		rightType := analyzer.stack.Pop().DataType
+		rightType := analyzer.storeExpression(node.Ast1, leftType).DataType
 		if !types.CanDoArithmetic("+", leftType, rightType) || !types.CanStore(leftType, rightType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_TYPE_MISMATCH,
 				fmt.Sprintf("cannot assign %s to %s", rightType.ToString(), leftType.ToString()),
 				node.Position,
 			)
@@ -1645,12 +3150,12 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 		analyzer.expressionAssignLeft(node.Ast0)
 		leftType := analyzer.stack.Pop().DataType
 		analyzer.write(" -= ", false)
-		analyzer.expression(node.Ast1)
-		rightType := analyzer.stack.Pop().DataType
+		rightType := analyzer.storeExpression(node.Ast1, leftType).DataType
 		if !types.CanDoArithmetic("-", leftType, rightType) || !types.CanStore(leftType, rightType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_TYPE_MISMATCH,
 				fmt.Sprintf("cannot assign %s to %s", rightType.ToString(), leftType.ToString()),
 				node.Position,
 			)
@@ -1669,6 +3174,7 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_TYPE_MISMATCH,
 				fmt.Sprintf("cannot assign %s to %s", rightType.ToString(), leftType.ToString()),
 				node.Position,
 			)
@@ -1687,6 +3193,7 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_TYPE_MISMATCH,
 				fmt.Sprintf("cannot assign %s to %s", rightType.ToString(), leftType.ToString()),
 				node.Position,
 			)
@@ -1699,12 +3206,12 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 		analyzer.expressionAssignLeft(node.Ast0)
 		leftType := analyzer.stack.Pop().DataType
 		analyzer.write(" &= ", false)
-		analyzer.expression(node.Ast1)
-		rightType := analyzer.stack.Pop().DataType
+		rightType := analyzer.storeExpression(node.Ast1, leftType).DataType
 		if !types.CanDoArithmetic("&", leftType, rightType) || !types.CanStore(leftType, rightType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_TYPE_MISMATCH,
 				fmt.Sprintf("cannot assign %s to %s", rightType.ToString(), leftType.ToString()),
 				node.Position,
 			)
@@ -1717,12 +3224,12 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 		analyzer.expressionAssignLeft(node.Ast0)
 		leftType := analyzer.stack.Pop().DataType
 		analyzer.write(" |= ", false)
-		analyzer.expression(node.Ast1)
-		rightType := analyzer.stack.Pop().DataType
+		rightType := analyzer.storeExpression(node.Ast1, leftType).DataType
 		if !types.CanDoArithmetic("|", leftType, rightType) || !types.CanStore(leftType, rightType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_TYPE_MISMATCH,
 				fmt.Sprintf("cannot assign %s to %s", rightType.ToString(), leftType.ToString()),
 				node.Position,
 			)
@@ -1735,12 +3242,12 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 		analyzer.expressionAssignLeft(node.Ast0)
 		leftType := analyzer.stack.Pop().DataType
 		analyzer.write(" ^= ", false)
-		analyzer.expression(node.Ast1)
-		rightType := analyzer.stack.Pop().DataType
+		rightType := analyzer.storeExpression(node.Ast1, leftType).DataType
 		if !types.CanDoArithmetic("^", leftType, rightType) || !types.CanStore(leftType, rightType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_TYPE_MISMATCH,
 				fmt.Sprintf("cannot assign %s to %s", rightType.ToString(), leftType.ToString()),
 				node.Position,
 			)
@@ -1755,6 +3262,7 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_NOT_ALLOWED_HERE,
 				"short variable declaration is not allowed in global scope",
 				node.Position,
 			)
@@ -1765,6 +3273,7 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_MISSING_NAME,
 				"invalid variable name, variable name must be in a form of identifier",
 				node.Ast0.Position,
 			)
@@ -1777,6 +3286,7 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 				RaiseLanguageCompileError(
 					analyzer.file.Path,
 					analyzer.file.Data,
+					CODE_DUPLICATE,
 					"duplicate variable namesss",
 					node.Ast0.Position,
 				)
@@ -1789,6 +3299,7 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 					RaiseLanguageCompileError(
 						analyzer.file.Path,
 						analyzer.file.Data,
+						CODE_MISSING_NAME,
 						"invalid variable name, variable name must be in a form of identifier",
 						variableNode.Position,
 					)
@@ -1802,6 +3313,9 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 
 		// Write the assignment operator and evaluate the right-hand expression
 		analyzer.write(" := ", false)
+		if node.Ast0.Ttype == AstIDN && node.Ast1.Ttype == AstRun {
+			analyzer.task = node.Ast1
+		}
 		analyzer.expression(node.Ast1)
 
 		// Register the variable in the symbol table
@@ -1811,13 +3325,14 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 				RaiseLanguageCompileError(
 					analyzer.file.Path,
 					analyzer.file.Data,
+					CODE_DUPLICATE,
 					fmt.Sprintf("duplicate variable name: %s", node.Ast0.Str0),
 					node.Ast0.Position,
 				)
 			}
 
 			// Add the symbol to the environment
-			analyzer.scope.Env.AddSymbol(TSymbol{
+			analyzer.declare(TSymbol{
 				Name:         node.Ast0.Str0,
 				NameSpace:    node.Ast0.Str0,
 				DataType:     analyzer.stack.Pop().DataType,
@@ -1826,6 +3341,7 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 				IsConst:      false,
 				IsUsed:       false,
 				IsInitialize: true,
+				IsTask:       node.Ast1.Ttype == AstRun && analyzer.scope.Parallel() != nil,
 			})
 		} else {
 			// Handle tuple unpacking
@@ -1836,6 +3352,7 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 				RaiseLanguageCompileError(
 					analyzer.file.Path,
 					analyzer.file.Data,
+					CODE_INVALID_OPERATION,
 					"cannot unpack non-tuple type",
 					node.Ast1.Position,
 				)
@@ -1849,6 +3366,7 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 				RaiseLanguageCompileError(
 					analyzer.file.Path,
 					analyzer.file.Data,
+					CODE_INVALID_OPERATION,
 					"unpacked tuple must have the same number of elements as the number of variables",
 					node.Ast1.Position,
 				)
@@ -1863,13 +3381,14 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 					RaiseLanguageCompileError(
 						analyzer.file.Path,
 						analyzer.file.Data,
+						CODE_DUPLICATE,
 						fmt.Sprintf("duplicate variable name: %s", variableNode.Str0),
 						variableNode.Position,
 					)
 				}
 
 				// Add the symbol to the environment
-				analyzer.scope.Env.AddSymbol(TSymbol{
+				analyzer.declare(TSymbol{
 					Name:         variableNode.Str0,
 					NameSpace:    variableNode.Str0,
 					DataType:     variableType,
@@ -1889,13 +3408,139 @@ func (analyzer *TAnalyzer) expression(node *TAst) {
 		RaiseLanguageCompileError(
 			analyzer.file.Path,
 			analyzer.file.Data,
+			CODE_INTERNAL,
 			fmt.Sprintf("not implemented expression: %d", node.Ttype),
 			node.Position,
 		)
 	}
 }
 
+// Analyzes a statement. A statement with an error is abandoned, what it
+// declares is declared with an invalid type and the analysis goes on
+// with the next statement.
 func (analyzer *TAnalyzer) statement(node *TAst) {
+	scope := analyzer.scope
+	tab := analyzer.tab
+	size := analyzer.stack.Size()
+	defer func() {
+		if !RecoverAbort(recover()) {
+			return
+		}
+		for analyzer.stack.Size() > size {
+			analyzer.stack.Pop()
+		}
+		analyzer.scope = scope
+		analyzer.tab = tab
+		analyzer.hint = nil
+		analyzer.task = nil
+		analyzer.await = nil
+		analyzer.discard = nil
+		analyzer.generic = nil
+		analyzer.copies = nil
+		analyzer.markUsed(node)
+		analyzer.invalidate(node)
+	}()
+	env := analyzer.scope.Env
+	declared := len(env.Symbols)
+	analyzer.visitStatement(node)
+	analyzer.markLocals(env, declared)
+}
+
+// Writes a marker after the locals that a statement declared. Go rejects
+// an unused local, one that only warns is discarded at its marker, see
+// unusedVariable, the other markers are removed.
+func (analyzer *TAnalyzer) markLocals(env *TEnv, declared int) {
+	for _, symbol := range env.Symbols[declared:] {
+		if symbol.IsGlobal || symbol.IsConst || symbol.IsUsed || symbol.Name == "_" {
+			continue
+		}
+		marker := discardMarker(symbol)
+		analyzer.discards[marker] = ""
+		analyzer.write(marker, false)
+	}
+}
+
+func discardMarker(symbol TSymbol) string {
+	return fmt.Sprintf("/*discard:%s:%d:%d*/", symbol.NameSpace, symbol.Position.SLine, symbol.Position.SColm)
+}
+
+// Reports a local that is never read. When the compile goes on, the
+// local is discarded after its declaration. The blank identifier is
+// never read, Go does not require it to be.
+func (analyzer *TAnalyzer) unusedVariable(symbol TSymbol) {
+	if symbol.Name == "_" {
+		return
+	}
+	compiles := ReportLanguageWarning(
+		CHECK_UNUSED,
+		analyzer.file.Path,
+		analyzer.file.Data,
+		CODE_UNUSED_VARIABLE,
+		fmt.Sprintf("unused variable: %s", symbol.Name),
+		symbol.Position,
+	)
+	marker := discardMarker(symbol)
+	if _, ok := analyzer.discards[marker]; ok && compiles {
+		analyzer.discards[marker] = "; _ = " + symbol.NameSpace
+	}
+}
+
+// Declares the locals of a statement that failed, which it has not
+// declared yet, with an invalid type. A global constant that failed is
+// declared already, its type becomes invalid.
+func (analyzer *TAnalyzer) invalidate(node *TAst) {
+	if node.Ttype == AstConst && analyzer.scope.InGlobal() {
+		for _, nameNode := range node.AstArr0 {
+			analyzer.scope.Env.UpdateSymbolType(nameNode.Str0, types.TInvalid())
+		}
+		return
+	}
+	names := make([]*TAst, 0)
+	switch {
+	case node.Ttype == AstLocal || (node.Ttype == AstConst && analyzer.scope.InLocal()):
+		names = node.AstArr0
+	case node.Ttype == AstExpressionStmnt && node.Ast0.Ttype == AstBindAssign:
+		if node.Ast0.Ast0.Ttype == AstTupleExpression {
+			names = node.Ast0.Ast0.AstArr0
+		} else {
+			names = append(names, node.Ast0.Ast0)
+		}
+	}
+	for _, nameNode := range names {
+		if nameNode.Ttype != AstIDN || analyzer.scope.Env.HasLocalSymbol(nameNode.Str0) {
+			continue
+		}
+		analyzer.scope.Env.AddSymbol(TSymbol{
+			Name:         nameNode.Str0,
+			NameSpace:    nameNode.Str0,
+			DataType:     types.TInvalid(),
+			Position:     nameNode.Position,
+			IsUsed:       true,
+			IsInitialize: true,
+		})
+	}
+}
+
+// Marks the symbols a statement that failed names as used, the reads
+// after its error are not analyzed and are not unused.
+func (analyzer *TAnalyzer) markUsed(node *TAst) {
+	if node == nil {
+		return
+	}
+	if node.Ttype == AstIDN {
+		analyzer.scope.Env.UpdateSymbolIsUsed(node.Str0, true)
+	}
+	for _, child := range []*TAst{node.Ast0, node.Ast1, node.Ast2, node.Ast3} {
+		analyzer.markUsed(child)
+	}
+	for _, children := range [][]*TAst{node.AstArr0, node.AstArr1, node.AstArr2} {
+		for _, child := range children {
+			analyzer.markUsed(child)
+		}
+	}
+}
+
+func (analyzer *TAnalyzer) visitStatement(node *TAst) {
 	switch node.Ttype {
 	case AstStruct:
 		analyzer.visitStruct(node)
@@ -1915,6 +3560,12 @@ func (analyzer *TAnalyzer) statement(node *TAst) {
 		analyzer.visitFor(node)
 	case AstIf:
 		analyzer.visitIf(node)
+	case AstSwitch:
+		analyzer.visitSwitch(node)
+	case AstSelect:
+		analyzer.visitSelect(node)
+	case AstParallel:
+		analyzer.visitParallel(node)
 	case AstCodeBlock:
 		analyzer.visitCodeBlock(node)
 	case AstRunStmnt:
@@ -1931,12 +3582,14 @@ func (analyzer *TAnalyzer) statement(node *TAst) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_NOT_ALLOWED_HERE,
 				"expression is not allowed in here",
 				node.Position,
 			)
 		}
 		analyzer.writePosition(node.Position)
 		analyzer.srcTb()
+		analyzer.discard = node.Ast0
 		analyzer.expression(node.Ast0)
 		value := analyzer.stack.Pop()
 		// Prevent standalone identifiers and constants that have no effect
@@ -1946,6 +3599,7 @@ func (analyzer *TAnalyzer) statement(node *TAst) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_UNUSED_EXPRESSION,
 				"unused expression: this expression has no effect and its result is discarded",
 				node.Position,
 			)
@@ -1954,6 +3608,7 @@ func (analyzer *TAnalyzer) statement(node *TAst) {
 		RaiseLanguageCompileError(
 			analyzer.file.Path,
 			analyzer.file.Data,
+			CODE_INTERNAL,
 			"not implemented statement",
 			node.Position,
 		)
@@ -1965,10 +3620,12 @@ func (analyzer *TAnalyzer) visitStruct(node *TAst) {
 		RaiseLanguageCompileError(
 			analyzer.file.Path,
 			analyzer.file.Data,
+			CODE_NOT_ALLOWED_HERE,
 			"struct is not allowed here",
 			node.Position,
 		)
 	}
+	analyzer.writeDoc(node)
 	analyzer.writePosition(node.Position)
 	analyzer.scope = CreateScope(analyzer.scope, ScopeStruct)
 	nameNode := node.Ast0
@@ -1978,23 +3635,16 @@ func (analyzer *TAnalyzer) visitStruct(node *TAst) {
 		RaiseLanguageCompileError(
 			analyzer.file.Path,
 			analyzer.file.Data,
+			CODE_MISSING_NAME,
 			"invalid struct name, struct name must be in a form of identifier",
 			nameNode.Position,
 		)
 	}
-	// Struct name must use pascal case
-	if !IsPascalCase(nameNode.Str0) {
-		RaiseLanguageCompileError(
-			analyzer.file.Path,
-			analyzer.file.Data,
-			"invalid struct name, struct name must be in a form of pascal case",
-			nameNode.Position,
-		)
-	}
 	if len(namesNode) <= 0 {
 		RaiseLanguageCompileError(
 			analyzer.file.Path,
 			analyzer.file.Data,
+			CODE_INVALID_STRUCT,
 			"invalid struct, struct must have at least one attribute",
 			node.Position,
 		)
@@ -2003,12 +3653,14 @@ func (analyzer *TAnalyzer) visitStruct(node *TAst) {
 		RaiseLanguageCompileError(
 			analyzer.file.Path,
 			analyzer.file.Data,
+			CODE_UNDEFINED_SYMBOL,
 			"undefined struct",
 			nameNode.Position,
 		)
 	}
 	thisStruct := analyzer.file.Env.GetSymbol(nameNode.Str0)
-	structName := JoinVariableName(GetFileNameWithoutExtension(analyzer.file.Path), nameNode.Str0)
+	analyzer.reference(nameNode, thisStruct)
+	structName := analyzer.state.DeclarationName(analyzer.file.Path, node)
 	analyzer.write(fmt.Sprintf("type %s struct", structName), false)
 	analyzer.srcSp()
 	analyzer.write("{", true)
@@ -2019,20 +3671,13 @@ func (analyzer *TAnalyzer) visitStruct(node *TAst) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_MISSING_NAME,
 				"invalid attribute name, attribute name must be in a form of identifier",
 				attrNode.Position,
 			)
 		}
-		// Attribute name must use pascal case
-		if !IsPascalCase(attrNode.Str0) {
-			RaiseLanguageCompileError(
-				analyzer.file.Path,
-				analyzer.file.Data,
-				"invalid attribute name, struct attribute must be in a form of pascal case",
-				attrNode.Position,
-			)
-		}
 		dataType := analyzer.getType(typeNode)
+		analyzer.memberReference(attrNode, thisStruct.DataType, thisStruct.DataType.GetMember(attrNode.Str0), true)
 		// Check for cycle member
 		items := make([]*types.TTyping, 0, 8) // Pre-allocate capacity for better performance
 		items = append(items, dataType)
@@ -2043,6 +3688,7 @@ func (analyzer *TAnalyzer) visitStruct(node *TAst) {
 				RaiseLanguageCompileError(
 					analyzer.file.Path,
 					analyzer.file.Data,
+					CODE_INVALID_STRUCT,
 					fmt.Sprintf("struct '%s' contains a cycle: member '%s' has the same type as its containing struct", thisStruct.Name, attrNode.Str0),
 					attrNode.Position,
 				)
@@ -2090,44 +3736,195 @@ func (analyzer *TAnalyzer) visitStruct(node *TAst) {
 	analyzer.decTb()
 	analyzer.write("}", true)
 
-	// Create a String method for the struct
+	// Create a String method for the struct, a String method
+	// declared in the script is the one Go uses, as for fmt.Stringer
+	analyzer.srcNl()
+	analyzer.state.AddBridge(structName, "String")
+	if stringMethod := analyzer.stringMethod(nameNode.Str0); stringMethod != nil {
+		// A String method that a library exports already is the Go one
+		methodName := analyzer.state.DeclarationName(analyzer.file.Path, stringMethod)
+		receiver := structName
+		if stringMethod.AstArr1[0].Ttype == AstTypePointer {
+			receiver = "*" + structName
+		}
+		if methodName != "String" {
+			analyzer.write(fmt.Sprintf("func (instance %s) String() string", receiver), false)
+			analyzer.srcSp()
+			analyzer.write("{", true)
+			analyzer.incTb()
+			analyzer.srcTb()
+			analyzer.write(fmt.Sprintf("return instance.%s()", methodName), true)
+			analyzer.decTb()
+			analyzer.write("}", true)
+		}
+	} else {
+		analyzer.addModule("\"fmt\"")
+		analyzer.write(fmt.Sprintf("func (instance %s) String() string", structName), false)
+		analyzer.srcSp()
+		analyzer.write("{", true)
+		analyzer.incTb()
+		analyzer.srcTb()
+		analyzer.write("str := \"\"", true)
+		analyzer.srcTb()
+		analyzer.write(fmt.Sprintf("str += \"%s \"", nameNode.Str0), true)
+		analyzer.srcTb()
+		analyzer.write("str += \"{ \"", true)
+		analyzer.incTb()
+		for index, attrNode := range namesNode {
+			analyzer.srcTb()
+			analyzer.write(fmt.Sprintf("str += fmt.Sprintf(\"%s: %%v\", instance.%s)", attrNode.Str0, attrNode.Str0), true)
+			if index < len(namesNode)-1 {
+				analyzer.write("str += \", \"", true)
+			}
+		}
+		analyzer.decTb()
+		analyzer.srcTb()
+		analyzer.write("str += \" }\"", true)
+		analyzer.srcTb()
+		analyzer.write("return str", true)
+		analyzer.decTb()
+		analyzer.write("}", true)
+	}
+
+	// Structs whose members are all comparable get a structural equality
+	// and hash, and can be used as map keys. They are not methods of the
+	// script, their Go names cannot be spelled by it, see STRUCT_EQUALS.
+	instanceType := types.ToInstance(thisStruct.DataType)
+	if !types.IsComparable(instanceType) {
+		return
+	}
+	if !analyzer.declaresGoMethod(nameNode.Str0, STRUCT_EQUALS) {
+		analyzer.structEquals(structName, instanceType)
+	}
+	if !analyzer.declaresGoMethod(nameNode.Str0, STRUCT_HASH) {
+		analyzer.structHash(structName, instanceType)
+	}
+}
+
+// Creates the method that compares two instances member by member.
+func (analyzer *TAnalyzer) structEquals(structName string, instanceType *types.TTyping) {
 	analyzer.srcNl()
-	analyzer.write(fmt.Sprintf("func (instance %s) String() string", structName), false)
+	analyzer.write(fmt.Sprintf("func (instance %s) %s(other %s) bool", structName, STRUCT_EQUALS, structName), false)
 	analyzer.srcSp()
 	analyzer.write("{", true)
 	analyzer.incTb()
 	analyzer.srcTb()
-	analyzer.write("str := \"\"", true)
-	analyzer.srcTb()
-	analyzer.write(fmt.Sprintf("str += \"%s \"", nameNode.Str0), true)
-	analyzer.srcTb()
-	analyzer.write("str += \"{ \"", true)
-	analyzer.incTb()
-	for index, attrNode := range namesNode {
-		analyzer.srcTb()
-		analyzer.write(fmt.Sprintf("str += fmt.Sprintf(\"%s: %%v\", instance.%s)", attrNode.Str0, attrNode.Str0), true)
-		if index < len(namesNode)-1 {
-			analyzer.write("str += \", \"", true)
+	analyzer.write("return", false)
+	for index, member := range instanceType.GetMembers() {
+		analyzer.srcSp()
+		if types.IsStructInstance(member.DataType) && member.DataType.HasConstructor() {
+			analyzer.write(fmt.Sprintf("instance.%s.%s(other.%s)", member.Name, STRUCT_EQUALS, member.Name), false)
+		} else {
+			analyzer.write(fmt.Sprintf("instance.%s == other.%s", member.Name, member.Name), false)
+		}
+		if index < len(instanceType.GetMembers())-1 {
+			analyzer.write(" &&", false)
 		}
 	}
+	analyzer.srcNl()
 	analyzer.decTb()
+	analyzer.write("}", true)
+}
+
+// Creates the method that hashes an instance by the values of its
+// members, instances that are equal have the same hash. Pointers,
+// channels and errors are equal by identity and are left out.
+func (analyzer *TAnalyzer) structHash(structName string, instanceType *types.TTyping) {
+	analyzer.addModule("\"hash/fnv\"")
+	analyzer.srcNl()
+	analyzer.write(fmt.Sprintf("func (instance %s) %s() uint64", structName, STRUCT_HASH), false)
+	analyzer.srcSp()
+	analyzer.write("{", true)
+	analyzer.incTb()
 	analyzer.srcTb()
-	analyzer.write("str += \" }\"", true)
+	analyzer.write("hash := fnv.New64a()", true)
+	for _, member := range instanceType.GetMembers() {
+		memberType := member.DataType
+		field := "instance." + member.Name
+		bits := ""
+		switch {
+		case types.IsPointer(memberType):
+			continue
+		case types.IsAnyInt(memberType):
+			bits = fmt.Sprintf("uint64(%s)", field)
+		case types.IsNum(memberType):
+			// -0 equals 0, it hashes as 0
+			analyzer.addModule("\"math\"")
+			bits = fmt.Sprintf("math.Float64bits(%s+0)", field)
+		case types.IsBool(memberType):
+			analyzer.addModule("\"strconv\"")
+			analyzer.srcTb()
+			analyzer.write(fmt.Sprintf("hash.Write(strconv.AppendBool(nil, %s))", field), true)
+			continue
+		case types.IsStr(memberType):
+			// The length keeps "a", "bc" apart from "ab", "c"
+			bits = fmt.Sprintf("uint64(len(%s))", field)
+		case types.IsStructInstance(memberType) && memberType.HasConstructor():
+			bits = fmt.Sprintf("%s.%s()", field, STRUCT_HASH)
+		default:
+			continue
+		}
+		analyzer.addModule("\"encoding/binary\"")
+		analyzer.srcTb()
+		analyzer.write(fmt.Sprintf("hash.Write(binary.LittleEndian.AppendUint64(nil, %s))", bits), true)
+		if types.IsStr(memberType) {
+			analyzer.srcTb()
+			analyzer.write(fmt.Sprintf("hash.Write([]byte(%s))", field), true)
+		}
+	}
 	analyzer.srcTb()
-	analyzer.write("return str", true)
+	analyzer.write("return hash.Sum64()", true)
 	analyzer.decTb()
 	analyzer.write("}", true)
 }
 
+// Returns true if the file declares a method of the struct structName
+// whose Go name is goName.
+func (analyzer *TAnalyzer) declaresGoMethod(structName string, goName string) bool {
+	for _, child := range analyzer.file.Ast.AstArr0 {
+		if child.Ttype != AstMethod || len(child.AstArr1) == 0 {
+			continue
+		}
+		receiverNode := child.AstArr1[0]
+		if receiverNode.Ttype == AstTypePointer {
+			receiverNode = receiverNode.Ast0
+		}
+		if receiverNode.Ttype == AstIDN && receiverNode.Str0 == structName && analyzer.state.DeclarationName(analyzer.file.Path, child) == goName {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the "String() str" method the file declares for the struct
+// structName, or nil if it declares none.
+func (analyzer *TAnalyzer) stringMethod(structName string) *TAst {
+	for _, child := range analyzer.file.Ast.AstArr0 {
+		if child.Ttype != AstMethod || child.Ast0.Str0 != "String" || len(child.AstArr1) != 1 {
+			continue
+		}
+		receiverNode := child.AstArr1[0]
+		if receiverNode.Ttype == AstTypePointer {
+			receiverNode = receiverNode.Ast0
+		}
+		if receiverNode.Ttype == AstIDN && receiverNode.Str0 == structName && child.Ast1.Ttype == AstTypeStr {
+			return child
+		}
+	}
+	return nil
+}
+
 func (analyzer *TAnalyzer) visitFunction(node *TAst) {
 	if !analyzer.scope.InGlobal() {
 		RaiseLanguageCompileError(
 			analyzer.file.Path,
 			analyzer.file.Data,
+			CODE_NOT_ALLOWED_HERE,
 			"function is not allowed here",
 			node.Position,
 		)
 	}
+	analyzer.writeDoc(node)
 	analyzer.writePosition(node.Position)
 	functionScope := CreateFunctionScope(analyzer.scope, node.Flg0)
 	localScope := CreateScope(functionScope, ScopeLocal)
@@ -2144,34 +3941,36 @@ func (analyzer *TAnalyzer) visitFunction(node *TAst) {
 		RaiseLanguageCompileError(
 			analyzer.file.Path,
 			analyzer.file.Data,
+			CODE_MISSING_NAME,
 			INVALID_FUNCTION_NAME,
 			nameNode.Position,
 		)
 	}
-	// Function name must use camel case
-	if !isMethod && !IsCamelCase(nameNode.Str0) {
-		RaiseLanguageCompileError(
-			analyzer.file.Path,
-			analyzer.file.Data,
-			"invalid function name, function name must be in a form of camel case",
-			nameNode.Position,
-		)
-	} else if isMethod && !IsPascalCase(nameNode.Str0) {
-		RaiseLanguageCompileError(
-			analyzer.file.Path,
-			analyzer.file.Data,
-			"invalid method name, method name must be in a form of pascal case",
-			nameNode.Position,
-		)
+	if !isMethod && analyzer.file.Env.HasGlobalSymbol(nameNode.Str0) {
+		analyzer.reference(nameNode, analyzer.file.Env.GetSymbol(nameNode.Str0))
 	}
 	analyzer.write("func", false)
 	analyzer.srcSp()
 	var thisArgType *types.TTyping = nil
+	var thisArgTypeNode *TAst = nil
 	if isMethod {
 		analyzer.write("(", false)
 		thisArgNode := paramNamesNode[0]
-		thisArgTypeNode := paramTypesNode[0]
+		thisArgTypeNode = paramTypesNode[0]
 		thisArgType = analyzer.getType(thisArgTypeNode)
+		receiverType := thisArgType
+		if types.IsPointer(receiverType) && receiverType.GetInternal0() != nil {
+			receiverType = receiverType.GetInternal0()
+		}
+		if !types.IsStructInstance(receiverType) || !receiverType.HasConstructor() {
+			RaiseLanguageCompileError(
+				analyzer.file.Path,
+				analyzer.file.Data,
+				CODE_INVALID_METHOD,
+				fmt.Sprintf("invalid method receiver, expected a struct or a pointer to a struct, got %s", thisArgType.ToString()),
+				thisArgTypeNode.Position,
+			)
+		}
 		analyzer.write(fmt.Sprintf("%s %s", thisArgNode.Str0, thisArgType.ToGoType()), false)
 		analyzer.write(")", false)
 		analyzer.srcSp()
@@ -2181,11 +3980,12 @@ func (analyzer *TAnalyzer) visitFunction(node *TAst) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_DUPLICATE,
 				INVALID_FUNCTION_PARAM_NAME_DUPLICATE,
 				thisArgNode.Position,
 			)
 		}
-		analyzer.scope.Env.AddSymbol(TSymbol{
+		analyzer.declare(TSymbol{
 			Name:         thisArgNode.Str0,
 			NameSpace:    thisArgNode.Str0,
 			Module:       "",
@@ -2197,7 +3997,7 @@ func (analyzer *TAnalyzer) visitFunction(node *TAst) {
 			IsInitialize: true, // For parameters, we always initialize them.
 		})
 	}
-	analyzer.write(JoinVariableName(GetFileNameWithoutExtension(analyzer.file.Path), nameNode.Str0), false)
+	analyzer.write(analyzer.state.DeclarationName(analyzer.file.Path, node), false)
 	analyzer.write("(", false)
 	parametersTypesPair := make([]*types.TPair, 0, len(paramNamesNode))
 	for index, paramNameNode := range paramNamesNode {
@@ -2206,19 +4006,11 @@ func (analyzer *TAnalyzer) visitFunction(node *TAst) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_MISSING_NAME,
 				INVALID_FUNCTION_PARAM_NAME,
 				paramNameNode.Position,
 			)
 		}
-		// Parameter name must use camel case
-		if !IsCamelCase(paramNameNode.Str0) {
-			RaiseLanguageCompileError(
-				analyzer.file.Path,
-				analyzer.file.Data,
-				"invalid parameter name, parameter name must be in a form of camel case",
-				paramNameNode.Position,
-			)
-		}
 		parameterType := analyzer.getType(paramTypeNode)
 		parametersTypesPair = append(parametersTypesPair, types.CreatePair(paramNameNode.Str0, parameterType))
 		analyzer.write(fmt.Sprintf("%s %s", paramNameNode.Str0, parameterType.ToGoType()), false)
@@ -2229,11 +4021,12 @@ func (analyzer *TAnalyzer) visitFunction(node *TAst) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_DUPLICATE,
 				INVALID_FUNCTION_PARAM_NAME_DUPLICATE,
 				paramNameNode.Position,
 			)
 		}
-		analyzer.scope.Env.AddSymbol(TSymbol{
+		analyzer.declare(TSymbol{
 			Name:         paramNameNode.Str0,
 			NameSpace:    paramNameNode.Str0,
 			Module:       "",
@@ -2247,6 +4040,7 @@ func (analyzer *TAnalyzer) visitFunction(node *TAst) {
 	}
 	analyzer.write(")", false)
 	returnType := analyzer.getType(returnTypeNode)
+	functionScope.ReturnType = returnType
 	analyzer.srcSp()
 	analyzer.write(returnType.ToGoType(), false)
 	analyzer.srcSp()
@@ -2268,17 +4062,20 @@ func (analyzer *TAnalyzer) visitFunction(node *TAst) {
 		analyzer.srcSp()
 		analyzer.write(returnType.DefaultValue(), false)
 	} else if !types.CanStore(returnType, functionScope.Return) {
-		RaiseLanguageCompileError(
+		ReportLanguageCompileError(
 			analyzer.file.Path,
 			analyzer.file.Data,
+			CODE_TYPE_MISMATCH,
 			fmt.Sprintf("cannot return %s, return type must be %s", functionScope.Return.ToString(), returnType.ToString()),
 			node.Position,
 		)
 	}
 	if functionScope.Panics && !functionScope.HasPanic {
-		RaiseLanguageCompileError(
+		ReportLanguageWarning(
+			CHECK_PANICS,
 			analyzer.file.Path,
 			analyzer.file.Data,
+			CODE_PANICS,
 			"function declared to panic, but it does not actually panic",
 			node.Position,
 		)
@@ -2294,31 +4091,61 @@ func (analyzer *TAnalyzer) visitFunction(node *TAst) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_DUPLICATE,
 				fmt.Sprintf("method '%s' already exists for type %s", nameNode.Str0, thisArgType.ToString()),
 				nameNode.Position,
 			)
 		}
-		thisArgType.AddMethod(
+		// Operator methods take exactly one operand, a method with other
+		// parameters is an ordinary one, such as Less of sort.Interface
+		if types.IsOperatorMethodName(nameNode.Str0) && len(parametersTypesPair) == 1 {
+			// Operands may be temporaries, which cannot be addressed
+			if types.IsPointer(thisArgType) {
+				RaiseLanguageCompileError(
+					analyzer.file.Path,
+					analyzer.file.Data,
+					CODE_INVALID_METHOD,
+					fmt.Sprintf("operator method '%s' must use a value receiver", nameNode.Str0),
+					thisArgTypeNode.Position,
+				)
+			}
+			if types.IsComparisonMethodName(nameNode.Str0) && !types.IsBool(returnType) {
+				RaiseLanguageCompileError(
+					analyzer.file.Path,
+					analyzer.file.Data,
+					CODE_INVALID_METHOD,
+					fmt.Sprintf("operator method '%s' must return bool, got %s", nameNode.Str0, returnType.ToString()),
+					returnTypeNode.Position,
+				)
+			} else if types.IsVoid(returnType) {
+				RaiseLanguageCompileError(
+					analyzer.file.Path,
+					analyzer.file.Data,
+					CODE_INVALID_METHOD,
+					fmt.Sprintf("operator method '%s' must return a value", nameNode.Str0),
+					returnTypeNode.Position,
+				)
+			}
+		}
+		// Value and pointer receivers are callable on both forms
+		types.AddReceiverMethod(
+			thisArgType,
 			nameNode.Str0,
-			JoinVariableName(GetFileNameWithoutExtension(analyzer.file.Path), nameNode.Str0),
-			types.TFunc(
+			analyzer.state.DeclarationName(analyzer.file.Path, node),
+			types.SetPointerReceiver(types.TFunc(
 				false,
 				parametersTypesPair,
 				returnType,
 				panics,
-			),
+			), types.IsPointer(thisArgType)),
 		)
+		analyzer.memberReference(nameNode, thisArgType, thisArgType.GetMethod(nameNode.Str0), true)
 	}
 	// Check if there are any unused variables.
 	env := localScope.Env
 	for _, symbol := range env.Symbols {
 		if !symbol.IsUsed {
-			RaiseLanguageCompileError(
-				analyzer.file.Path,
-				analyzer.file.Data,
-				fmt.Sprintf("unused variable: %s", symbol.Name),
-				symbol.Position,
-			)
+			analyzer.unusedVariable(symbol)
 		}
 	}
 }
@@ -2338,6 +4165,7 @@ func (analyzer *TAnalyzer) visitImport(node *TAst) {
 		RaiseLanguageCompileError(
 			analyzer.file.Path,
 			analyzer.file.Data,
+			CODE_NOT_ALLOWED_HERE,
 			"import is not allowed here",
 			node.Position,
 		)
@@ -2345,22 +4173,31 @@ func (analyzer *TAnalyzer) visitImport(node *TAst) {
 	analyzer.writePosition(node.Position)
 	pathNode := node.Ast0
 	namesNode := node.AstArr0
+	aliasNodes := node.AstArr1
 
 	// Validate import path format
 	if pathNode.Ttype != AstStr {
 		RaiseLanguageCompileError(
 			analyzer.file.Path,
 			analyzer.file.Data,
+			CODE_IMPORT,
 			"invalid import path, import path must be in a form of string",
 			pathNode.Position,
 		)
 	}
 
+	// Namespace imports are resolved where their members are used
+	if node.Flg0 {
+		analyzer.write(fmt.Sprintf("/* import %s -> * as %s */", pathNode.Str0, node.Ast1.Str0), false)
+		return
+	}
+
 	// Ensure import has at least one attribute
 	if len(namesNode) == 0 {
 		RaiseLanguageCompileError(
 			analyzer.file.Path,
 			analyzer.file.Data,
+			CODE_IMPORT,
 			"invalid import, import must have at least one attribute",
 			node.Position,
 		)
@@ -2368,33 +4205,48 @@ func (analyzer *TAnalyzer) visitImport(node *TAst) {
 
 	if strings.HasPrefix(pathNode.Str0, "go:") {
 		// Make sure this was handled by Forwarder
-		pkg := pathNode.Str0[3:]
-		analyzer.addModule(fmt.Sprintf("\"%s\"", pkg))
+		path := pathNode.Str0[3:]
+		pkg := GoPackageName(path)
 		asTypes := make([]*types.TPair, 0)
 		asVars := make([]*types.TPair, 0)
+		asConsts := make([]*types.TPair, 0)
 
-		for _, nameNode := range namesNode {
+		for index, nameNode := range namesNode {
 			if nameNode.Ttype != AstIDN {
 				RaiseLanguageCompileError(
 					analyzer.file.Path,
 					analyzer.file.Data,
+					CODE_IMPORT,
 					INVALID_IMPORT_NAME,
 					nameNode.Position,
 				)
 			}
-			if !analyzer.scope.Env.HasLocalSymbol(nameNode.Str0) {
+			// The pair maps the name in the script to the name in Go
+			name := nameNode.Str0
+			if aliasNodes[index] != nil {
+				name = aliasNodes[index].Str0
+			}
+			if !analyzer.scope.Env.HasLocalSymbol(name) {
 				RaiseLanguageCompileError(
 					analyzer.file.Path,
 					analyzer.file.Data,
+					CODE_IMPORT_SYMBOL,
 					fmt.Sprintf("symbol %s not found in import", nameNode.Str0),
 					nameNode.Position,
 				)
 			}
-			symbol := analyzer.scope.Env.GetSymbol(nameNode.Str0)
+			symbol := analyzer.scope.Env.GetSymbol(name)
+			if types.IsGeneric(symbol.DataType) {
+				// Named through the package where it is called
+				continue
+			}
+			analyzer.addModule(fmt.Sprintf("\"%s\"", path))
 			if types.IsStruct(symbol.DataType) {
-				asTypes = append(asTypes, types.CreatePair(nameNode.Str0, symbol.DataType))
+				asTypes = append(asTypes, types.CreatePairWithNamespace(name, nameNode.Str0, symbol.DataType))
+			} else if IsGoConstant(PackagesGetName(GetGoPackages(path), nameNode.Str0)) {
+				asConsts = append(asConsts, types.CreatePairWithNamespace(name, nameNode.Str0, symbol.DataType))
 			} else {
-				asVars = append(asVars, types.CreatePair(nameNode.Str0, symbol.DataType))
+				asVars = append(asVars, types.CreatePairWithNamespace(name, nameNode.Str0, symbol.DataType))
 			}
 		}
 
@@ -2404,9 +4256,29 @@ func (analyzer *TAnalyzer) visitImport(node *TAst) {
 				analyzer.write("type", false)
 				analyzer.srcSp()
 				analyzer.write(info.NameSpace, false)
-				analyzer.srcSp()
-				analyzer.write(fmt.Sprintf("%s.%s", pkg, asType.Name), true)
+				// An alias, so that the type keeps the methods of the Go one
+				analyzer.write(" = ", false)
+				analyzer.write(fmt.Sprintf("%s.%s", pkg, asType.Namespace), true)
+			}
+		}
+
+		if len(asConsts) > 0 {
+			// Untyped constants stay untyped, Go converts them where they are used
+			analyzer.write("const", false)
+			analyzer.write("(", true)
+			analyzer.incTb()
+			for _, asConst := range asConsts {
+				info := analyzer.scope.Env.GetSymbol(asConst.Name)
+				analyzer.srcTb()
+				analyzer.write(fmt.Sprintf(
+					"%s = %s.%s",
+					info.NameSpace,
+					pkg,
+					asConst.Namespace,
+				), true)
 			}
+			analyzer.decTb()
+			analyzer.write(")", true)
 		}
 
 		if len(asVars) > 0 {
@@ -2423,7 +4295,7 @@ func (analyzer *TAnalyzer) visitImport(node *TAst) {
 						info.NameSpace,
 						asVar.DataType.ToGoType(),
 						GetArrayConstructor(elementType),
-						pkg, asVar.Name,
+						pkg, asVar.Namespace,
 					), true)
 				} else if types.IsMap(info.DataType) {
 					keyType := info.DataType.GetInternal0()
@@ -2434,15 +4306,15 @@ func (analyzer *TAnalyzer) visitImport(node *TAst) {
 						asVar.DataType.ToGoType(),
 						GetMapConstructor(keyType, valueType),
 						pkg,
-						asVar.Name,
+						asVar.Namespace,
 					), true)
 				} else {
+					// Go types the binding, the ns type may not spell the Go one
 					analyzer.write(fmt.Sprintf(
-						"%s %s = %s.%s",
+						"%s = %s.%s",
 						info.NameSpace,
-						asVar.DataType.ToGoType(),
 						pkg,
-						asVar.Name,
+						asVar.Namespace,
 					), true)
 				}
 			}
@@ -2452,22 +4324,38 @@ func (analyzer *TAnalyzer) visitImport(node *TAst) {
 		return
 	}
 
-	// Ensure import path is relative
-	if !(strings.HasPrefix(pathNode.Str0, "./") || strings.HasPrefix(pathNode.Str0, "../")) {
+	// Ensure import path is relative or names a standard library module
+	actualPath := ""
+	if IsLibPath(pathNode.Str0) {
+		libPath, ok := ResolveLibPath(analyzer.state.LibPath, pathNode.Str0)
+		if !ok {
+			RaiseLanguageCompileError(
+				analyzer.file.Path,
+				analyzer.file.Data,
+				CODE_IMPORT,
+				INVALID_IMPORT_LIB_NAME,
+				pathNode.Position,
+			)
+		}
+		actualPath = libPath
+	} else if strings.HasPrefix(pathNode.Str0, "./") || strings.HasPrefix(pathNode.Str0, "../") {
+		actualPath = ResolvePath(GetDir(analyzer.file.Path), pathNode.Str0)
+	} else {
 		RaiseLanguageCompileError(
 			analyzer.file.Path,
 			analyzer.file.Data,
-			"invalid import path, import path must be relative",
+			CODE_IMPORT,
+			"invalid import path, import path must be relative, or a go: or lib: import",
 			pathNode.Position,
 		)
 	}
 
 	// Verify imported file exists
-	actualPath := ResolvePath(GetDir(analyzer.file.Path), pathNode.Str0)
 	if !analyzer.state.HasFile(actualPath) {
 		RaiseLanguageCompileError(
 			analyzer.file.Path,
 			analyzer.file.Data,
+			CODE_IMPORT,
 			"imported file not found",
 			pathNode.Position,
 		)
@@ -2479,6 +4367,7 @@ func (analyzer *TAnalyzer) visitImport(node *TAst) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_IMPORT,
 				INVALID_IMPORT_NAME,
 				nameNode.Position,
 			)
@@ -2495,6 +4384,7 @@ func (analyzer *TAnalyzer) visitVar(node *TAst) {
 		RaiseLanguageCompileError(
 			analyzer.file.Path,
 			analyzer.file.Data,
+			CODE_NOT_ALLOWED_HERE,
 			"variable is not allowed here",
 			node.Position,
 		)
@@ -2513,24 +4403,17 @@ func (analyzer *TAnalyzer) visitVar(node *TAst) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_MISSING_NAME,
 				INVALID_VARIABLE_NAME,
 				nameNode.Position,
 			)
 		}
-		// Global variable must use pascal case
-		if !IsPascalCase(nameNode.Str0) {
-			RaiseLanguageCompileError(
-				analyzer.file.Path,
-				analyzer.file.Data,
-				"invalid variable name, global variable must be in a form of pascal case",
-				nameNode.Position,
-			)
-		}
 		dataType := analyzer.getType(typeNode)
 		if types.IsVoid(dataType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_TYPE,
 				"invalid variable type, variable type cannot be void",
 				nameNode.Position,
 			)
@@ -2546,12 +4429,14 @@ func (analyzer *TAnalyzer) visitVar(node *TAst) {
 		analyzer.write(fmt.Sprintf("%s %s", variableName, goType), false)
 		if valuNode != nil {
 			analyzer.write(" = ", false)
-			analyzer.expression(valuNode)
-			valueType := analyzer.stack.Pop().DataType
+			value := analyzer.storeExpression(valuNode, dataType)
+			valueType := value.DataType
+			analyzer.checkConstantFits(dataType, value, nameNode.Position)
 			if !types.CanStore(dataType, valueType) {
 				RaiseLanguageCompileError(
 					analyzer.file.Path,
 					analyzer.file.Data,
+					CODE_TYPE_MISMATCH,
 					fmt.Sprintf("cannot assign %s to %s", valueType.ToString(), dataType.ToString()),
 					nameNode.Position,
 				)
@@ -2571,11 +4456,13 @@ func (analyzer *TAnalyzer) visitVar(node *TAst) {
 				RaiseLanguageCompileError(
 					analyzer.file.Path,
 					analyzer.file.Data,
+					CODE_DUPLICATE,
 					INVALID_VARIABLE_NAME_DUPLICATE,
 					nameNode.Position,
+					NoteDeclaredAt(analyzer.scope.Env.GetSymbol(nameNode.Str0)),
 				)
 			}
-			analyzer.scope.Env.AddSymbol(TSymbol{
+			analyzer.declare(TSymbol{
 				Name:         nameNode.Str0,
 				NameSpace:    variableName,
 				DataType:     dataType,
@@ -2597,6 +4484,7 @@ func (analyzer *TAnalyzer) visitConst(node *TAst) {
 		RaiseLanguageCompileError(
 			analyzer.file.Path,
 			analyzer.file.Data,
+			CODE_NOT_ALLOWED_HERE,
 			"constant is not allowed here",
 			node.Position,
 		)
@@ -2620,31 +4508,17 @@ func (analyzer *TAnalyzer) visitConst(node *TAst) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_MISSING_NAME,
 				INVALID_VARIABLE_NAME,
 				nameNode.Position,
 			)
 		}
-		// Global variable must use pascal case
-		if analyzer.scope.InGlobal() && !IsPascalCase(nameNode.Str0) {
-			RaiseLanguageCompileError(
-				analyzer.file.Path,
-				analyzer.file.Data,
-				"invalid constant name, global constant name must be in a form of pascal case",
-				nameNode.Position,
-			)
-		} else if !analyzer.scope.InGlobal() && !IsCamelCase(nameNode.Str0) {
-			RaiseLanguageCompileError(
-				analyzer.file.Path,
-				analyzer.file.Data,
-				"invalid constant name, local constant name must be in a form of camel case",
-				nameNode.Position,
-			)
-		}
 		dataType := analyzer.getType(typeNode)
 		if types.IsVoid(dataType) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_TYPE,
 				"invalid constant type, constant type cannot be void",
 				nameNode.Position,
 			)
@@ -2660,27 +4534,40 @@ func (analyzer *TAnalyzer) visitConst(node *TAst) {
 			variableName = JoinVariableName(GetFileNameWithoutExtension(analyzer.file.Path), nameNode.Str0)
 		}
 		analyzer.write(fmt.Sprintf("%s %s", variableName, dataType.ToGoType()), false)
-
-		if analyzer.scope.InGlobal() && valuNode != nil && !IsConstantValueNode(valuNode) {
-			RaiseLanguageCompileError(
-				analyzer.file.Path,
-				analyzer.file.Data,
-				"invalid constant value, constant value must be a literal constant",
-				nameNode.Position,
-			)
-		}
+		var constant interface{} = nil
 		if valuNode != nil {
 			analyzer.write(" = ", false)
-			analyzer.expression(valuNode)
-			valueType := analyzer.stack.Pop().DataType
+			if analyzer.scope.InGlobal() {
+				// A constant that refers to itself is reported by globalConstant
+				analyzer.constants[nameNode.Str0] = nil
+			}
+			valueSrc, value := analyzer.captureConstant(nameNode, valuNode, dataType)
+			valueType := value.DataType
+			analyzer.checkConstantFits(dataType, value, nameNode.Position)
 			if !types.CanStore(dataType, valueType) {
 				RaiseLanguageCompileError(
 					analyzer.file.Path,
 					analyzer.file.Data,
+					CODE_TYPE_MISMATCH,
 					fmt.Sprintf("cannot assign %s to %s", valueType.ToString(), dataType.ToString()),
 					nameNode.Position,
 				)
 			}
+			if analyzer.scope.InGlobal() && value.Data == nil {
+				RaiseLanguageCompileError(
+					analyzer.file.Path,
+					analyzer.file.Data,
+					CODE_CONSTANT_EXPRESSION,
+					"invalid constant value, constant value must be a constant expression",
+					nameNode.Position,
+				)
+			}
+			// Fold the initializer, so later constant expressions can use it
+			constant = CoerceConstant(value.Data, dataType)
+			if constant != nil {
+				valueSrc = ConstantToGo(constant)
+			}
+			analyzer.write(valueSrc, false)
 		} else {
 			analyzer.write(" = ", false)
 			analyzer.write(dataType.DefaultValue(), false)
@@ -2696,11 +4583,13 @@ func (analyzer *TAnalyzer) visitConst(node *TAst) {
 				RaiseLanguageCompileError(
 					analyzer.file.Path,
 					analyzer.file.Data,
+					CODE_DUPLICATE,
 					INVALID_VARIABLE_NAME_DUPLICATE,
 					nameNode.Position,
+					NoteDeclaredAt(analyzer.scope.Env.GetSymbol(nameNode.Str0)),
 				)
 			}
-			analyzer.scope.Env.AddSymbol(TSymbol{
+			analyzer.declare(TSymbol{
 				Name:         nameNode.Str0,
 				NameSpace:    variableName,
 				Module:       "",
@@ -2710,13 +4599,65 @@ func (analyzer *TAnalyzer) visitConst(node *TAst) {
 				IsConst:      true,
 				IsUsed:       false,
 				IsInitialize: valuNode != nil,
+				Value:        constant,
 			})
+		} else {
+			analyzer.scope.Env.UpdateSymbolValue(nameNode.Str0, constant)
 		}
 	}
 	analyzer.decTb()
 	analyzer.srcNl()
 	analyzer.srcTb()
 	analyzer.write(")", false)
+	// A local constant is a Go variable, which Go never reads once every
+	// use of it is folded
+	if analyzer.scope.InLocal() {
+		for _, nameNode := range namesNode {
+			analyzer.write("; _ = "+nameNode.Str0, false)
+		}
+	}
+}
+
+// Folds the value of a global constant of the file that is declared
+// after the constant being analyzed, or used before its declaration.
+func (analyzer *TAnalyzer) globalConstant(node *TAst) {
+	declaration, ok := analyzer.constants[node.Str0]
+	if !ok {
+		return
+	}
+	if declaration == nil {
+		// Reported once, later uses find no value to fold
+		delete(analyzer.constants, node.Str0)
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_CONSTANT_EXPRESSION,
+			fmt.Sprintf("invalid constant value, constant %s refers to itself", node.Str0),
+			node.Position,
+		)
+	}
+	analyzer.constants[node.Str0] = nil
+	defer delete(analyzer.constants, node.Str0)
+	scope := analyzer.scope
+	defer func() { analyzer.scope = scope }()
+	for analyzer.scope.Parent != nil {
+		analyzer.scope = analyzer.scope.Parent
+	}
+	for index, nameNode := range declaration.AstArr0 {
+		if nameNode.Str0 != node.Str0 || declaration.AstArr2[index] == nil {
+			continue
+		}
+		dataType := analyzer.getType(declaration.AstArr1[index])
+		_, value := analyzer.captureStore(declaration.AstArr2[index], dataType)
+		analyzer.scope.Env.UpdateSymbolValue(node.Str0, CoerceConstant(value.Data, dataType))
+	}
+}
+
+// Evaluates the initializer of a constant, which is no longer folding
+// once it is evaluated, even when it has an error.
+func (analyzer *TAnalyzer) captureConstant(nameNode *TAst, valueNode *TAst, dataType *types.TTyping) (string, TValue) {
+	defer delete(analyzer.constants, nameNode.Str0)
+	return analyzer.captureStore(valueNode, dataType)
 }
 
 func (analyzer *TAnalyzer) visitLocal(node *TAst) {
@@ -2725,6 +4666,7 @@ func (analyzer *TAnalyzer) visitLocal(node *TAst) {
 		RaiseLanguageCompileError(
 			analyzer.file.Path,
 			analyzer.file.Data,
+			CODE_NOT_ALLOWED_HERE,
 			"local variable is not allowed here",
 			node.Position,
 		)
@@ -2749,21 +4691,12 @@ func (analyzer *TAnalyzer) visitLocal(node *TAst) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_MISSING_NAME,
 				INVALID_VARIABLE_NAME,
 				nameNode.Position,
 			)
 		}
 
-		// Local variable must use camel case
-		if !IsCamelCase(nameNode.Str0) {
-			RaiseLanguageCompileError(
-				analyzer.file.Path,
-				analyzer.file.Data,
-				"invalid variable name, local variable must be in a form of camel case",
-				nameNode.Position,
-			)
-		}
-
 		dataType := analyzer.getType(typeNode)
 
 		// Validate variable type
@@ -2771,6 +4704,7 @@ func (analyzer *TAnalyzer) visitLocal(node *TAst) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_TYPE,
 				"invalid variable type, variable type cannot be void",
 				nameNode.Position,
 			)
@@ -2788,14 +4722,19 @@ func (analyzer *TAnalyzer) visitLocal(node *TAst) {
 		// Handle variable initialization
 		if valuNode != nil {
 			analyzer.write(" = ", false)
-			analyzer.expression(valuNode)
-			valueType := analyzer.stack.Pop().DataType
+			if valuNode.Ttype == AstRun {
+				analyzer.task = valuNode
+			}
+			value := analyzer.storeExpression(valuNode, dataType)
+			valueType := value.DataType
 
-			// Type compatibility check
+			// Constant range and type compatibility check
+			analyzer.checkConstantFits(dataType, value, nameNode.Position)
 			if !types.CanStore(dataType, valueType) {
 				RaiseLanguageCompileError(
 					analyzer.file.Path,
 					analyzer.file.Data,
+					CODE_TYPE_MISMATCH,
 					fmt.Sprintf("cannot assign %s to %s", valueType.ToString(), dataType.ToString()),
 					nameNode.Position,
 				)
@@ -2820,12 +4759,14 @@ func (analyzer *TAnalyzer) visitLocal(node *TAst) {
 				RaiseLanguageCompileError(
 					analyzer.file.Path,
 					analyzer.file.Data,
+					CODE_DUPLICATE,
 					INVALID_VARIABLE_NAME_DUPLICATE,
 					nameNode.Position,
+					NoteDeclaredAt(analyzer.scope.Env.GetSymbol(nameNode.Str0)),
 				)
 			}
 
-			analyzer.scope.Env.AddSymbol(TSymbol{
+			analyzer.declare(TSymbol{
 				Name:         nameNode.Str0,
 				NameSpace:    nameNode.Str0,
 				Module:       "",
@@ -2835,6 +4776,7 @@ func (analyzer *TAnalyzer) visitLocal(node *TAst) {
 				IsConst:      false,
 				IsUsed:       false,
 				IsInitialize: valuNode != nil,
+				IsTask:       valuNode != nil && valuNode.Ttype == AstRun && analyzer.scope.Parallel() != nil,
 			})
 		}
 	}
@@ -2876,11 +4818,12 @@ func (analyzer *TAnalyzer) visitForInit(node *TAst) {
 				RaiseLanguageCompileError(
 					analyzer.file.Path,
 					analyzer.file.Data,
+					CODE_DUPLICATE,
 					"invalid for statement, variable name already exists",
 					node.Position,
 				)
 			}
-			analyzer.scope.Env.AddSymbol(TSymbol{
+			analyzer.declare(TSymbol{
 				Name:         variableName,
 				NameSpace:    variableName,
 				Module:       "",
@@ -2900,12 +4843,19 @@ func (analyzer *TAnalyzer) visitForInit(node *TAst) {
 		for index, valuNode := range valusNode {
 			dataType := analyzer.getType(typesNode[index])
 			if valuNode != nil {
-				analyzer.expression(valuNode)
-				valueType := analyzer.stack.Pop().DataType
+				src, value := analyzer.captureStore(valuNode, dataType)
+				if types.IsAnyNumber(dataType) && value.Untyped {
+					// ":=" would give an untyped constant Go's default type
+					src = fmt.Sprintf("%s(%s)", dataType.GoValueType(), src)
+				}
+				analyzer.write(src, false)
+				valueType := value.DataType
+				analyzer.checkConstantFits(dataType, value, valuNode.Position)
 				if !types.CanStore(dataType, valueType) {
 					RaiseLanguageCompileError(
 						analyzer.file.Path,
 						analyzer.file.Data,
+						CODE_TYPE_MISMATCH,
 						fmt.Sprintf("cannot assign %s to %s", valueType.ToString(), dataType.ToString()),
 						valuNode.Position,
 					)
@@ -2925,6 +4875,7 @@ func (analyzer *TAnalyzer) visitForInit(node *TAst) {
 		RaiseLanguageCompileError(
 			analyzer.file.Path,
 			analyzer.file.Data,
+			CODE_INVALID_SYNTAX,
 			"invalid for statement, for statement must have a mutator or condition",
 			node.Position,
 		)
@@ -2960,6 +4911,7 @@ func (analyzer *TAnalyzer) visitFor(node *TAst) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_SYNTAX,
 				"invalid for statement, for statement must have a mutator",
 				node.Position,
 			)
@@ -2990,6 +4942,7 @@ func (analyzer *TAnalyzer) visitFor(node *TAst) {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
+				CODE_INVALID_SYNTAX,
 				"invalid for statement, for statement must have a condition",
 				node.Position,
 			)
@@ -3030,12 +4983,7 @@ func (analyzer *TAnalyzer) visitFor(node *TAst) {
 	env := analyzer.scope.Env
 	for _, symbol := range env.Symbols {
 		if !symbol.IsUsed {
-			RaiseLanguageCompileError(
-				analyzer.file.Path,
-				analyzer.file.Data,
-				fmt.Sprintf("unused variable: %s", symbol.Name),
-				symbol.Position,
-			)
+			analyzer.unusedVariable(symbol)
 		}
 	}
 
@@ -3049,27 +4997,28 @@ func (analyzer *TAnalyzer) visitIf(node *TAst) {
 	elseNode := node.Ast2
 	analyzer.write("if", false)
 	analyzer.srcSp()
-	analyzer.expression(conditionNode)
-	analyzer.stack.Pop()
-	analyzer.srcSp()
-	if thenNode.Ttype == AstCodeBlock {
-		analyzer.scope = CreateScope(analyzer.scope, ScopeConditional)
-		analyzer.scope = CreateScope(analyzer.scope, ScopeSingle)
-		analyzer.statement(thenNode)
-		analyzer.scope = analyzer.scope.Parent // Leave the single scope
-		analyzer.scope = analyzer.scope.Parent // Leave the conditional scope
+	if conditionNode.Ttype == AstIs && conditionNode.Ast0.Ttype == AstIDN {
+		// "if (x is T)" narrows x to T within the then branch
+		src, dataType := analyzer.typeTest(conditionNode)
+		narrowed := analyzer.narrowSymbol(conditionNode.Ast0, analyzer.tempName(conditionNode.Ast0.Str0), dataType)
+		ok := analyzer.tempName("ok")
+		saveSrc := analyzer.src
+		analyzer.src = ""
+		used := analyzer.visitBranch(thenNode, narrowed)
+		body := analyzer.src
+		analyzer.src = saveSrc
+		binding := narrowed.NameSpace
+		if !used {
+			binding = "_"
+		}
+		analyzer.write(fmt.Sprintf("%s, %s := %s.(%s); %s", binding, ok, src, dataType.ToGoType(), ok), false)
+		analyzer.srcSp()
+		analyzer.write(body, false)
 	} else {
-		analyzer.write("{", true)
-		analyzer.incTb()
-		analyzer.scope = CreateScope(analyzer.scope, ScopeConditional)
-		analyzer.scope = CreateScope(analyzer.scope, ScopeSingle)
-		analyzer.statement(thenNode)
-		analyzer.scope = analyzer.scope.Parent // Leave the single scope
-		analyzer.scope = analyzer.scope.Parent // Leave the conditional scope
-		analyzer.decTb()
-		analyzer.srcNl()
-		analyzer.srcTb()
-		analyzer.write("}", false)
+		analyzer.expression(conditionNode)
+		analyzer.stack.Pop()
+		analyzer.srcSp()
+		analyzer.visitBranch(thenNode, nil)
 	}
 	if elseNode != nil {
 		analyzer.write("else", false)
@@ -3107,44 +5056,332 @@ func (analyzer *TAnalyzer) visitCodeBlock(node *TAst) {
 	analyzer.srcNl()
 	analyzer.srcTb()
 	analyzer.write("}", false)
+	analyzer.checkUnused()
 
-	// Check if there are any unused variables.
+	// Leave the Local scope
+	analyzer.scope = analyzer.scope.Parent
+}
+
+// Check if there are any unused variables in the current scope.
+func (analyzer *TAnalyzer) checkUnused() {
 	env := analyzer.scope.Env
 	for _, symbol := range env.Symbols {
 		if !symbol.IsUsed {
+			analyzer.unusedVariable(symbol)
+		}
+	}
+}
+
+func (analyzer *TAnalyzer) visitSwitch(node *TAst) {
+	cases := node.AstArr0
+	isTypeSwitch := cases[0].Flg0
+	for _, clause := range cases {
+		if clause.Flg0 != isTypeSwitch {
 			RaiseLanguageCompileError(
 				analyzer.file.Path,
 				analyzer.file.Data,
-				fmt.Sprintf("unused variable: %s", symbol.Name),
-				symbol.Position,
+				CODE_SWITCH,
+				"cannot mix type cases and value cases in a switch",
+				clause.Position,
 			)
 		}
 	}
+	analyzer.scope = CreateScope(analyzer.scope, ScopeSwitch)
+	if isTypeSwitch {
+		analyzer.visitTypeSwitch(node)
+	} else {
+		analyzer.visitValueSwitch(node)
+	}
+	analyzer.scope = analyzer.scope.Parent
+}
 
-	// Leave the Local scope
+// Lowers "switch (x) { case 1, 2: ... }" to a Go expression switch.
+// Cases never fall through.
+func (analyzer *TAnalyzer) visitValueSwitch(node *TAst) {
+	subjectNode := node.Ast0
+	subjectSrc, subjectValue := analyzer.captureExpression(subjectNode)
+	subjectType := subjectValue.DataType
+	if !types.IsAnyNumber(subjectType) && !types.IsStr(subjectType) && !types.IsBool(subjectType) {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_SWITCH,
+			fmt.Sprintf("cannot switch on %s, switch values must be numbers, strings or booleans", subjectType.ToString()),
+			subjectNode.Position,
+		)
+	}
+	analyzer.write(fmt.Sprintf("switch %s {", subjectSrc), true)
+	seen := make(map[string]bool)
+	for _, clause := range node.AstArr0 {
+		labels := make([]string, 0)
+		for _, labelNode := range clause.AstArr0 {
+			src, value := analyzer.captureExpression(labelNode)
+			if value.Data != nil && types.IsAnyInt(subjectType) && types.IsAnyInt(value.DataType) {
+				analyzer.checkConstantFits(subjectType, value, labelNode.Position)
+			} else if !types.CanStore(subjectType, value.DataType) {
+				RaiseLanguageCompileError(
+					analyzer.file.Path,
+					analyzer.file.Data,
+					CODE_SWITCH,
+					fmt.Sprintf("cannot use %s as %s in switch case", value.DataType.ToString(), subjectType.ToString()),
+					labelNode.Position,
+				)
+			}
+			if value.Data != nil {
+				// Go rejects duplicate constant cases
+				key := ConstantToGo(CoerceConstant(value.Data, subjectType))
+				if seen[key] {
+					RaiseLanguageCompileError(
+						analyzer.file.Path,
+						analyzer.file.Data,
+						CODE_SWITCH,
+						fmt.Sprintf("duplicate case %s in switch", key),
+						labelNode.Position,
+					)
+				}
+				seen[key] = true
+			}
+			labels = append(labels, analyzer.convert(src, value, subjectType))
+		}
+		analyzer.visitClause(clause, fmt.Sprintf("case %s:", strings.Join(labels, ", ")), nil)
+	}
+	if node.Ast1 != nil {
+		analyzer.visitClause(node.Ast1, "default:", nil)
+	}
+	analyzer.srcTb()
+	analyzer.write("}", false)
+}
+
+// Lowers "switch (x) { case is T: ... }" to a Go type switch. Within a case
+// that tests a single type, x is narrowed to that type.
+func (analyzer *TAnalyzer) visitTypeSwitch(node *TAst) {
+	subjectNode := node.Ast0
+	subjectSrc, subjectValue := analyzer.captureExpression(subjectNode)
+	if !types.IsAny(subjectValue.DataType) {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_SWITCH,
+			fmt.Sprintf("cannot switch on the type of %s, only any values have a dynamic type", subjectValue.DataType.ToString()),
+			subjectNode.Position,
+		)
+	}
+	if types.IsGoInterface(subjectValue.DataType) {
+		subjectSrc = fmt.Sprintf("any(%s)", subjectSrc)
+	}
+	binding := ""
+	if subjectNode.Ttype == AstIDN {
+		binding = analyzer.tempName(subjectNode.Str0)
+	}
+	saveSrc := analyzer.src
+	analyzer.src = ""
+	used := false
+	seen := make(map[string]bool)
+	for _, clause := range node.AstArr0 {
+		labels := make([]string, 0)
+		var dataType *types.TTyping = nil
+		for _, labelNode := range clause.AstArr0 {
+			dataType = analyzer.getType(labelNode)
+			if types.IsVoid(dataType) {
+				RaiseLanguageCompileError(
+					analyzer.file.Path,
+					analyzer.file.Data,
+					CODE_INVALID_TYPE,
+					fmt.Sprintf("invalid type in type test: %s", dataType.ToString()),
+					labelNode.Position,
+				)
+			}
+			goType := dataType.ToGoType()
+			if seen[goType] {
+				RaiseLanguageCompileError(
+					analyzer.file.Path,
+					analyzer.file.Data,
+					CODE_SWITCH,
+					fmt.Sprintf("duplicate case %s in type switch", dataType.ToString()),
+					labelNode.Position,
+				)
+			}
+			seen[goType] = true
+			labels = append(labels, goType)
+		}
+		var narrowed *TSymbol = nil
+		if binding != "" && len(labels) == 1 {
+			narrowed = analyzer.narrowSymbol(subjectNode, binding, dataType)
+		}
+		used = analyzer.visitClause(clause, fmt.Sprintf("case %s:", strings.Join(labels, ", ")), narrowed) || used
+	}
+	if node.Ast1 != nil {
+		analyzer.visitClause(node.Ast1, "default:", nil)
+	}
+	body := analyzer.src
+	analyzer.src = saveSrc
+	if used {
+		analyzer.write(fmt.Sprintf("switch %s := %s.(type) {", binding, subjectSrc), true)
+	} else {
+		analyzer.write(fmt.Sprintf("switch %s.(type) {", subjectSrc), true)
+	}
+	analyzer.write(body, false)
+	analyzer.srcTb()
+	analyzer.write("}", false)
+}
+
+func (analyzer *TAnalyzer) visitSelect(node *TAst) {
+	analyzer.scope = CreateScope(analyzer.scope, ScopeSelect)
+	analyzer.write("select {", true)
+	for _, clause := range node.AstArr0 {
+		analyzer.visitClause(clause, "", nil)
+	}
+	if node.Ast1 != nil {
+		analyzer.visitClause(node.Ast1, "default:", nil)
+	}
+	analyzer.srcTb()
+	analyzer.write("}", false)
 	analyzer.scope = analyzer.scope.Parent
 }
 
+// Emits the communication of a select case: a send, a receive, a receive
+// into a variable, or "timeout(ms)", which receives from time.After.
+func (analyzer *TAnalyzer) selectCase(node *TAst) {
+	switch node.Ttype {
+	case AstReceive,
+		AstSend:
+		analyzer.expression(node)
+		analyzer.stack.Pop()
+		return
+	case AstAssign,
+		AstBindAssign:
+		if node.Ast0.Ttype != AstTupleExpression && node.Ast1.Ttype == AstReceive {
+			analyzer.expression(node)
+			analyzer.stack.Pop()
+			return
+		}
+	case AstCall:
+		if analyzer.isBuiltin(node.Ast0, GLOBAL_TIMEOUT) && len(node.AstArr0) == 1 {
+			src, value := analyzer.captureStore(node.AstArr0[0], analyzer.state.TI64)
+			if !types.IsAnyInt(value.DataType) {
+				RaiseLanguageCompileError(
+					analyzer.file.Path,
+					analyzer.file.Data,
+					CODE_CONCURRENCY,
+					fmt.Sprintf("timeout must be an integer number of milliseconds, got %s", value.DataType.ToString()),
+					node.AstArr0[0].Position,
+				)
+			}
+			time := analyzer.packageAlias("time", "time")
+			analyzer.write(fmt.Sprintf("<-%s.After(%s.Duration(%s) * %s.Millisecond)", time, time, src, time), false)
+			return
+		}
+	}
+	RaiseLanguageCompileError(
+		analyzer.file.Path,
+		analyzer.file.Data,
+		CODE_SWITCH,
+		"select case must be a send, a receive or a timeout",
+		node.Position,
+	)
+}
+
+// Analyzes a switch or select case under the given label, see visitBranch.
+// A select case writes its own label, since the variables it declares
+// belong to the scope of the case.
+func (analyzer *TAnalyzer) visitClause(node *TAst, label string, narrowed *TSymbol) bool {
+	analyzer.scope = CreateScope(analyzer.scope, ScopeConditional)
+	if narrowed != nil {
+		analyzer.scope.Env.AddSymbol(*narrowed)
+	}
+	analyzer.scope = CreateScope(analyzer.scope, ScopeLocal)
+	analyzer.srcTb()
+	if node.Ast0 != nil {
+		analyzer.write("case ", false)
+		analyzer.selectCase(node.Ast0)
+		analyzer.write(":", true)
+	} else {
+		analyzer.write(label, true)
+	}
+	analyzer.incTb()
+	for _, statement := range node.AstArr1 {
+		analyzer.statement(statement)
+		analyzer.srcNl()
+	}
+	analyzer.decTb()
+	analyzer.checkUnused()
+	analyzer.scope = analyzer.scope.Parent // Leave the local scope
+	used := narrowed != nil && analyzer.scope.Env.GetSymbol(narrowed.Name).IsUsed
+	analyzer.scope = analyzer.scope.Parent // Leave the conditional scope
+	return used
+}
+
 func (analyzer *TAnalyzer) visitRunStmnt(node *TAst) {
 	exprNode := node.Ast0
 	if exprNode.Ttype != AstCall {
 		RaiseLanguageCompileError(
 			analyzer.file.Path,
 			analyzer.file.Data,
+			CODE_CONCURRENCY,
 			"invalid run statement, run statement must be a function call",
 			node.Position,
 		)
 	}
-	analyzer.write("go ", false)
-	analyzer.expression(exprNode)
-	analyzer.stack.Pop()
+	parallel := analyzer.scope.Parallel()
+	if parallel == nil {
+		analyzer.write("go ", false)
+		analyzer.expression(exprNode)
+		analyzer.stack.Pop()
+		return
+	}
+	// Tasks of a parallel block join the group the block waits for
+	bindings, call, funcType := analyzer.runCall(node)
+	analyzer.checkPanics(funcType, exprNode)
+	bindings = append(bindings, fmt.Sprintf("%s.Run(func() { %s })", parallel.Group, call))
+	analyzer.write(fmt.Sprintf("{ %s }", strings.Join(bindings, "; ")), false)
+}
+
+// Emits a parallel block, which waits for every task started in it
+// and then re-raises the first panic one of them ended with.
+func (analyzer *TAnalyzer) visitParallel(node *TAst) {
+	if !analyzer.scope.InFunction() {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_NOT_ALLOWED_HERE,
+			"parallel block is not allowed here",
+			node.Position,
+		)
+	}
+	analyzer.state.TaskGroup = true
+	analyzer.scope = CreateScope(analyzer.scope, ScopeParallel)
+	analyzer.scope.Group = analyzer.tempName("group")
+	analyzer.write("{", true)
+	analyzer.incTb()
+	analyzer.srcTb()
+	analyzer.write(fmt.Sprintf("%s := new(TaskGroup)", analyzer.scope.Group), true)
+	analyzer.srcTb()
+	analyzer.statement(node.Ast0)
+	analyzer.srcNl()
+	analyzer.srcTb()
+	analyzer.write(fmt.Sprintf("%s.Wait()", analyzer.scope.Group), false)
+	analyzer.decTb()
+	analyzer.srcNl()
+	analyzer.srcTb()
+	analyzer.write("}", false)
+	analyzer.scope = analyzer.scope.Parent
 }
 
 func (analyzer *TAnalyzer) visitBreak(node *TAst) {
+	if analyzer.scope.InCase() {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_SWITCH,
+			"break statement is not allowed in a switch or select case, cases do not fall through",
+			node.Position,
+		)
+	}
 	if !analyzer.scope.InLoop() {
 		RaiseLanguageCompileError(
 			analyzer.file.Path,
 			analyzer.file.Data,
+			CODE_NOT_ALLOWED_HERE,
 			"break statement is not allowed here",
 			node.Position,
 		)
@@ -3157,10 +5394,20 @@ func (analyzer *TAnalyzer) visitReturn(node *TAst) {
 		RaiseLanguageCompileError(
 			analyzer.file.Path,
 			analyzer.file.Data,
+			CODE_NOT_ALLOWED_HERE,
 			"return statement is not allowed here",
 			node.Position,
 		)
 	}
+	if analyzer.scope.Parallel() != nil {
+		RaiseLanguageCompileError(
+			analyzer.file.Path,
+			analyzer.file.Data,
+			CODE_CONCURRENCY,
+			"return statement is not allowed in a parallel block, the block must wait for its tasks",
+			node.Position,
+		)
+	}
 	// Capture the function
 	currentScope := analyzer.scope
 	for currentScope.Type != ScopeFunction {
@@ -3171,6 +5418,7 @@ func (analyzer *TAnalyzer) visitReturn(node *TAst) {
 		RaiseLanguageCompileError(
 			analyzer.file.Path,
 			analyzer.file.Data,
+			CODE_UNREACHABLE,
 			"unreachable return statement",
 			node.Position,
 		)
@@ -3180,13 +5428,11 @@ func (analyzer *TAnalyzer) visitReturn(node *TAst) {
 	analyzer.write("return", false)
 	if exprNode != nil {
 		analyzer.srcSp()
-		analyzer.expression(exprNode)
+		value := analyzer.storeExpression(exprNode, currentScope.ReturnType)
 		if !analyzer.scope.InConditional() {
 			// If inside a conditional scope,
 			// it's possible that there's no reachable return statement
-			currentScope.Return = analyzer.stack.Pop().DataType
-		} else {
-			analyzer.stack.Pop()
+			currentScope.Return = value.DataType
 		}
 	} else {
 		if !analyzer.scope.InConditional() {
@@ -3205,10 +5451,9 @@ func (analyzer *TAnalyzer) program(node *TAst) {
 	defer func() {
 		src := analyzer.src
 		analyzer.src = ""
-		analyzer.write("package main", true)
+		analyzer.write("package "+analyzer.state.PackageName(), true)
 		// Collect required modules
-		analyzer.addModule("\"fmt\"")
-		if analyzer.file.IsMain {
+		if analyzer.file.IsMain && !analyzer.state.IsLibrary() {
 			analyzer.addModule("\"os\"")
 		}
 		// Use a map to track unique modules for better performance
@@ -3224,7 +5469,7 @@ func (analyzer *TAnalyzer) program(node *TAst) {
 		if len(analyzer.modules) > 0 {
 			analyzer.write(fmt.Sprintf("import (%s)", strings.Join(analyzer.modules, "\n")), true)
 		}
-		analyzer.src = analyzer.src + src
+		analyzer.src = analyzer.src + src + analyzer.bridges
 	}()
 
 	// Set the parent of the file's environment to the built-in environment.
@@ -3241,6 +5486,16 @@ func (analyzer *TAnalyzer) program(node *TAst) {
 	// Set the scope to the global scope.
 	analyzer.scope = globalScope
 
+	// Global constants may be used before they are declared
+	for _, child := range node.AstArr0 {
+		if child.Ttype != AstConst {
+			continue
+		}
+		for _, nameNode := range child.AstArr0 {
+			analyzer.constants[nameNode.Str0] = child
+		}
+	}
+
 	// Analyze the program.
 	lastIdx := len(node.AstArr0) - 1
 	for index, child := range node.AstArr0 {
@@ -3254,16 +5509,12 @@ func (analyzer *TAnalyzer) program(node *TAst) {
 	env := globalScope.Env
 	for _, symbol := range env.Symbols {
 		if !symbol.IsUsed && !symbol.IsGlobal {
-			RaiseLanguageCompileError(
-				analyzer.file.Path,
-				analyzer.file.Data,
-				fmt.Sprintf("unused variable: %s", symbol.Name),
-				symbol.Position,
-			)
+			analyzer.unusedVariable(symbol)
 		}
 	}
 
-	if !analyzer.file.IsMain {
+	// A library is imported by Go code, it has no main function to call
+	if !analyzer.file.IsMain || analyzer.state.IsLibrary() {
 		return
 	}
 
@@ -3272,6 +5523,7 @@ func (analyzer *TAnalyzer) program(node *TAst) {
 		RaiseLanguageCompileError(
 			analyzer.file.Path,
 			analyzer.file.Data,
+			CODE_MAIN_FUNCTION,
 			"main function is not defined",
 			node.Position,
 		)
@@ -3282,6 +5534,7 @@ func (analyzer *TAnalyzer) program(node *TAst) {
 		RaiseLanguageCompileError(
 			analyzer.file.Path,
 			analyzer.file.Data,
+			CODE_MAIN_FUNCTION,
 			"main function is not a function",
 			node.Position,
 		)
@@ -3292,6 +5545,7 @@ func (analyzer *TAnalyzer) program(node *TAst) {
 		RaiseLanguageCompileError(
 			analyzer.file.Path,
 			analyzer.file.Data,
+			CODE_MAIN_FUNCTION,
 			"main function must have exactly one parameter",
 			node.Position,
 		)
@@ -3302,6 +5556,7 @@ func (analyzer *TAnalyzer) program(node *TAst) {
 		RaiseLanguageCompileError(
 			analyzer.file.Path,
 			analyzer.file.Data,
+			CODE_MAIN_FUNCTION,
 			"main function parameter must be an array",
 			node.Position,
 		)
@@ -3311,6 +5566,7 @@ func (analyzer *TAnalyzer) program(node *TAst) {
 		RaiseLanguageCompileError(
 			analyzer.file.Path,
 			analyzer.file.Data,
+			CODE_MAIN_FUNCTION,
 			"main function parameter must be an array of strings",
 			node.Position,
 		)
@@ -3332,7 +5588,10 @@ func (analyzer *TAnalyzer) program(node *TAst) {
 
 // API:Export
 func (analyzer *TAnalyzer) Analyze() string {
-	analyzer.program(analyzer.file.Ast)
+	// The errors of the main function are the only ones out of a statement
+	if !Recovering(func() { analyzer.program(analyzer.file.Ast) }) {
+		return ""
+	}
 	if analyzer.stack.Size() != 0 {
 		repr := ""
 		for _, value := range analyzer.stack.stack {
@@ -3341,9 +5600,13 @@ func (analyzer *TAnalyzer) Analyze() string {
 		RaiseLanguageCompileError(
 			analyzer.file.Path,
 			analyzer.file.Data,
+			CODE_INTERNAL,
 			fmt.Sprintf("evaluation stack is not empty (%s)", repr),
 			analyzer.file.Ast.Position,
 		)
 	}
+	for marker, discard := range analyzer.discards {
+		analyzer.src = strings.ReplaceAll(analyzer.src, marker, discard)
+	}
 	return analyzer.src
 }
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// Analyzes source as the main file of a program, keeping the
+// diagnostics instead of exiting on them, as the language server does.
+func analyzeSource(t *testing.T, source string) []TDiagnostic {
+	keep := Diagnostics.Keep
+	Diagnostics.Keep = true
+	Diagnostics.Reset()
+	t.Cleanup(func() {
+		Diagnostics.Keep = keep
+		Diagnostics.Reset()
+	})
+	state := CreateState()
+	Recovering(func() {
+		parser := CreateParser("main.ns", source)
+		program := parser.Parse()
+		if Diagnostics.HasErrors() {
+			return
+		}
+		files := ForwardDeclairation(state, "main.ns", parser.Tokenizer.Data, program)
+		state.SetFile(files)
+		if Diagnostics.HasErrors() {
+			return
+		}
+		for _, file := range files {
+			CreateAnalyzer(state, file).Analyze()
+		}
+	})
+	return append([]TDiagnostic{}, Diagnostics.Items...)
+}
+
+// Prints the diagnostics collected so far and returns what is printed.
+func printDiagnostics(t *testing.T) string {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = writer
+	Diagnostics.Print()
+	os.Stderr = stderr
+	writer.Close()
+	output, _ := io.ReadAll(reader)
+	return string(output)
+}
+
+// Returns the "line:column: severity[code]" of each diagnostic printed.
+func printedHeaders(output string) []string {
+	headers := make([]string, 0)
+	for _, line := range strings.Split(output, "\n") {
+		if !strings.HasPrefix(line, "main.ns:") && !strings.Contains(line, "/main.ns:") {
+			continue
+		}
+		header := line[strings.Index(line, "main.ns:")+len("main.ns:"):]
+		headers = append(headers, header[:strings.Index(header, "]")+1])
+	}
+	return headers
+}
+
+// A statement with an error is abandoned, the statements after it are
+// still analyzed and report their own errors.
+func TestAnalyzerReportsEveryStatement(t *testing.T) {
+	items := analyzeSource(t, strings.Join([]string{
+		"function main(args [str]) i32 {",
+		"    local a i32 = \"a\";",
+		"    local b bool = 1;",
+		"    local c str = true;",
+		"    println(a, b, c);",
+		"    return 0;",
+		"}",
+	}, "\n"))
+	lines := make([]int, 0)
+	for _, item := range items {
+		if item.Severity != SEVERITY_ERROR || item.Code != CODE_TYPE_MISMATCH {
+			t.Errorf("unexpected %s[%s] at line %d: %s", item.Severity, item.Code, item.Position.SLine, item.Message)
+			continue
+		}
+		lines = append(lines, item.Position.SLine)
+	}
+	if fmt.Sprint(lines) != "[2 3 4]" {
+		t.Errorf("errors reported at lines %v, expected [2 3 4]", lines)
+	}
+}
+
+// A local whose declaration failed reports nothing where it is used, and
+// the other operands of that statement are not reported unused.
+func TestAnalyzerInvalidSymbolIsQuiet(t *testing.T) {
+	items := analyzeSource(t, strings.Join([]string{
+		"function main(args [str]) i32 {",
+		"    local b i32 = \"x\";",
+		"    local q i32 = 3;",
+		"    println(b, q);",
+		"    local r i32 = b + 1;",
+		"    return 0;",
+		"}",
+	}, "\n"))
+	if len(items) != 1 || items[0].Position.SLine != 2 {
+		for _, item := range items {
+			t.Logf("%d:%d: %s[%s] %s", item.Position.SLine, item.Position.SColm, item.Severity, item.Code, item.Message)
+		}
+		t.Fatalf("expected the error of line 2 only, got %d diagnostics", len(items))
+	}
+}
+
+// Warnings are reported when a function ends, after the errors of its
+// body, and are printed with the errors in the order of the source.
+func TestDiagnosticsPrintInSourceOrder(t *testing.T) {
+	items := analyzeSource(t, strings.Join([]string{
+		"function main(args [str]) i32 {",
+		"    local unused i32 = 1;",
+		"    local a i32 = \"a\";",
+		"    local alsoUnused i32 = 2;",
+		"    local b bool = 1;",
+		"    return 0;",
+		"}",
+	}, "\n"))
+	reported := make([]string, 0)
+	for _, item := range items {
+		reported = append(reported, fmt.Sprintf("%d:%s", item.Position.SLine, item.Severity))
+	}
+	if strings.Join(reported, " ") != "3:error 5:error 2:warning 4:warning" {
+		t.Errorf("reported %v", reported)
+	}
+	Diagnostics.Items = items
+	output := printDiagnostics(t)
+	expected := []string{
+		"2:11: warning[NS0017]",
+		"3:11: error[NS0012]",
+		"4:11: warning[NS0017]",
+		"5:11: error[NS0012]",
+	}
+	if headers := printedHeaders(output); strings.Join(headers, " ") != strings.Join(expected, " ") {
+		t.Errorf("printed %v, expected %v", headers, expected)
+	}
+	if !strings.HasSuffix(output, "2 errors, 2 warnings\n") {
+		t.Errorf("printed summary %q", output[strings.LastIndex(strings.TrimSuffix(output, "\n"), "\n")+1:])
+	}
+}
+
+// Runs the analysis of the script in DIAGNOSTICS_TEST_SOURCE with the
+// error limit in DIAGNOSTICS_TEST_LIMIT, in the process the limit test
+// starts. The collector exits once the limit is reached.
+func TestDiagnosticsLimitCommand(t *testing.T) {
+	source := os.Getenv("DIAGNOSTICS_TEST_SOURCE")
+	if source == "" {
+		t.Skip("only run by TestDiagnosticsLimit")
+	}
+	fmt.Sscan(os.Getenv("DIAGNOSTICS_TEST_LIMIT"), &Diagnostics.Limit)
+	state := CreateState()
+	parser := CreateParser("main.ns", source)
+	program := parser.Parse()
+	Diagnostics.Check()
+	files := ForwardDeclairation(state, "main.ns", parser.Tokenizer.Data, program)
+	state.SetFile(files)
+	Diagnostics.Check()
+	for _, file := range files {
+		CreateAnalyzer(state, file).Analyze()
+	}
+	Diagnostics.Check()
+	os.Exit(0)
+}
+
+func TestDiagnosticsLimit(t *testing.T) {
+	lines := []string{"function main(args [str]) i32 {"}
+	for i := 0; i < 6; i++ {
+		lines = append(lines, fmt.Sprintf("    local a%d i32 = \"a\";", i))
+	}
+	lines = append(lines, "    return 0;", "}")
+	command := exec.Command(os.Args[0], "-test.run=^TestDiagnosticsLimitCommand$")
+	command.Env = append(os.Environ(), "DIAGNOSTICS_TEST_SOURCE="+strings.Join(lines, "\n"), "DIAGNOSTICS_TEST_LIMIT=3")
+	output, err := command.CombinedOutput()
+	exit, ok := err.(*exec.ExitError)
+	if !ok || exit.ExitCode() != 1 {
+		t.Fatalf("expected exit code 1, got %v:\n%s", err, output)
+	}
+	expected := []string{
+		"2:11: error[NS0012]",
+		"3:11: error[NS0012]",
+		"4:11: error[NS0012]",
+	}
+	if headers := printedHeaders(string(output)); strings.Join(headers, " ") != strings.Join(expected, " ") {
+		t.Errorf("printed %v, expected %v", headers, expected)
+	}
+	if !strings.Contains(string(output), "too many errors, stopped after 3\n") {
+		t.Errorf("no limit message in:\n%s", output)
+	}
+}
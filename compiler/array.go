@@ -2,6 +2,7 @@ package main
 
 import (
 	"dev/types"
+	"fmt"
 	"strings"
 )
 
@@ -25,13 +26,19 @@ func NewArray{{TypeName}}(elements []{{GoType}}) *Array{{TypeName}} {
 	}
 	return lst
 }
-func (lst *Array{{TypeName}}) Length() int {
-	return lst.length
+func WrapArray{{TypeName}}(elements []{{GoType}}) *Array{{TypeName}} {
+	lst := new(Array{{TypeName}})
+	lst.elements = elements
+	lst.length = len(elements)
+	return lst
 }
-func (lst *Array{{TypeName}}) Get(index int) {{TypeName}} {
+func (lst *Array{{TypeName}}) Length() int64 {
+	return int64(lst.length)
+}
+func (lst *Array{{TypeName}}) Get(index int64) {{TypeName}} {
 	return lst.elements[index]
 }
-func (lst *Array{{TypeName}}) Set(index int, value {{TypeName}}) {
+func (lst *Array{{TypeName}}) Set(index int64, value {{TypeName}}) {
 	lst.elements[index] = value
 }
 func (lst *Array{{TypeName}}) Push(value {{TypeName}}) {
@@ -44,14 +51,14 @@ func (lst *Array{{TypeName}}) Pop() {{TypeName}} {
 	lst.length--
 	return last
 }
-func (lst *Array{{TypeName}}) Each(callback func(index int, value {{TypeName}})) {
+func (lst *Array{{TypeName}}) Each(callback func(index int64, value {{TypeName}})) {
 	for i := 0; i < len(lst.elements); i++ {
-		callback(i, lst.elements[i])
+		callback(int64(i), lst.elements[i])
 	}
 }
-func (lst *Array{{TypeName}}) Some(callback func(index int, value {{TypeName}}) bool) bool {
+func (lst *Array{{TypeName}}) Some(callback func(index int64, value {{TypeName}}) bool) bool {
 	for i := 0; i < len(lst.elements); i++ {
-		if callback(i, lst.elements[i]) {
+		if callback(int64(i), lst.elements[i]) {
 			return true
 		}
 	}
@@ -99,6 +106,28 @@ func GetArrayConstructor(t *types.TTyping) string {
 	return code
 }
 
+// Wraps a Go slice without copying it, the array shares its elements.
+func GetArrayWrapper(t *types.TTyping) string {
+	return "WrapArray" + t.ToNormalName()
+}
+
+// Conversions between str and [i8], the byte array of Parrot Script.
+func GetStrToBytesCode(src string) string {
+	header := GetArrayHeader(types.TInt08())
+	return fmt.Sprintf(
+		"func(s string) *%s { elements := make([]int8, len(s)); for i := 0; i < len(s); i++ { elements[i] = int8(s[i]) }; return New%s(elements) }(%s)",
+		header, header, src,
+	)
+}
+
+func GetBytesToStrCode(src string) string {
+	header := GetArrayHeader(types.TInt08())
+	return fmt.Sprintf(
+		"func(a *%s) string { b := make([]byte, a.Length()); for i := range b { b[i] = byte(a.Get(int64(i))) }; return string(b) }(%s)",
+		header, src,
+	)
+}
+
 func GenerateArrayCode(t *types.TTyping) string {
 	code := ArrayCode
 	code = strings.ReplaceAll(code, "{{TypeName}}", t.ToNormalName())
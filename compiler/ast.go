@@ -11,6 +11,7 @@ const (
 	AstNull            AstType = iota
 	AstArray           AstType = iota
 	AstHashMap         AstType = iota
+	AstChan            AstType = iota
 	AstMember          AstType = iota
 	AstNullSafeMember  AstType = iota
 	AstIndex           AstType = iota
@@ -22,6 +23,11 @@ const (
 	AstPlus2           AstType = iota
 	AstMinus2          AstType = iota
 	AstAllocation      AstType = iota
+	AstReceive         AstType = iota
+	AstAwait           AstType = iota
+	AstRun             AstType = iota
+	AstCast            AstType = iota
+	AstIs              AstType = iota
 	AstMul             AstType = iota
 	AstDiv             AstType = iota
 	AstMod             AstType = iota
@@ -52,6 +58,7 @@ const (
 	AstAndAssign       AstType = iota
 	AstOrAssign        AstType = iota
 	AstXorAssign       AstType = iota
+	AstSend            AstType = iota
 	AstTupleExpression AstType = iota
 	AstTypePointer     AstType = iota
 	AstTypeInt8        AstType = iota // Typing
@@ -63,6 +70,9 @@ const (
 	AstTypeBool        AstType = iota // Typing
 	AstTypeVoid        AstType = iota // Typing
 	AstTypeError       AstType = iota // Typing
+	AstTypeAny         AstType = iota // Typing
+	AstTypeChan        AstType = iota // Typing
+	AstTypeFuture      AstType = iota // Typing
 	AstTypeFunc        AstType = iota // Typing
 	AstTypeTuple       AstType = iota // Typing
 	AstTypeHashMap     AstType = iota // Typing
@@ -79,6 +89,10 @@ const (
 	AstFor             AstType = iota
 	AstForIf           AstType = iota
 	AstIf              AstType = iota
+	AstSwitch          AstType = iota
+	AstCase            AstType = iota
+	AstSelect          AstType = iota
+	AstParallel        AstType = iota
 	AstRunStmnt        AstType = iota
 	AstContinueStmnt   AstType = iota
 	AstBreakStmnt      AstType = iota
@@ -93,7 +107,9 @@ type TAst struct {
 	Ttype    AstType
 	Position TPosition
 	Str0     string
+	Str1     string // Documentation of an exported declaration
 	Flg0     bool
+	Flg1     bool // Whether a declaration is exported
 	Ast0     *TAst
 	Ast1     *TAst
 	Ast2     *TAst
@@ -306,6 +322,8 @@ func GetAstTypeByUnaryOp(opt string) AstType {
 		return AstNot
 	case "~":
 		return AstBitNot
+	case "<-":
+		return AstReceive
 	default:
 		RaiseSystemError("invalid or not implemented unary operator!")
 	}
@@ -0,0 +1,395 @@
+package main
+
+import (
+	"dev/types"
+	"errors"
+	"fmt"
+	goconstant "go/constant"
+	gotypes "go/types"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Compile-time constants are carried in TValue.Data as:
+//   - int8, int16, int32 or int64 for integers
+//   - float64 for numbers
+//   - string for strings (kept as written in source, escapes included)
+//   - bool for booleans
+// A nil Data means the value is only known at runtime.
+
+var (
+	ErrDivisionByZero   = errors.New("division by zero")
+	ErrConstantOverflow = errors.New("constant overflow")
+)
+
+func ConstantInt(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	}
+	return 0, false
+}
+
+func ConstantNum(value interface{}) (float64, bool) {
+	if v, ok := value.(float64); ok {
+		return v, true
+	}
+	if v, ok := ConstantInt(value); ok {
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func IsZeroConstant(value interface{}) bool {
+	if v, ok := ConstantInt(value); ok {
+		return v == 0
+	}
+	if v, ok := value.(float64); ok {
+		return v == 0
+	}
+	return false
+}
+
+// Converts a constant to the representation of the type it is stored in,
+// so that "const x num = 1" folds as a number and not as an integer.
+func CoerceConstant(value interface{}, dataType *types.TTyping) interface{} {
+	if value == nil {
+		return nil
+	}
+	if types.IsNum(dataType) {
+		if v, ok := ConstantNum(value); ok {
+			return v
+		}
+	}
+	if types.IsAnyInt(dataType) {
+		if v, ok := ConstantInt(value); ok {
+			return v
+		}
+	}
+	return value
+}
+
+// Reports whether a Go constant is untyped, such as math.MaxInt8, it
+// takes the type of where it is used.
+func IsUntypedConstant(object *gotypes.Const) bool {
+	basic, ok := object.Type().(*gotypes.Basic)
+	return ok && basic.Info()&gotypes.IsUntyped != 0
+}
+
+// Converts the value of a constant imported from Go. An untyped constant,
+// such as math.Pi, is typed like a literal of its value, while a typed one
+// keeps its type. The value is nil for kinds the script has no constant of,
+// and for constants of named types such as time.Second: a folded value
+// would lose the type, and with it the methods of the type.
+func GoConstant(state *TState, object *gotypes.Const) (interface{}, *types.TTyping, error) {
+	dataType := state.GoTypes.FromObject(object)
+	if _, ok := object.Type().(*gotypes.Named); ok {
+		return nil, dataType, nil
+	}
+	untyped := IsUntypedConstant(object)
+	value := object.Val()
+	switch value.Kind() {
+	case goconstant.Int:
+		v, exact := goconstant.Int64Val(value)
+		if !exact {
+			return nil, state.TI64, ErrConstantOverflow
+		}
+		if !untyped {
+			return CoerceConstant(v, dataType), dataType, nil
+		}
+		switch SizeOfInt(v) {
+		case 8:
+			return int8(v), state.TI08, nil
+		case 16:
+			return int16(v), state.TI16, nil
+		case 32:
+			return int32(v), state.TI32, nil
+		default:
+			return v, state.TI64, nil
+		}
+	case goconstant.Float:
+		v, _ := goconstant.Float64Val(value)
+		if untyped {
+			dataType = state.TNum
+		}
+		return v, dataType, nil
+	case goconstant.String:
+		// Kept as a literal would be written, escapes included
+		literal := strconv.Quote(goconstant.StringVal(value))
+		if untyped {
+			dataType = state.TStr
+		}
+		return literal[1 : len(literal)-1], dataType, nil
+	case goconstant.Bool:
+		if untyped {
+			dataType = state.TBit
+		}
+		return goconstant.BoolVal(value), dataType, nil
+	}
+	return nil, dataType, nil
+}
+
+// Converts a numeric constant the way Go converts the value at runtime:
+// numbers truncate toward zero and integers wrap to the target width.
+func CastConstant(value interface{}, dataType *types.TTyping) interface{} {
+	if types.IsNum(dataType) {
+		if v, ok := ConstantNum(value); ok {
+			return v
+		}
+		return nil
+	}
+	v, ok := ConstantInt(value)
+	if !ok {
+		f, isNum := value.(float64)
+		if !isNum || f < math.MinInt64 || f >= math.MaxInt64 {
+			return nil
+		}
+		v = int64(f)
+	}
+	switch {
+	case types.IsInt08(dataType):
+		return int8(v)
+	case types.IsInt16(dataType):
+		return int16(v)
+	case types.IsInt32(dataType):
+		return int32(v)
+	case types.IsInt64(dataType):
+		return v
+	}
+	return nil
+}
+
+// Reports whether an integer constant fits in the given integer type.
+func ConstantFits(value interface{}, dataType *types.TTyping) bool {
+	v, ok := ConstantInt(value)
+	if !ok {
+		return true
+	}
+	switch {
+	case types.IsInt08(dataType):
+		return v >= math.MinInt8 && v <= math.MaxInt8
+	case types.IsInt16(dataType):
+		return v >= math.MinInt16 && v <= math.MaxInt16
+	case types.IsInt32(dataType):
+		return v >= math.MinInt32 && v <= math.MaxInt32
+	}
+	return true
+}
+
+// Renders a constant as a Go literal.
+func ConstantToGo(value interface{}) string {
+	if v, ok := ConstantInt(value); ok {
+		return strconv.FormatInt(v, 10)
+	}
+	switch v := value.(type) {
+	case float64:
+		literal := strconv.FormatFloat(v, 'g', -1, 64)
+		if !strings.ContainsAny(literal, ".e") {
+			literal += ".0"
+		}
+		return literal
+	case string:
+		return fmt.Sprintf("\"%s\"", v)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	RaiseSystemError("invalid constant value")
+	return ""
+}
+
+// Evaluates a binary operator over two constants.
+// Returns nil without an error if either operand is not constant.
+func FoldBinary(opt string, lhs interface{}, rhs interface{}) (interface{}, error) {
+	if lhs == nil || rhs == nil {
+		return nil, nil
+	}
+	if (opt == "/" || opt == "%") && IsZeroConstant(rhs) {
+		return nil, ErrDivisionByZero
+	}
+	l, lok := ConstantInt(lhs)
+	r, rok := ConstantInt(rhs)
+	if lok && rok {
+		return foldInt(opt, l, r)
+	}
+	if lf, ok := ConstantNum(lhs); ok {
+		if rf, ok := ConstantNum(rhs); ok {
+			return foldNum(opt, lf, rf)
+		}
+	}
+	if ls, ok := lhs.(string); ok {
+		if rs, ok := rhs.(string); ok {
+			return foldStr(opt, ls, rs), nil
+		}
+	}
+	if lb, ok := lhs.(bool); ok {
+		if rb, ok := rhs.(bool); ok {
+			return foldBool(opt, lb, rb), nil
+		}
+	}
+	return nil, nil
+}
+
+// Evaluates a unary operator over a constant.
+// Returns nil without an error if the operand is not constant.
+func FoldUnary(opt string, operand interface{}) (interface{}, error) {
+	if v, ok := ConstantInt(operand); ok {
+		switch opt {
+		case "+":
+			return v, nil
+		case "-":
+			if v == math.MinInt64 {
+				return nil, ErrConstantOverflow
+			}
+			return -v, nil
+		case "^":
+			return ^v, nil
+		}
+	}
+	if v, ok := operand.(float64); ok {
+		switch opt {
+		case "+":
+			return v, nil
+		case "-":
+			return -v, nil
+		}
+	}
+	if v, ok := operand.(bool); ok && opt == "!" {
+		return !v, nil
+	}
+	return nil, nil
+}
+
+func foldInt(opt string, l int64, r int64) (interface{}, error) {
+	bl := big.NewInt(l)
+	br := big.NewInt(r)
+	result := new(big.Int)
+	switch opt {
+	case "+":
+		result.Add(bl, br)
+	case "-":
+		result.Sub(bl, br)
+	case "*":
+		result.Mul(bl, br)
+	case "/":
+		// Quo and Rem truncate toward zero, like Go
+		result.Quo(bl, br)
+	case "%":
+		result.Rem(bl, br)
+	case "<<":
+		if r < 0 || r >= 64 {
+			return nil, ErrConstantOverflow
+		}
+		result.Lsh(bl, uint(r))
+	case ">>":
+		if r < 0 {
+			return nil, ErrConstantOverflow
+		}
+		if r >= 64 {
+			r = 63
+		}
+		result.Rsh(bl, uint(r))
+	case "&":
+		result.And(bl, br)
+	case "|":
+		result.Or(bl, br)
+	case "^":
+		result.Xor(bl, br)
+	case "<":
+		return l < r, nil
+	case "<=":
+		return l <= r, nil
+	case ">":
+		return l > r, nil
+	case ">=":
+		return l >= r, nil
+	case "==":
+		return l == r, nil
+	case "!=":
+		return l != r, nil
+	default:
+		return nil, nil
+	}
+	if !result.IsInt64() {
+		return nil, ErrConstantOverflow
+	}
+	return result.Int64(), nil
+}
+
+func foldNum(opt string, l float64, r float64) (interface{}, error) {
+	var result float64
+	switch opt {
+	case "+":
+		result = l + r
+	case "-":
+		result = l - r
+	case "*":
+		result = l * r
+	case "/":
+		result = l / r
+	case "<":
+		return l < r, nil
+	case "<=":
+		return l <= r, nil
+	case ">":
+		return l > r, nil
+	case ">=":
+		return l >= r, nil
+	case "==":
+		return l == r, nil
+	case "!=":
+		return l != r, nil
+	default:
+		return nil, nil
+	}
+	if math.IsInf(result, 0) || math.IsNaN(result) {
+		return nil, ErrConstantOverflow
+	}
+	return result, nil
+}
+
+func foldStr(opt string, l string, r string) interface{} {
+	if opt == "+" {
+		return l + r
+	}
+	// Escapes are not decoded, so only plain strings compare reliably
+	if strings.Contains(l, "\\") || strings.Contains(r, "\\") {
+		return nil
+	}
+	switch opt {
+	case "<":
+		return l < r
+	case "<=":
+		return l <= r
+	case ">":
+		return l > r
+	case ">=":
+		return l >= r
+	case "==":
+		return l == r
+	case "!=":
+		return l != r
+	}
+	return nil
+}
+
+func foldBool(opt string, l bool, r bool) interface{} {
+	switch opt {
+	case "==":
+		return l == r
+	case "!=":
+		return l != r
+	case "&&":
+		return l && r
+	case "||":
+		return l || r
+	}
+	return nil
+}
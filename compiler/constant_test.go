@@ -0,0 +1,168 @@
+package main
+
+import (
+	"dev/types"
+	"fmt"
+	"math"
+	"testing"
+)
+
+// Formats a folded constant with its Go type, so that int8(1) and
+// int64(1) are told apart.
+func constantString(value interface{}) string {
+	if value == nil {
+		return "nil"
+	}
+	return fmt.Sprintf("%T(%v)", value, value)
+}
+
+func TestFoldBinary(t *testing.T) {
+	tests := []struct {
+		opt      string
+		lhs      interface{}
+		rhs      interface{}
+		expected string
+		err      error
+	}{
+		{"+", int64(1), int64(2), "int64(3)", nil},
+		{"+", int8(100), int8(100), "int64(200)", nil},
+		{"-", int32(-7), int64(3), "int64(-10)", nil},
+		{"*", int64(math.MaxInt64), int64(2), "nil", ErrConstantOverflow},
+		{"/", int64(-7), int64(2), "int64(-3)", nil},
+		{"%", int64(-7), int64(2), "int64(-1)", nil},
+		{"/", int64(1), int64(0), "nil", ErrDivisionByZero},
+		{"%", int64(1), int8(0), "nil", ErrDivisionByZero},
+		{"/", 1.0, 0.0, "nil", ErrDivisionByZero},
+		{"<<", int64(1), int64(62), "int64(4611686018427387904)", nil},
+		{"<<", int64(1), int64(64), "nil", ErrConstantOverflow},
+		{"<<", int64(1), int64(-1), "nil", ErrConstantOverflow},
+		{">>", int64(-8), int64(100), "int64(-1)", nil},
+		{"&", int64(6), int64(3), "int64(2)", nil},
+		{"|", int64(6), int64(3), "int64(7)", nil},
+		{"^", int64(6), int64(3), "int64(5)", nil},
+		{"<", int64(1), int64(2), "bool(true)", nil},
+		{"==", int16(3), int64(3), "bool(true)", nil},
+		{"+", 1.5, int64(2), "float64(3.5)", nil},
+		{"/", 1.0, 4.0, "float64(0.25)", nil},
+		{"*", math.MaxFloat64, 2.0, "nil", ErrConstantOverflow},
+		{"%", 1.5, 1.0, "nil", nil},
+		{">=", 2.0, 2.0, "bool(true)", nil},
+		{"+", "ab", "cd", "string(abcd)", nil},
+		{"<", "ab", "b", "bool(true)", nil},
+		{"==", "a\\n", "a\\n", "nil", nil},
+		{"+", "a\\n", "b", "string(a\\nb)", nil},
+		{"&&", true, false, "bool(false)", nil},
+		{"||", true, false, "bool(true)", nil},
+		{"!=", true, false, "bool(true)", nil},
+		{"+", "a", int64(1), "nil", nil},
+		{"+", nil, int64(1), "nil", nil},
+		{"+", int64(1), nil, "nil", nil},
+	}
+	for _, test := range tests {
+		name := fmt.Sprintf("%s %s %s", constantString(test.lhs), test.opt, constantString(test.rhs))
+		t.Run(name, func(t *testing.T) {
+			result, err := FoldBinary(test.opt, test.lhs, test.rhs)
+			if err != test.err {
+				t.Fatalf("error %v, expected %v", err, test.err)
+			}
+			if actual := constantString(result); actual != test.expected {
+				t.Errorf("folded to %s, expected %s", actual, test.expected)
+			}
+		})
+	}
+}
+
+func TestFoldUnary(t *testing.T) {
+	tests := []struct {
+		opt      string
+		operand  interface{}
+		expected string
+		err      error
+	}{
+		{"+", int8(5), "int64(5)", nil},
+		{"-", int32(5), "int64(-5)", nil},
+		{"-", int64(math.MinInt64), "nil", ErrConstantOverflow},
+		{"^", int64(0), "int64(-1)", nil},
+		{"+", 1.5, "float64(1.5)", nil},
+		{"-", 1.5, "float64(-1.5)", nil},
+		{"^", 1.5, "nil", nil},
+		{"!", true, "bool(false)", nil},
+		{"-", true, "nil", nil},
+		{"-", "a", "nil", nil},
+		{"-", nil, "nil", nil},
+	}
+	for _, test := range tests {
+		name := fmt.Sprintf("%s%s", test.opt, constantString(test.operand))
+		t.Run(name, func(t *testing.T) {
+			result, err := FoldUnary(test.opt, test.operand)
+			if err != test.err {
+				t.Fatalf("error %v, expected %v", err, test.err)
+			}
+			if actual := constantString(result); actual != test.expected {
+				t.Errorf("folded to %s, expected %s", actual, test.expected)
+			}
+		})
+	}
+}
+
+func TestConstantFits(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		dataType *types.TTyping
+		expected bool
+	}{
+		{int64(127), types.TInt08(), true},
+		{int64(128), types.TInt08(), false},
+		{int64(-128), types.TInt08(), true},
+		{int64(-129), types.TInt08(), false},
+		{int64(32767), types.TInt16(), true},
+		{int64(32768), types.TInt16(), false},
+		{int64(math.MaxInt32), types.TInt32(), true},
+		{int64(math.MinInt32 - 1), types.TInt32(), false},
+		{int64(math.MaxInt64), types.TInt64(), true},
+		{int64(1000), types.TNum(), true},
+		// Only integers are checked, other constants are converted elsewhere
+		{1e10, types.TInt08(), true},
+		{"a", types.TInt08(), true},
+	}
+	for _, test := range tests {
+		name := fmt.Sprintf("%s in %s", constantString(test.value), test.dataType.ToString())
+		t.Run(name, func(t *testing.T) {
+			if actual := ConstantFits(test.value, test.dataType); actual != test.expected {
+				t.Errorf("fits %v, expected %v", actual, test.expected)
+			}
+		})
+	}
+}
+
+func TestCastConstant(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		dataType *types.TTyping
+		expected string
+	}{
+		{int64(200), types.TInt08(), "int8(-56)"},
+		{int64(-1), types.TInt16(), "int16(-1)"},
+		{int64(65536 + 5), types.TInt16(), "int16(5)"},
+		{int64(math.MaxInt32 + 1), types.TInt32(), "int32(-2147483648)"},
+		{int8(-3), types.TInt64(), "int64(-3)"},
+		{int32(3), types.TNum(), "float64(3)"},
+		{2.9, types.TInt32(), "int32(2)"},
+		{-2.9, types.TInt32(), "int32(-2)"},
+		{300.5, types.TInt08(), "int8(44)"},
+		{2.5, types.TNum(), "float64(2.5)"},
+		{1e19, types.TInt64(), "nil"},
+		{math.Inf(-1), types.TInt64(), "nil"},
+		{"1", types.TInt64(), "nil"},
+		{true, types.TNum(), "nil"},
+		{int64(1), types.TStr(), "nil"},
+	}
+	for _, test := range tests {
+		name := fmt.Sprintf("%s as %s", constantString(test.value), test.dataType.ToString())
+		t.Run(name, func(t *testing.T) {
+			if actual := constantString(CastConstant(test.value, test.dataType)); actual != test.expected {
+				t.Errorf("cast to %s, expected %s", actual, test.expected)
+			}
+		})
+	}
+}
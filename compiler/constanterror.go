@@ -3,8 +3,11 @@ package main
 const (
 	INVALID_TYPE_OR_MISSING               = "missing type or type is invalid"
 	INVALID_HASHMAP_KEY_TYPE              = "invalid hashmap key type"
+	INVALID_HASHMAP_KEY_NOT_COMPARABLE    = "struct %s cannot be used as a hashmap key, member %s has non-comparable type %s"
 	INVALID_HASHMAP_VALUE_TYPE            = "invalid hashmap value type"
 	INVALID_ARRAY_ELEMENT_TYPE            = "invalid array element type"
+	INVALID_CHAN_ELEMENT_TYPE             = "invalid channel element type"
+	INVALID_FUTURE_RESULT_TYPE            = "invalid future result type"
 	INVALID_STRUCT_NAME                   = "struct name must be an identifier"
 	INVALID_STRUCT_NAME_DUPLICATE         = "struct name must be unique"
 	INVALID_STRUCT_ATTR_EMPTY             = "struct must have at least one attribute"
@@ -14,12 +17,24 @@ const (
 	INVALID_FUNCTION_NAME_DUPLICATE       = "function name must be unique"
 	INVALID_FUNCTION_PARAM_NAME           = "parameter name must be an identifier"
 	INVALID_FUNCTION_PARAM_NAME_DUPLICATE = "parameter name must be unique"
+	INVALID_EXPORT_NAME_DUPLICATE         = "exported name %s is already declared by the library"
 	INVALID_IMPORT_PATH                   = "import path must be a string"
-	INVALID_IMPORT_PATH_VALUE             = "import path must be relative"
+	INVALID_IMPORT_PATH_VALUE             = "import path must be relative, or a go: or lib: import"
 	INVALID_IMPORT_NAMES_EMPTY            = "import must have at least one attribute"
 	INVALID_IMPORT_PATH_NOT_FOUND         = "import path not found"
+	INVALID_IMPORT_LIB_NAME               = "invalid standard library module name"
+	INVALID_IMPORT_LIB_NOT_FOUND          = "module %s not found in the standard library"
+	INVALID_IMPORT_GO_PACKAGE             = "package %s not found"
+	INVALID_IMPORT_GO_MODULE              = "package %s not found, its module must be required in " + PROJECT_FILE + " and be available offline"
 	INVALID_IMPORT_NAME                   = "import name must be an identifier"
 	INVALID_IMPORT_NAME_DUPLICATE         = "symbol name must be unique"
+	INVALID_IMPORT_NAMESPACE_VALUE        = "module %s cannot be used as a value"
+	INVALID_IMPORT_NAMESPACE_MEMBER       = "symbol %s not found in module %s"
+	INVALID_IMPORT_CONSTANT_OVERFLOW      = "constant %s overflows i64"
+	INVALID_IMPORT_GENERIC_TYPE           = "generic type %s cannot be imported, a script cannot give it type arguments"
+	INVALID_GENERIC_VALUE                 = "generic function %s must be called, it has no value before it is instantiated"
+	INVALID_GENERIC_CALL                  = "cannot instantiate generic function: %s"
 	INVALID_VARIABLE_NAME                 = "variable name must be an identifier"
 	INVALID_VARIABLE_NAME_DUPLICATE       = "variable name must be unique"
+	INVALID_ALLOW_CHECK                   = "unknown check %s in " + ALLOW_DIRECTIVE + ", the checks are %s"
 )
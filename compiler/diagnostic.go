@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Line and column of a span, both counted from 1.
+type TJsonLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type TJsonSpan struct {
+	Start TJsonLocation `json:"start"`
+	End   TJsonLocation `json:"end"`
+}
+
+// A diagnostic as printed by --diagnostics json.
+type TJsonDiagnostic struct {
+	Code     TErrorCode `json:"code"`
+	Severity TSeverity  `json:"severity"`
+	File     string     `json:"file"`
+	Message  string     `json:"message"`
+	Span     TJsonSpan  `json:"span"`
+	Notes    []string   `json:"notes"`
+}
+
+// Formats the diagnostics as a JSON array.
+func (diagnostics *TDiagnostics) Json() string {
+	items := make([]TJsonDiagnostic, 0, len(diagnostics.Items))
+	for _, item := range diagnostics.Items {
+		notes := item.Notes
+		if notes == nil {
+			notes = []string{}
+		}
+		items = append(items, TJsonDiagnostic{
+			Code:     item.Code,
+			Severity: item.Severity,
+			File:     item.File,
+			Message:  item.Message,
+			Span: TJsonSpan{
+				Start: TJsonLocation{Line: item.Position.SLine, Column: item.Position.SColm},
+				End:   TJsonLocation{Line: item.Position.ELine, Column: item.Position.EColm},
+			},
+			Notes: notes,
+		})
+	}
+	data, _ := json.MarshalIndent(items, "", "  ")
+	return string(data)
+}
+
+// The subset of SARIF 2.1.0 that code scanning tools read.
+type TSarifLog struct {
+	Schema  string      `json:"$schema"`
+	Version string      `json:"version"`
+	Runs    []TSarifRun `json:"runs"`
+}
+
+type TSarifRun struct {
+	Tool    TSarifTool     `json:"tool"`
+	Results []TSarifResult `json:"results"`
+}
+
+type TSarifTool struct {
+	Driver TSarifDriver `json:"driver"`
+}
+
+type TSarifDriver struct {
+	Name  string       `json:"name"`
+	Rules []TSarifRule `json:"rules"`
+}
+
+type TSarifRule struct {
+	Id               string     `json:"id"`
+	Name             string     `json:"name"`
+	ShortDescription TSarifText `json:"shortDescription"`
+	FullDescription  TSarifText `json:"fullDescription"`
+}
+
+type TSarifText struct {
+	Text string `json:"text"`
+}
+
+type TSarifResult struct {
+	RuleId    string           `json:"ruleId"`
+	Level     string           `json:"level"`
+	Message   TSarifText       `json:"message"`
+	Locations []TSarifLocation `json:"locations"`
+}
+
+type TSarifLocation struct {
+	PhysicalLocation TSarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type TSarifPhysicalLocation struct {
+	ArtifactLocation TSarifArtifact `json:"artifactLocation"`
+	Region           TSarifRegion   `json:"region"`
+}
+
+type TSarifArtifact struct {
+	Uri string `json:"uri"`
+}
+
+type TSarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
+
+// Formats the diagnostics as a SARIF log, with a rule for each code.
+func (diagnostics *TDiagnostics) Sarif() string {
+	rules := make([]TSarifRule, 0)
+	results := make([]TSarifResult, 0, len(diagnostics.Items))
+	for _, code := range SortedErrorCodes() {
+		info := ErrorCodes[code]
+		rules = append(rules, TSarifRule{
+			Id:               string(code),
+			Name:             info.Title,
+			ShortDescription: TSarifText{Text: info.Title},
+			FullDescription:  TSarifText{Text: info.Explain},
+		})
+	}
+	for _, item := range diagnostics.Items {
+		message := item.Message
+		for _, note := range item.Notes {
+			message += "\nnote: " + note
+		}
+		results = append(results, TSarifResult{
+			RuleId:  string(item.Code),
+			Level:   string(item.Severity),
+			Message: TSarifText{Text: message},
+			Locations: []TSarifLocation{{
+				PhysicalLocation: TSarifPhysicalLocation{
+					ArtifactLocation: TSarifArtifact{Uri: sarifUri(item.File)},
+					Region: TSarifRegion{
+						StartLine:   item.Position.SLine,
+						StartColumn: item.Position.SColm,
+						EndLine:     item.Position.ELine,
+						EndColumn:   item.Position.EColm,
+					},
+				},
+			}},
+		})
+	}
+	log := TSarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []TSarifRun{{
+			Tool:    TSarifTool{Driver: TSarifDriver{Name: "parrot", Rules: rules}},
+			Results: results,
+		}},
+	}
+	data, _ := json.MarshalIndent(log, "", "  ")
+	return string(data)
+}
+
+// Returns the path relative to the working directory, which is the root of
+// the repository in a CI run, or a file URI if it is outside of it.
+func sarifUri(path string) string {
+	if cwd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(cwd, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	uri := url.URL{Scheme: "file", Path: filepath.ToSlash(path)}
+	return uri.String()
+}
@@ -72,6 +72,32 @@ func (env *TEnv) UpdateSymbolIsUsed(name string, isUsed bool) {
 	}
 }
 
+func (env *TEnv) UpdateSymbolValue(name string, value interface{}) {
+	current := env
+	for current != nil {
+		for i := range current.Symbols {
+			if current.Symbols[i].Name == name {
+				current.Symbols[i].Value = value
+				return
+			}
+		}
+		current = current.Parent
+	}
+}
+
+func (env *TEnv) UpdateSymbolType(name string, dataType *types.TTyping) {
+	current := env
+	for current != nil {
+		for i := range current.Symbols {
+			if current.Symbols[i].Name == name {
+				current.Symbols[i].DataType = dataType
+				return
+			}
+		}
+		current = current.Parent
+	}
+}
+
 // Used for defining global constants|variables|functions|classes|interfaces|enums|structs|etc.
 func DefineSymbol(env *TEnv, name string, namespace string, module string, dataType *types.TTyping) {
 	if env.HasLocalSymbol(name) {
@@ -4,15 +4,115 @@ import (
 	"fmt"
 	"math"
 	"os"
-	"runtime"
+	"sort"
 	"strings"
 )
 
 var PADDING = 3
 
+type TSeverity string
+
+const (
+	SEVERITY_ERROR   TSeverity = "error"
+	SEVERITY_WARNING TSeverity = "warning"
+)
+
+// Formats the diagnostics are printed in, see --diagnostics.
+const (
+	OUTPUT_TEXT  = "text"
+	OUTPUT_JSON  = "json"
+	OUTPUT_SARIF = "sarif"
+)
+
+// Checks whose diagnostics are warnings. Each can be turned off or made
+// an error with -W, and turned off within a declaration by a comment.
+const (
+	CHECK_UNUSED = "unused"
+	CHECK_PANICS = "panics"
+	// Rules of parrot lint, see LintRules
+	CHECK_NAMING      = "naming"
+	CHECK_SHADOW      = "shadow"
+	CHECK_UNREACHABLE = "unreachable"
+	CHECK_REDUNDANT   = "redundant"
+	CHECK_CAPTURE     = "capture"
+	CHECK_UNCHECKED   = "unchecked"
+)
+
+var Checks = []string{
+	CHECK_UNUSED,
+	CHECK_PANICS,
+	CHECK_NAMING,
+	CHECK_SHADOW,
+	CHECK_UNREACHABLE,
+	CHECK_REDUNDANT,
+	CHECK_CAPTURE,
+	CHECK_UNCHECKED,
+}
+
+// Levels a check is set to with -W.
+const (
+	LEVEL_OFF   = "off"
+	LEVEL_WARN  = "warn"
+	LEVEL_ERROR = "error"
+)
+
+// The comment that turns checks off within the declaration below it.
+const ALLOW_DIRECTIVE = "parrot:allow"
+
+// A compile error of a script.
+type TDiagnostic struct {
+	Code     TErrorCode
+	Severity TSeverity
+	File     string
+	Data     []rune
+	Message  string
+	Position TPosition
+	Notes    []string // Hints printed after the message
+}
+
+// Checks turned off within a declaration by a parrot:allow comment.
+type TSuppression struct {
+	File     string
+	Position TPosition
+	Checks   []string
+}
+
+// Collects the compile errors, so that a compile reports all the
+// errors of a file instead of stopping at the first one.
+type TDiagnostics struct {
+	Items        []TDiagnostic
+	Limit        int               // Errors reported before the compile gives up
+	Output       string            // One of OUTPUT_TEXT, OUTPUT_JSON or OUTPUT_SARIF
+	Levels       map[string]string // Level of each check set with -W
+	Werror       bool              // Warnings are reported as errors
+	Suppressions []TSuppression
+	Keep         bool // Errors do not exit, the language server reports them
+}
+
+var Diagnostics = &TDiagnostics{
+	Items:        make([]TDiagnostic, 0),
+	Limit:        20,
+	Output:       OUTPUT_TEXT,
+	Levels:       make(map[string]string),
+	Suppressions: make([]TSuppression, 0),
+}
+
+// Panicked to abandon the statement that has an error. The tokenizer,
+// the parser, the forwarder and the analyzer recover from it and go on
+// with what follows, see RecoverAbort.
+type TAbort struct{}
+
+// Panicked instead of exiting while the language server runs, which
+// reports it and goes on serving.
+type TSystemError struct {
+	Message string
+}
+
 func RaiseSystemError(message any) {
-	_, fileName, line, _ := runtime.Caller(1)
-	err := fmt.Sprintf("DEBUG(%s:%d) | [ERROR] %s", fileName, line, fmt.Sprint(message))
+	if Diagnostics.Keep {
+		panic(TSystemError{Message: fmt.Sprint(message)})
+	}
+	err := fmt.Sprintf("error: %s\n", fmt.Sprint(message))
 	fmt.Fprint(os.Stderr, err)
 	// Collect and free the memory
 	CollectAndFree()
@@ -20,12 +120,239 @@ func RaiseSystemError(message any) {
 	os.Exit(1)
 }
 
-func RaiseLanguageCompileError(file string, data []rune, message string, position TPosition) {
-	_, fileName, line, _ := runtime.Caller(1)
-	lines := strings.Split(string(data), "\n")
+// Reports an error and abandons the statement it is found in.
+func RaiseLanguageCompileError(file string, data []rune, code TErrorCode, message string, position TPosition, notes ...string) {
+	ReportLanguageCompileError(file, data, code, message, position, notes...)
+	panic(TAbort{})
+}
+
+// Reports an error that leaves the code around it valid, the analysis goes on.
+func ReportLanguageCompileError(file string, data []rune, code TErrorCode, message string, position TPosition, notes ...string) {
+	Diagnostics.Add(TDiagnostic{
+		Code:     code,
+		Severity: SEVERITY_ERROR,
+		File:     file,
+		Data:     data,
+		Message:  message,
+		Position: position,
+		Notes:    notes,
+	})
+}
+
+// Reports the diagnostic of a check at the level the check is set to.
+// Reports whether the compile goes on, that is the check is off,
+// suppressed or only warns.
+func ReportLanguageWarning(check string, file string, data []rune, code TErrorCode, message string, position TPosition, notes ...string) bool {
+	level := Diagnostics.Level(check)
+	if level == LEVEL_OFF || Diagnostics.IsSuppressed(check, file, position) {
+		return true
+	}
+	severity := SEVERITY_WARNING
+	if level == LEVEL_ERROR || Diagnostics.Werror {
+		severity = SEVERITY_ERROR
+	}
+	Diagnostics.Add(TDiagnostic{
+		Code:     code,
+		Severity: severity,
+		File:     file,
+		Data:     data,
+		Message:  message,
+		Position: position,
+		Notes:    notes,
+	})
+	return severity == SEVERITY_WARNING
+}
+
+// Abandons the statement without an error, for one that uses
+// a value whose error is already reported.
+func Abort() {
+	panic(TAbort{})
+}
+
+// Reports whether value, the result of recover(), abandoned a statement.
+// Any other panic is not recovered from, it is raised again.
+func RecoverAbort(value any) bool {
+	if value == nil {
+		return false
+	}
+	if _, ok := value.(TAbort); ok {
+		return true
+	}
+	panic(value)
+}
+
+// Runs step, an error abandons the step but not the caller.
+// Reports whether the step completed.
+func Recovering(step func()) (ok bool) {
+	defer func() {
+		if RecoverAbort(recover()) {
+			ok = false
+		}
+	}()
+	step()
+	return true
+}
+
+// Note that points at the declaration of a symbol.
+func NoteDeclaredAt(symbol TSymbol) string {
+	return fmt.Sprintf("%s is declared at line %d", symbol.Name, symbol.Position.SLine)
+}
+
+// Returns the level of a check, checks warn unless set otherwise.
+func (diagnostics *TDiagnostics) Level(check string) string {
+	if level, ok := diagnostics.Levels[check]; ok {
+		return level
+	}
+	return LEVEL_WARN
+}
+
+// Sets the levels of checks from a list such as "unused=off,naming=error".
+func (diagnostics *TDiagnostics) SetLevels(levels string) error {
+	for _, item := range strings.Split(levels, ",") {
+		check, level, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok {
+			return fmt.Errorf("%s must be a check and a level, such as unused=off", item)
+		}
+		if !IsCheck(check) {
+			return fmt.Errorf("unknown check %s, the checks are %s", check, strings.Join(Checks, ", "))
+		}
+		if level != LEVEL_OFF && level != LEVEL_WARN && level != LEVEL_ERROR {
+			return fmt.Errorf("unknown level %s, the levels are off, warn and error", level)
+		}
+		diagnostics.Levels[check] = level
+	}
+	return nil
+}
+
+func IsCheck(name string) bool {
+	for _, check := range Checks {
+		if check == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Turns checks off within the declaration at position.
+func (diagnostics *TDiagnostics) Suppress(file string, position TPosition, checks []string) {
+	diagnostics.Suppressions = append(diagnostics.Suppressions, TSuppression{
+		File:     file,
+		Position: position,
+		Checks:   checks,
+	})
+}
+
+func (diagnostics *TDiagnostics) IsSuppressed(check string, file string, position TPosition) bool {
+	for _, suppression := range diagnostics.Suppressions {
+		if suppression.File != file || !suppression.Position.Contains(position) {
+			continue
+		}
+		for _, name := range suppression.Checks {
+			if name == check {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (diagnostics *TDiagnostics) Add(diagnostic TDiagnostic) {
+	for _, item := range diagnostics.Items {
+		// A declaration analyzed again reports its errors again
+		if item.File == diagnostic.File && item.Position == diagnostic.Position && item.Message == diagnostic.Message {
+			return
+		}
+	}
+	diagnostics.Items = append(diagnostics.Items, diagnostic)
+	if !diagnostics.Keep && diagnostics.Count(SEVERITY_ERROR) >= diagnostics.Limit {
+		diagnostics.Flush()
+	}
+}
+
+// Forgets the diagnostics and suppressions of an earlier compile.
+func (diagnostics *TDiagnostics) Reset() {
+	diagnostics.Items = diagnostics.Items[:0]
+	diagnostics.Suppressions = diagnostics.Suppressions[:0]
+}
+
+func (diagnostics *TDiagnostics) Count(severity TSeverity) int {
+	count := 0
+	for _, item := range diagnostics.Items {
+		if item.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+func (diagnostics *TDiagnostics) HasErrors() bool {
+	return diagnostics.Count(SEVERITY_ERROR) > 0
+}
+
+// Prints the errors and exits, if there are any. Called at the end of
+// each phase, a later phase would only report what follows from them.
+func (diagnostics *TDiagnostics) Check() {
+	if diagnostics.HasErrors() {
+		diagnostics.Flush()
+	}
+}
+
+// Prints the diagnostics, then exits.
+func (diagnostics *TDiagnostics) Flush() {
+	diagnostics.Print()
+	// Collect and free the memory
+	CollectAndFree()
+	// Exit the program
+	os.Exit(1)
+}
+
+// Prints the diagnostics sorted by file and position, and clears them.
+// A compile without errors prints its warnings before it goes on.
+func (diagnostics *TDiagnostics) Print() {
+	items := diagnostics.Items
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].File != items[j].File {
+			return items[i].File < items[j].File
+		}
+		if items[i].Position.SLine != items[j].Position.SLine {
+			return items[i].Position.SLine < items[j].Position.SLine
+		}
+		return items[i].Position.SColm < items[j].Position.SColm
+	})
+	switch diagnostics.Output {
+	case OUTPUT_JSON:
+		fmt.Fprintln(os.Stderr, diagnostics.Json())
+	case OUTPUT_SARIF:
+		fmt.Fprintln(os.Stderr, diagnostics.Sarif())
+	default:
+		for _, item := range items {
+			fmt.Fprint(os.Stderr, item.Format())
+		}
+		errors := diagnostics.Count(SEVERITY_ERROR)
+		warnings := diagnostics.Count(SEVERITY_WARNING)
+		if errors >= diagnostics.Limit {
+			fmt.Fprintf(os.Stderr, "too many errors, stopped after %d\n", diagnostics.Limit)
+		} else if errors+warnings > 1 {
+			fmt.Fprintf(os.Stderr, "%s, %s\n", plural(errors, "error"), plural(warnings, "warning"))
+		}
+	}
+	diagnostics.Items = diagnostics.Items[:0]
+}
+
+func plural(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
+// Formats the error with the lines of source around it.
+func (diagnostic TDiagnostic) Format() string {
+	position := diagnostic.Position
+	lines := strings.Split(string(diagnostic.Data), "\n")
 	start := int(math.Max((float64(position.SLine)-1)-float64(PADDING), 0))
 	ended := int(math.Min((float64(position.ELine)+0)+float64(PADDING), float64(len(lines))))
-	fmtMessage := fmt.Sprintf("DEBUG(%s:%d) | [ERROR] %s:%d:%d: %s\n", fileName, line, file, position.SLine, position.SColm, message)
+	fmtMessage := fmt.Sprintf("%s:%d:%d: %s[%s]: %s\n", diagnostic.File, position.SLine, position.SColm, diagnostic.Severity, diagnostic.Code, diagnostic.Message)
 	strEnded := fmt.Sprintf("%d", int(ended))
 	for i := start; i < ended; i++ {
 		strStart := fmt.Sprintf("%d", i+1)
@@ -42,9 +369,8 @@ func RaiseLanguageCompileError(file string, data []rune, message string, positio
 			fmtMessage += "\n"
 		}
 	}
-	fmt.Fprint(os.Stderr, fmtMessage)
-	// Collect and free the memory
-	CollectAndFree()
-	// Exit the program
-	os.Exit(1)
+	for _, note := range diagnostic.Notes {
+		fmtMessage += fmt.Sprintf("note: %s\n", note)
+	}
+	return fmtMessage
 }
@@ -0,0 +1,435 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Stable code of a diagnostic. A code is never reused for another kind of
+// error, so that scripts, editors and "parrot explain" can rely on it.
+type TErrorCode string
+
+const (
+	CODE_INVALID_CHARACTER   TErrorCode = "NS0001"
+	CODE_INVALID_NUMBER      TErrorCode = "NS0002"
+	CODE_INVALID_SYNTAX      TErrorCode = "NS0003"
+	CODE_MISSING_EXPRESSION  TErrorCode = "NS0004"
+	CODE_MISSING_NAME        TErrorCode = "NS0005"
+	CODE_INVALID_TYPE        TErrorCode = "NS0006"
+	CODE_NOT_ALLOWED_HERE    TErrorCode = "NS0007"
+	CODE_DUPLICATE           TErrorCode = "NS0008"
+	CODE_IMPORT              TErrorCode = "NS0009"
+	CODE_IMPORT_SYMBOL       TErrorCode = "NS0010"
+	CODE_UNDEFINED_SYMBOL    TErrorCode = "NS0011"
+	CODE_TYPE_MISMATCH       TErrorCode = "NS0012"
+	CODE_INVALID_OPERATION   TErrorCode = "NS0013"
+	CODE_ARGUMENT_COUNT      TErrorCode = "NS0014"
+	CODE_UNKNOWN_MEMBER      TErrorCode = "NS0015"
+	CODE_ASSIGN_CONSTANT     TErrorCode = "NS0016"
+	CODE_UNUSED_VARIABLE     TErrorCode = "NS0017"
+	CODE_UNUSED_EXPRESSION   TErrorCode = "NS0018"
+	CODE_UNREACHABLE         TErrorCode = "NS0019"
+	CODE_PANICS              TErrorCode = "NS0020"
+	CODE_CONCURRENCY         TErrorCode = "NS0021"
+	CODE_SWITCH              TErrorCode = "NS0022"
+	CODE_INVALID_STRUCT      TErrorCode = "NS0023"
+	CODE_INVALID_METHOD      TErrorCode = "NS0024"
+	CODE_CONSTANT_EXPRESSION TErrorCode = "NS0025"
+	CODE_GENERIC             TErrorCode = "NS0026"
+	CODE_NAMING              TErrorCode = "NS0027"
+	CODE_MAIN_FUNCTION       TErrorCode = "NS0028"
+	CODE_PROJECT_FILE        TErrorCode = "NS0029"
+	CODE_INTERNAL            TErrorCode = "NS0030"
+	CODE_SHADOW              TErrorCode = "NS0031"
+	CODE_CONSTANT_CONDITION  TErrorCode = "NS0032"
+	CODE_LOOP_CAPTURE        TErrorCode = "NS0033"
+	CODE_UNCHECKED_ERROR     TErrorCode = "NS0034"
+)
+
+type TErrorCodeInfo struct {
+	Title   string // One line summary, the name of the SARIF rule
+	Explain string // Long-form explanation printed by "parrot explain"
+}
+
+var ErrorCodes = map[TErrorCode]TErrorCodeInfo{
+	CODE_INVALID_CHARACTER: {
+		Title: "invalid character",
+		Explain: `The tokenizer found a character that cannot start any token, or a string
+literal that is not closed before the end of its line.
+
+    local s str = "hello;   // missing closing quote
+
+Check for stray characters copied from another language, and close every
+string with the quote that opened it.`,
+	},
+	CODE_INVALID_NUMBER: {
+		Title: "invalid number literal",
+		Explain: `A number literal has digits that its base does not allow, or a value that
+does not fit in a 64 bit integer or float.
+
+    local mask i32 = 0xZZ;   // Z is not a hex digit
+
+Hex literals start with 0x, octal literals with 0o and binary literals
+with 0b.`,
+	},
+	CODE_INVALID_SYNTAX: {
+		Title: "invalid syntax",
+		Explain: `The parser expected a particular token, such as ";" or ")", but found
+another one, or a statement is missing a part that its form requires,
+such as the body of a loop.
+
+    if (ready) println("go")   // the body must be a block
+
+The message names the token that was expected and the one that was found.`,
+	},
+	CODE_MISSING_EXPRESSION: {
+		Title: "missing expression",
+		Explain: `An expression is required, for example after a binary operator, after a
+comma in a list or as the value of a declaration.
+
+    local total i32 = 1 +;
+
+Complete the expression or remove the trailing operator or comma.`,
+	},
+	CODE_MISSING_NAME: {
+		Title: "missing or invalid name",
+		Explain: `A declaration, parameter, field or member access needs a name, and the
+name must be an identifier: a letter or "_" followed by letters, digits
+or "_".
+
+    struct Point { 1X i32; }
+
+Rename the declaration so that it is a valid identifier.`,
+	},
+	CODE_INVALID_TYPE: {
+		Title: "missing or invalid type",
+		Explain: `A type is missing, is not declared, or cannot be used where it appears.
+Map keys must be comparable, variables cannot be void, and the element
+type of an empty array or map literal cannot be inferred.
+
+    local names [str] = [];        // valid, the element type is declared
+    local scores {str:i32} = {};   // valid
+    local seen {[i32]:bool} = {};  // arrays are not comparable map keys
+
+Declare the type explicitly, or use a type that is allowed in that place.`,
+	},
+	CODE_NOT_ALLOWED_HERE: {
+		Title: "declaration not allowed here",
+		Explain: `Some declarations and statements are only valid in one scope. Structs,
+functions and imports are declared at the top of a file, locals only in
+a function, and return and break only where there is something to leave.
+
+    function main(args [str]) i32 {
+        struct Inner { X i32; }   // structs are declared at the top level
+        return 0;
+    }
+
+Move the declaration to a scope that allows it.`,
+	},
+	CODE_DUPLICATE: {
+		Title: "duplicate declaration",
+		Explain: `Two declarations in the same scope have the same name. This includes
+parameters of one function, attributes of one struct, methods of one
+type and names brought in by imports.
+
+    function add(a i32, a i32) i32 { return a; }
+
+Rename one of them. A name in an inner scope may not reuse the name of a
+local of its enclosing function either.`,
+	},
+	CODE_IMPORT: {
+		Title: "invalid import",
+		Explain: `An import path is malformed, or the file, standard library module or Go
+package it names cannot be found.
+
+    import ( Println ) from "go:fmt";     // Go package
+    import ( Shout ) from "./util.ns";    // script file, relative path
+    import * as strings from "lib:strings";
+
+Go packages outside the standard library must be required in the project
+file and be available in the module cache, the compiler does not download
+them.`,
+	},
+	CODE_IMPORT_SYMBOL: {
+		Title: "invalid imported symbol",
+		Explain: `An imported name is not declared by the module it is imported from, or it
+has a type that a script cannot use, such as a generic Go type.
+
+    import ( Printline ) from "go:fmt";   // fmt declares Println
+
+A namespace import cannot be used as a value, only its members can.`,
+	},
+	CODE_UNDEFINED_SYMBOL: {
+		Title: "undefined symbol",
+		Explain: `A name is used that is not declared in any enclosing scope, nor imported.
+
+    function main(args [str]) i32 {
+        println(count);   // count is not declared
+        return 0;
+    }
+
+Check the spelling, declare the symbol before it is used, or import it.`,
+	},
+	CODE_TYPE_MISMATCH: {
+		Title: "type mismatch",
+		Explain: `A value is stored, passed, returned or used as an index where a value of
+another type is required, and there is no implicit conversion between
+the two types.
+
+    local count i32 = "three";
+
+Convert the value with "as" where a conversion exists, or change the
+declared type.`,
+	},
+	CODE_INVALID_OPERATION: {
+		Title: "invalid operation",
+		Explain: `An operator, a call or a cast is applied to operands of types that do not
+support it.
+
+    local ok bool = true + 1;
+
+Arithmetic requires numbers, "+" also concatenates strings, comparisons
+require comparable operands and only values of function type can be
+called.`,
+	},
+	CODE_ARGUMENT_COUNT: {
+		Title: "wrong number of arguments",
+		Explain: `A function is called with more or fewer arguments than it declares
+parameters.
+
+    function add(a i32, b i32) i32 { return a + b; }
+    ...
+    add(1);
+
+Pass one argument for each parameter.`,
+	},
+	CODE_UNKNOWN_MEMBER: {
+		Title: "unknown member",
+		Explain: `A struct, Go type or module has no attribute or method of the given name.
+
+    struct Point { X i32; Y i32; }
+    ...
+    println(p.Z);
+
+Attribute and method names are case sensitive.`,
+	},
+	CODE_ASSIGN_CONSTANT: {
+		Title: "assignment to a constant",
+		Explain: `A constant, one of its members, or a value that is not addressable is
+assigned to, or a method that changes its receiver is called on a
+constant.
+
+    const limit i32 = 10;
+    limit = 20;
+
+Declare the symbol with local or var if it has to change.`,
+	},
+	CODE_UNUSED_VARIABLE: {
+		Title: "unused variable",
+		Explain: `A variable is declared but its value is never read.
+
+    function main(args [str]) i32 {
+        local unused i32 = 1;
+        return 0;
+    }
+
+Remove the variable, or use it.`,
+	},
+	CODE_UNUSED_EXPRESSION: {
+		Title: "unused expression",
+		Explain: `An expression statement has no effect, it computes a value that is
+discarded.
+
+    a + b;
+
+Assign the result, or remove the statement.`,
+	},
+	CODE_UNREACHABLE: {
+		Title: "unreachable code",
+		Explain: `A statement follows a return, break or continue in the same block, so
+it never runs.
+
+    return 0;
+    return 1;
+
+Remove the statement, or move it before the return.`,
+	},
+	CODE_PANICS: {
+		Title: "unchecked panic",
+		Explain: `A function that may panic is called from a function that does not declare
+"panics", or from the global scope. A function that declares "panics"
+must also contain something that may actually panic.
+
+    function parse(s str) panics i32 { ... }
+    function main(args [str]) i32 {
+        local n i32 = parse("1");   // main does not declare panics
+        return n;
+    }
+
+Declare "panics" on the caller, or remove it where nothing panics.`,
+	},
+	CODE_CONCURRENCY: {
+		Title: "invalid concurrent operation",
+		Explain: `A run, await, parallel block or channel operation is used in a way that is
+not allowed. Run takes a function call, only futures can be awaited,
+futures started in a parallel block cannot escape the block, not even
+in a function literal, and channels are only sent to and received from
+in their direction.
+
+    parallel {
+        local task = run fetch();
+        return await task;   // the block must wait for its tasks
+    }`,
+	},
+	CODE_SWITCH: {
+		Title: "invalid switch or select",
+		Explain: `A switch or select statement has duplicate cases, more than one default,
+cases whose type does not match the subject, or mixes type cases with
+value cases. Cases do not fall through, so break is not needed.
+
+    switch (n) {
+        case 1: println("one");
+        case 1: println("uno");
+    }`,
+	},
+	CODE_INVALID_STRUCT: {
+		Title: "invalid struct",
+		Explain: `A struct declares no attributes, contains itself by value, or is built with
+a literal whose values do not match its attributes.
+
+    struct Node { Next Node; }   // use a pointer to refer to another Node
+
+A struct used as a map key must only have comparable attributes.`,
+	},
+	CODE_INVALID_METHOD: {
+		Title: "invalid method",
+		Explain: `A method has a receiver that is not a struct or a pointer to a struct, or
+an operator method has the wrong receiver or return type.
+
+    function (p Point) Equals(other Point) bool { ... }
+
+Operator methods must use a value receiver and return a value of the type
+that the operator requires.`,
+	},
+	CODE_CONSTANT_EXPRESSION: {
+		Title: "invalid constant expression",
+		Explain: `An expression that the compiler evaluates is invalid: a division by zero,
+a constant that overflows its type, or a constant initialized with a
+value that is not known at compile time.
+
+    const Size i32 = 4 * 1024;   // valid
+    const Bad i8 = 300;          // overflows i8
+    local x i32 = 1 / 0;`,
+	},
+	CODE_GENERIC: {
+		Title: "invalid generic call",
+		Explain: `A generic Go function is used as a value, or called with arguments from
+which its type parameters cannot be inferred.
+
+    import ( Max ) from "go:slices";
+    local f ([i32]):i32 = Max;   // must be called, not stored
+
+Call the function directly with arguments of concrete types.`,
+	},
+	CODE_NAMING: {
+		Title: "naming convention",
+		Explain: `A name does not follow the naming convention of its kind. Structs,
+attributes, methods, global variables and global constants are
+PascalCase, functions, parameters and locals are camelCase.
+
+    function Add(a i32, b i32) i32 { ... }   // functions are camelCase`,
+	},
+	CODE_MAIN_FUNCTION: {
+		Title: "invalid main function",
+		Explain: `A program must declare a main function that takes the command line
+arguments as its only parameter.
+
+    function main(args [str]) i32 {
+        return 0;
+    }
+
+A library, built with --lib, does not need a main function.`,
+	},
+	CODE_PROJECT_FILE: {
+		Title: "invalid project file",
+		Explain: `The project file next to the script, or in one of its parent directories,
+cannot be read. Each line of its require block names a Go module and
+its version.
+
+    require (
+        github.com/google/uuid v1.6.0
+    )`,
+	},
+	CODE_INTERNAL: {
+		Title: "internal compiler error",
+		Explain: `The compiler reached a state that it does not handle. This is a bug in the
+compiler, not in the script. Please report it with the smallest script
+that reproduces it.`,
+	},
+	CODE_SHADOW: {
+		Title: "shadowed local",
+		Explain: `A local or parameter is declared with the name of a local or parameter
+of an enclosing block, which it hides for the rest of its block. An
+assignment meant for the outer one changes the inner one instead.
+
+    local total i32 = 0;
+    for (local i i32 = 0; i < 3; i++) {
+        local total i32 = i;   // hides the total above
+    }
+
+Rename one of them, or assign to the outer one.`,
+	},
+	CODE_CONSTANT_CONDITION: {
+		Title: "constant condition",
+		Explain: `The condition of an if is the literal true or false, so one of its
+branches always runs and the other never does.
+
+    if (true) { ... }
+
+Remove the if and keep the branch that runs.`,
+	},
+	CODE_LOOP_CAPTURE: {
+		Title: "loop variable captured by run",
+		Explain: `A function started with run inside a loop refers to a variable that the
+loop changes. The task reads the variable when it runs, by which time
+the loop may have changed it.
+
+    for (local i i32 = 0; i < 3; i++) {
+        run function() void { print(i); }();
+    }
+
+Pass the variable to the function as an argument, which is read when
+the task starts.`,
+	},
+	CODE_UNCHECKED_ERROR: {
+		Title: "unchecked error",
+		Explain: `A Go function that returns an error is called as a statement, so the
+error is ignored.
+
+    os.Remove(path);
+
+Assign the result and check the error.`,
+	},
+}
+
+// Returns the long-form explanation of a code, as printed by "parrot explain".
+func ExplainErrorCode(code string) (string, bool) {
+	info, ok := ErrorCodes[TErrorCode(strings.ToUpper(code))]
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("%s: %s\n\n%s\n", strings.ToUpper(code), info.Title, info.Explain), true
+}
+
+// Returns the codes sorted, the order in which they are listed.
+func SortedErrorCodes() []TErrorCode {
+	codes := make([]TErrorCode, 0, len(ErrorCodes))
+	for code := range ErrorCodes {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		return codes[i] < codes[j]
+	})
+	return codes
+}
@@ -0,0 +1,953 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	FLAG_FMT_CHECK = "--check"
+	FLAG_FMT_DIFF  = "--diff"
+	FORMAT_INDENT  = "    "
+)
+
+// Prints a parsed script back as canonical source. The parser keeps no
+// parentheses nor layout, so parentheses are put back where precedence
+// needs them and the comments are laid out again by their lines.
+type TFormatter struct {
+	lines    [][]rune
+	comments []TToken
+	next     int // First comment that is not printed yet
+	buffer   strings.Builder
+	indent   int
+	fresh    bool // Nothing is written on the current line yet
+	line     int  // Last line of the source that is printed
+	opened   bool // A block is opened, its first line has no blank above
+	pending  bool // The next line has a blank line above
+	hold     int  // Line of a block that closes on it, its trailing comments follow the "}"
+}
+
+func CreateFormatter(tokenizer *TTokenizer) *TFormatter {
+	formatter := new(TFormatter)
+	formatter.lines = make([][]rune, 0)
+	start := 0
+	for i, char := range tokenizer.Data {
+		if char == '\n' {
+			formatter.lines = append(formatter.lines, tokenizer.Data[start:i])
+			start = i + 1
+		}
+	}
+	formatter.lines = append(formatter.lines, tokenizer.Data[start:])
+	formatter.comments = tokenizer.Comments
+	formatter.fresh = true
+	return formatter
+}
+
+// Returns the canonical source of the script in data, or reports its
+// syntax errors.
+func FormatSource(file string, data string) (string, bool) {
+	errors := Diagnostics.Count(SEVERITY_ERROR)
+	parser := CreateParser(file, data)
+	program := parser.Parse()
+	if Diagnostics.Count(SEVERITY_ERROR) > errors {
+		return "", false
+	}
+	return CreateFormatter(parser.Tokenizer).Format(program), true
+}
+
+func (formatter *TFormatter) Format(program *TAst) string {
+	formatter.statements(program.AstArr0, true)
+	formatter.flushComments(math.MaxInt)
+	return strings.TrimRight(formatter.buffer.String(), "\n") + "\n"
+}
+
+func (formatter *TFormatter) write(text string) {
+	if formatter.fresh {
+		formatter.buffer.WriteString(strings.Repeat(FORMAT_INDENT, formatter.indent))
+		formatter.fresh = false
+	}
+	formatter.buffer.WriteString(text)
+}
+
+// Ends the line, with the comments that follow the code on the lines
+// printed so far.
+func (formatter *TFormatter) newline() {
+	for formatter.next < len(formatter.comments) {
+		comment := formatter.comments[formatter.next]
+		if comment.Position.SLine > formatter.line || comment.Position.SLine == formatter.hold || !formatter.isTrailing(comment) {
+			break
+		}
+		formatter.write(" //" + strings.TrimRight(comment.Value, " \t\r"))
+		formatter.next++
+	}
+	formatter.buffer.WriteString("\n")
+	formatter.fresh = true
+}
+
+func (formatter *TFormatter) mark(line int) {
+	if line > formatter.line {
+		formatter.line = line
+	}
+}
+
+// Puts a blank line above the next line when it is asked for, or when
+// the source has one or more above line.
+func (formatter *TFormatter) space(line int) {
+	if formatter.pending || (!formatter.opened && formatter.line > 0 && line > formatter.line+1) {
+		formatter.buffer.WriteString("\n")
+	}
+	formatter.pending = false
+	formatter.opened = false
+}
+
+// Reports whether code comes before the comment on its line.
+func (formatter *TFormatter) isTrailing(comment TToken) bool {
+	line := formatter.lines[comment.Position.SLine-1]
+	return strings.TrimSpace(string(line[:comment.Position.SColm-1])) != ""
+}
+
+// Reports whether comments that are not printed yet come before line.
+func (formatter *TFormatter) hasComments(line int) bool {
+	return formatter.next < len(formatter.comments) && formatter.comments[formatter.next].Position.SLine < line
+}
+
+// Prints the comments above line, each on a line of its own.
+func (formatter *TFormatter) flushComments(line int) {
+	for formatter.next < len(formatter.comments) && formatter.comments[formatter.next].Position.SLine < line {
+		comment := formatter.comments[formatter.next]
+		formatter.space(comment.Position.SLine)
+		formatter.write("//" + strings.TrimRight(comment.Value, " \t\r"))
+		formatter.mark(comment.Position.SLine)
+		formatter.next++
+		formatter.newline()
+	}
+}
+
+// Returns the text of a number or string as it is written in the source,
+// the tokenizer keeps their values only.
+func (formatter *TFormatter) source(node *TAst) string {
+	line := formatter.lines[node.Position.SLine-1]
+	start := node.Position.SColm - 1
+	end := start + 1
+	if node.Ttype == AstStr {
+		for end < len(line) && line[end] != '"' {
+			if line[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		return string(line[start : end+1])
+	}
+	isHex := strings.HasPrefix(strings.ToLower(string(line[start:min(start+2, len(line))])), "0x")
+	for end < len(line) {
+		char := line[end]
+		if char == '.' || char == '_' || (char >= '0' && char <= '9') || (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') {
+			end++
+		} else if (char == '+' || char == '-') && !isHex && (line[end-1] == 'e' || line[end-1] == 'E') {
+			end++
+		} else {
+			break
+		}
+	}
+	return string(line[start:end])
+}
+
+func isDeclarationNode(node *TAst) bool {
+	return node.Ttype == AstStruct || node.Ttype == AstFunction || node.Ttype == AstMethod
+}
+
+// Prints statements one per line. Structs and functions of a program
+// have a blank line around them.
+func (formatter *TFormatter) statements(nodes []*TAst, isProgram bool) {
+	var previous *TAst = nil
+	for _, node := range nodes {
+		if node.Ttype == AstEmptyStmnt {
+			continue
+		}
+		if isProgram && previous != nil && (isDeclarationNode(node) || isDeclarationNode(previous)) {
+			formatter.pending = true
+		}
+		formatter.flushComments(node.Position.SLine)
+		formatter.space(node.Position.SLine)
+		formatter.statement(node)
+		formatter.newline()
+		previous = node
+	}
+}
+
+// Prints "{", the statements and "}". The closing line is 0 for the
+// statement of a body that is not a block.
+func (formatter *TFormatter) block(nodes []*TAst, closing int) {
+	formatter.write("{")
+	if len(nodes) == 0 && !formatter.hasComments(closing) {
+		formatter.write("}")
+		return
+	}
+	hold := formatter.hold
+	if closing == formatter.line {
+		formatter.hold = closing
+	}
+	formatter.newline()
+	formatter.indent++
+	formatter.opened = true
+	formatter.statements(nodes, false)
+	formatter.flushComments(closing)
+	formatter.indent--
+	formatter.opened = false
+	formatter.hold = hold
+	formatter.mark(closing)
+	formatter.write("}")
+}
+
+// Prints the body of an if, else or loop as a block, with braces added
+// when the source leaves them out.
+func (formatter *TFormatter) body(node *TAst) {
+	if node.Ttype == AstCodeBlock {
+		formatter.block(node.AstArr0, node.Position.ELine)
+	} else if node.Ttype == AstEmptyStmnt {
+		formatter.block(nil, 0)
+	} else {
+		formatter.block([]*TAst{node}, 0)
+	}
+}
+
+func (formatter *TFormatter) statement(node *TAst) {
+	formatter.mark(node.Position.SLine)
+	switch node.Ttype {
+	case AstStruct:
+		formatter.structDecl(node)
+	case AstFunction, AstMethod:
+		formatter.functionDecl(node)
+	case AstImport:
+		formatter.importDecl(node)
+	case AstVar, AstConst, AstLocal:
+		formatter.varDecl(node)
+	case AstFor:
+		formatter.write("for (")
+		if node.Ast0 == nil {
+			formatter.write(";")
+		} else if node.Ast0.Ttype == AstVar || node.Ast0.Ttype == AstConst || node.Ast0.Ttype == AstLocal {
+			formatter.varDecl(node.Ast0)
+		} else {
+			formatter.assignment(node.Ast0)
+			formatter.write(";")
+		}
+		if node.Ast1 != nil {
+			formatter.write(" ")
+			formatter.expression(node.Ast1)
+		}
+		formatter.write(";")
+		if node.Ast2 != nil {
+			formatter.write(" ")
+			formatter.assignment(node.Ast2)
+		}
+		formatter.write(") ")
+		formatter.body(node.Ast3)
+	case AstForIf:
+		formatter.write("for ")
+		formatter.body(node.Ast3)
+		formatter.write(" if (")
+		formatter.expression(node.Ast1)
+		formatter.write(");")
+	case AstDo:
+		formatter.write("do ")
+		formatter.body(node.Ast1)
+		formatter.write(" while (")
+		formatter.expression(node.Ast0)
+		formatter.write(");")
+	case AstWhile:
+		formatter.write("while (")
+		formatter.expression(node.Ast0)
+		formatter.write(") ")
+		formatter.body(node.Ast1)
+	case AstIf:
+		formatter.write("if (")
+		formatter.expression(node.Ast0)
+		formatter.write(") ")
+		formatter.body(node.Ast1)
+		if node.Ast2 != nil {
+			if formatter.hasComments(node.Ast2.Position.SLine) {
+				// Comments between "}" and "else" stay there
+				formatter.newline()
+				formatter.flushComments(node.Ast2.Position.SLine)
+				formatter.write("else ")
+			} else {
+				formatter.write(" else ")
+			}
+			if node.Ast2.Ttype == AstIf {
+				formatter.statement(node.Ast2)
+			} else {
+				formatter.body(node.Ast2)
+			}
+		}
+	case AstSwitch:
+		formatter.write("switch (")
+		formatter.expression(node.Ast0)
+		formatter.write(") ")
+		formatter.clauses(node)
+	case AstSelect:
+		formatter.write("select ")
+		formatter.clauses(node)
+	case AstParallel:
+		formatter.write("parallel ")
+		formatter.body(node.Ast0)
+	case AstRunStmnt:
+		formatter.write("run ")
+		formatter.expression(node.Ast0)
+		formatter.write(";")
+	case AstContinueStmnt:
+		formatter.write("continue;")
+	case AstBreakStmnt:
+		formatter.write("break;")
+	case AstReturnStmnt:
+		formatter.write("return")
+		if node.Ast0 != nil {
+			formatter.write(" ")
+			formatter.assignment(node.Ast0)
+		}
+		formatter.write(";")
+	case AstCodeBlock:
+		formatter.body(node)
+	case AstExpressionStmnt:
+		formatter.assignment(node.Ast0)
+		formatter.write(";")
+	default:
+		RaiseSystemError(fmt.Sprintf("cannot format statement of type %d", node.Ttype))
+	}
+	formatter.mark(node.Position.ELine)
+}
+
+func (formatter *TFormatter) structDecl(node *TAst) {
+	if node.Flg1 {
+		formatter.write("export ")
+	}
+	formatter.write("struct " + node.Ast0.Str0 + " {")
+	formatter.newline()
+	formatter.indent++
+	formatter.opened = true
+	width := 0
+	for _, name := range node.AstArr0 {
+		width = max(width, len([]rune(name.Str0)))
+	}
+	for i, name := range node.AstArr0 {
+		formatter.flushComments(name.Position.SLine)
+		formatter.space(name.Position.SLine)
+		formatter.mark(name.Position.SLine)
+		formatter.write(name.Str0 + strings.Repeat(" ", width-len([]rune(name.Str0))) + " ")
+		formatter.typing(node.AstArr1[i])
+		formatter.write(";")
+		formatter.newline()
+	}
+	formatter.flushComments(node.Position.ELine)
+	formatter.indent--
+	formatter.opened = false
+	formatter.mark(node.Position.ELine)
+	formatter.write("}")
+}
+
+// Prints a function or method declaration, or a function expression,
+// which has no name.
+func (formatter *TFormatter) functionDecl(node *TAst) {
+	if node.Flg1 {
+		formatter.write("export ")
+	}
+	formatter.write("function")
+	names := node.AstArr0
+	types := node.AstArr1
+	if node.Ttype == AstMethod {
+		formatter.write(" (" + names[0].Str0 + " ")
+		formatter.typing(types[0])
+		formatter.write(")")
+		names = names[1:]
+		types = types[1:]
+	}
+	if node.Ast0 != nil {
+		formatter.write(" " + node.Ast0.Str0)
+	}
+	formatter.write("(")
+	for i, name := range names {
+		if i > 0 {
+			formatter.write(", ")
+		}
+		formatter.write(name.Str0 + " ")
+		formatter.typing(types[i])
+	}
+	formatter.write(") ")
+	formatter.typing(node.Ast1)
+	if node.Flg0 {
+		formatter.write(" " + KeyPanics)
+	}
+	formatter.write(" ")
+	formatter.block(node.AstArr2, node.Position.ELine)
+}
+
+// Prints "import * as name from path;" or "import ( a, b as c ) from path;".
+// The names of a list that starts on a line of its own keep their lines.
+func (formatter *TFormatter) importDecl(node *TAst) {
+	path := formatter.source(node.Ast0)
+	if node.Flg0 {
+		formatter.write("import * as " + node.Ast1.Str0 + " from " + path + ";")
+		return
+	}
+	names := make([]string, len(node.AstArr0))
+	for i, name := range node.AstArr0 {
+		names[i] = name.Str0
+		if node.AstArr1[i] != nil {
+			names[i] += " as " + node.AstArr1[i].Str0
+		}
+	}
+	if node.AstArr0[0].Position.SLine == node.Position.SLine {
+		formatter.write("import ( " + strings.Join(names, ", ") + " ) from " + path + ";")
+		return
+	}
+	formatter.write("import (")
+	formatter.indent++
+	for i, name := range node.AstArr0 {
+		if i > 0 {
+			formatter.write(",")
+		}
+		if i == 0 || name.Position.SLine > node.AstArr0[i-1].Position.SLine {
+			formatter.newline()
+			formatter.mark(name.Position.SLine)
+		} else {
+			formatter.write(" ")
+		}
+		formatter.write(names[i])
+	}
+	formatter.indent--
+	formatter.newline()
+	formatter.write(") from " + path + ";")
+}
+
+func (formatter *TFormatter) varDecl(node *TAst) {
+	switch node.Ttype {
+	case AstVar:
+		formatter.write(KeyVar + " ")
+	case AstConst:
+		formatter.write(KeyConst + " ")
+	default:
+		formatter.write(KeyLocal + " ")
+	}
+	for i, name := range node.AstArr0 {
+		if i > 0 {
+			formatter.write(", ")
+		}
+		formatter.write(name.Str0 + " ")
+		formatter.typing(node.AstArr1[i])
+		if node.AstArr2[i] != nil {
+			formatter.write(" = ")
+			formatter.expression(node.AstArr2[i])
+		}
+	}
+	formatter.write(";")
+}
+
+// Prints the cases of a switch or select, the default one last.
+func (formatter *TFormatter) clauses(node *TAst) {
+	formatter.write("{")
+	formatter.newline()
+	formatter.indent++
+	formatter.opened = true
+	clauses := node.AstArr0
+	if node.Ast1 != nil {
+		clauses = append(append(make([]*TAst, 0), clauses...), node.Ast1)
+	}
+	for _, clause := range clauses {
+		formatter.flushComments(clause.Position.SLine)
+		formatter.space(clause.Position.SLine)
+		formatter.mark(clause.Position.SLine)
+		if clause.Str0 == KeyDefault {
+			formatter.write(KeyDefault)
+		} else if node.Ttype == AstSelect {
+			formatter.write(KeyCase + " ")
+			formatter.assignment(clause.Ast0)
+		} else {
+			formatter.write(KeyCase + " ")
+			for i, label := range clause.AstArr0 {
+				if i > 0 {
+					formatter.write(", ")
+				}
+				if clause.Flg0 {
+					formatter.write(KeyIs + " ")
+					formatter.typing(label)
+				} else {
+					formatter.expression(label)
+				}
+			}
+		}
+		formatter.write(":")
+		formatter.newline()
+		formatter.indent++
+		formatter.opened = true
+		formatter.statements(clause.AstArr1, false)
+		formatter.indent--
+	}
+	formatter.flushComments(node.Position.ELine)
+	formatter.indent--
+	formatter.opened = false
+	formatter.mark(node.Position.ELine)
+	formatter.write("}")
+}
+
+// Prints what a statement may have in place of an expression, the
+// assignments, sends, increments and tuples.
+func (formatter *TFormatter) assignment(node *TAst) {
+	switch node.Ttype {
+	case AstAssign, AstBindAssign, AstMulAssign, AstDivAssign, AstModAssign, AstAddAssign, AstSubAssign,
+		AstShlAssign, AstShrAssign, AstAndAssign, AstOrAssign, AstXorAssign, AstSend:
+		formatter.assignment(node.Ast0)
+		formatter.write(" " + node.Str0 + " ")
+		formatter.assignment(node.Ast1)
+	case AstPlus2, AstMinus2:
+		formatter.assignment(node.Ast0)
+		formatter.write(node.Str0)
+	case AstTupleExpression:
+		formatter.list(node.AstArr0)
+	default:
+		formatter.expression(node)
+	}
+}
+
+func (formatter *TFormatter) list(nodes []*TAst) {
+	for i, node := range nodes {
+		if i > 0 {
+			formatter.write(", ")
+		}
+		formatter.expression(node)
+	}
+}
+
+// Returns how tight an expression binds, as the parser reads them.
+func precedence(node *TAst) int {
+	switch node.Ttype {
+	case AstLogAnd, AstLogOr:
+		return 1
+	case AstAnd, AstOr, AstXor:
+		return 2
+	case AstEq, AstNe:
+		return 3
+	case AstLt, AstLe, AstGt, AstGe:
+		return 4
+	case AstShl, AstShr:
+		return 5
+	case AstAdd, AstSub:
+		return 6
+	case AstMul, AstDiv, AstMod:
+		return 7
+	case AstCast, AstIs:
+		return 8
+	case AstPlus, AstMinus, AstNot, AstBitNot, AstReceive, AstRun, AstAwait, AstAllocation, AstIf, AstStruct:
+		return 9
+	}
+	return 10
+}
+
+// Reports whether an expression ends in one that reads as far to the
+// right as it can, an if expression or an allocation, which would take
+// in what follows it.
+func isOpenEnded(node *TAst) bool {
+	switch node.Ttype {
+	case AstIf, AstAllocation:
+		return true
+	case AstPlus, AstMinus, AstNot, AstBitNot, AstReceive, AstRun, AstAwait:
+		return isOpenEnded(node.Ast0)
+	case AstCast, AstIs:
+		return false
+	}
+	if precedence(node) < 8 {
+		return isOpenEnded(node.Ast1)
+	}
+	return false
+}
+
+func (formatter *TFormatter) operand(node *TAst, parenthesize bool) {
+	if parenthesize {
+		formatter.write("(")
+		formatter.expression(node)
+		formatter.write(")")
+	} else {
+		formatter.expression(node)
+	}
+}
+
+func (formatter *TFormatter) expression(node *TAst) {
+	formatter.mark(node.Position.SLine)
+	switch node.Ttype {
+	case AstIDN, AstBool, AstNull:
+		formatter.write(node.Str0)
+	case AstInt, AstNum, AstStr:
+		formatter.write(formatter.source(node))
+	case AstArray:
+		formatter.array(node)
+	case AstHashMap:
+		formatter.hashmap(node)
+	case AstStruct:
+		formatter.structLiteral(node)
+	case AstChan:
+		formatter.write(KeyChan + "<")
+		formatter.typing(node.Ast0)
+		formatter.write(">(")
+		if node.Ast1 != nil {
+			formatter.expression(node.Ast1)
+		}
+		formatter.write(")")
+	case AstFunction:
+		formatter.functionDecl(node)
+	case AstMember:
+		formatter.operand(node.Ast0, precedence(node.Ast0) < 10)
+		formatter.write("." + node.Ast1.Str0)
+	case AstIndex:
+		formatter.operand(node.Ast0, precedence(node.Ast0) < 10)
+		formatter.write("[")
+		formatter.expression(node.Ast1)
+		formatter.write("]")
+	case AstCall:
+		formatter.operand(node.Ast0, precedence(node.Ast0) < 10)
+		formatter.arguments(node)
+	case AstPlus, AstMinus, AstNot, AstBitNot, AstReceive:
+		formatter.write(node.Str0)
+		operand := node.Ast0
+		// "- -x" written together would read as a decrement
+		joined := (operand.Ttype == AstPlus || operand.Ttype == AstMinus) && strings.HasSuffix(node.Str0, operand.Str0)
+		formatter.operand(operand, precedence(operand) < 9 || joined)
+	case AstRun, AstAwait:
+		if node.Ttype == AstRun {
+			formatter.write(KeyRun + " ")
+		} else {
+			formatter.write(KeyAwait + " ")
+		}
+		formatter.operand(node.Ast0, precedence(node.Ast0) < 9)
+	case AstAllocation:
+		formatter.write(KeyNew + " ")
+		formatter.expression(node.Ast0)
+	case AstIf:
+		formatter.write(KeyIf + " (")
+		formatter.expression(node.Ast0)
+		formatter.write(") ")
+		formatter.expression(node.Ast1)
+		formatter.write(" " + KeyElse + " ")
+		formatter.expression(node.Ast2)
+	case AstCast, AstIs:
+		formatter.operand(node.Ast0, precedence(node.Ast0) < 8 || isOpenEnded(node.Ast0))
+		formatter.write(" " + node.Str0 + " ")
+		formatter.typing(node.Ast1)
+	case AstTupleExpression:
+		formatter.list(node.AstArr0)
+	default:
+		level := precedence(node)
+		if level > 7 {
+			RaiseSystemError(fmt.Sprintf("cannot format expression of type %d", node.Ttype))
+		}
+		formatter.operand(node.Ast0, precedence(node.Ast0) < level || isOpenEnded(node.Ast0))
+		formatter.write(" " + node.Str0 + " ")
+		formatter.operand(node.Ast1, precedence(node.Ast1) <= level)
+	}
+}
+
+// Prints the arguments of a call on one line, or, when the first one
+// starts on a line of its own, each on the line it starts on.
+func (formatter *TFormatter) arguments(node *TAst) {
+	arguments := node.AstArr0
+	if len(arguments) == 0 || arguments[0].Position.SLine <= node.Ast0.Position.ELine {
+		formatter.write("(")
+		formatter.list(arguments)
+		formatter.write(")")
+		return
+	}
+	formatter.write("(")
+	formatter.indent++
+	for i, argument := range arguments {
+		if i > 0 {
+			formatter.write(",")
+		}
+		if i == 0 || argument.Position.SLine > arguments[i-1].Position.ELine {
+			formatter.newline()
+			formatter.flushComments(argument.Position.SLine)
+		} else {
+			formatter.write(" ")
+		}
+		formatter.expression(argument)
+	}
+	formatter.indent--
+	formatter.newline()
+	formatter.flushComments(node.Position.ELine)
+	formatter.mark(node.Position.ELine)
+	formatter.write(")")
+}
+
+// Prints the items of a literal. A literal whose first item starts on a
+// line of its own has one item per line, each with a trailing comma,
+// otherwise the items are on one line within padding.
+func (formatter *TFormatter) items(opening int, closing int, padding string, count int, item func(i int) *TAst, print func(i int)) {
+	if count == 0 || item(0).Position.SLine <= opening {
+		formatter.write(padding)
+		for i := 0; i < count; i++ {
+			if i > 0 {
+				formatter.write(", ")
+			}
+			print(i)
+		}
+		formatter.write(padding)
+		return
+	}
+	formatter.newline()
+	formatter.indent++
+	formatter.opened = true
+	for i := 0; i < count; i++ {
+		formatter.flushComments(item(i).Position.SLine)
+		formatter.space(item(i).Position.SLine)
+		print(i)
+		formatter.write(",")
+		formatter.newline()
+	}
+	formatter.flushComments(closing)
+	formatter.indent--
+	formatter.opened = false
+	formatter.mark(closing)
+}
+
+func (formatter *TFormatter) array(node *TAst) {
+	formatter.write("[")
+	formatter.items(node.Position.SLine, node.Position.ELine, "", len(node.AstArr0), func(i int) *TAst {
+		return node.AstArr0[i]
+	}, func(i int) {
+		formatter.expression(node.AstArr0[i])
+	})
+	formatter.write("]")
+}
+
+func (formatter *TFormatter) hashmap(node *TAst) {
+	formatter.write("{")
+	formatter.items(node.Position.SLine, node.Position.ELine, "", len(node.AstArr0), func(i int) *TAst {
+		return node.AstArr0[i]
+	}, func(i int) {
+		formatter.expression(node.AstArr0[i])
+		formatter.write(": ")
+		formatter.expression(node.AstArr1[i])
+	})
+	formatter.write("}")
+}
+
+func (formatter *TFormatter) structLiteral(node *TAst) {
+	formatter.operand(node.Ast0, precedence(node.Ast0) < 10)
+	if len(node.AstArr0) == 0 {
+		formatter.write(" {}")
+		return
+	}
+	formatter.write(" {")
+	formatter.items(node.Ast0.Position.ELine, node.Position.ELine, " ", len(node.AstArr0), func(i int) *TAst {
+		return node.AstArr0[i]
+	}, func(i int) {
+		formatter.write(node.AstArr0[i].Str0 + ": ")
+		formatter.expression(node.AstArr1[i])
+	})
+	formatter.write("}")
+}
+
+func (formatter *TFormatter) typing(node *TAst) {
+	switch node.Ttype {
+	case AstTypePointer:
+		formatter.typing(node.Ast0)
+		formatter.write("*")
+	case AstTypeChan:
+		formatter.write(KeyChan + "<")
+		formatter.typing(node.Ast0)
+		formatter.write(">")
+	case AstTypeFuture:
+		formatter.write(KeyFuture + "<")
+		formatter.typing(node.Ast0)
+		if node.Flg0 {
+			formatter.write(" " + KeyPanics)
+		}
+		formatter.write(">")
+	case AstTypeHashMap:
+		formatter.write("{")
+		formatter.typing(node.Ast0)
+		formatter.write(":")
+		formatter.typing(node.Ast1)
+		formatter.write("}")
+	case AstTypeArray:
+		formatter.write("[")
+		formatter.typing(node.Ast0)
+		formatter.write("]")
+	case AstTypeTuple, AstTypeFunc:
+		formatter.write("(")
+		for i, argument := range node.AstArr0 {
+			if i > 0 {
+				formatter.write(", ")
+			}
+			formatter.typing(argument)
+		}
+		formatter.write(")")
+		if node.Ttype == AstTypeFunc {
+			formatter.write(":")
+			formatter.typing(node.Ast0)
+		}
+	default:
+		formatter.write(node.Str0)
+	}
+}
+
+// Returns the scripts of paths, the .ns files of a directory and those
+// within it.
+func scriptFiles(paths []string) []string {
+	files := make([]string, 0)
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			RaiseSystemError(fmt.Sprintf("error reading file %s", path))
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		filepath.WalkDir(path, func(file string, entry os.DirEntry, err error) error {
+			if err == nil && !entry.IsDir() && filepath.Ext(file) == ".ns" {
+				files = append(files, file)
+			}
+			return nil
+		})
+	}
+	return files
+}
+
+// Formats the scripts in place. With --check it lists the scripts that
+// are not formatted, with --diff it prints what formatting changes, and
+// both exit with 1 when a script would change.
+func format(args []string) {
+	check := false
+	diff := false
+	paths := make([]string, 0)
+	for _, arg := range args {
+		switch arg {
+		case FLAG_FMT_CHECK:
+			check = true
+		case FLAG_FMT_DIFF:
+			diff = true
+		default:
+			paths = append(paths, arg)
+		}
+	}
+	if len(paths) == 0 {
+		RaiseSystemError("error formatting, no file is specified")
+	}
+	files := scriptFiles(paths)
+	sources := make([]string, len(files))
+	formatted := make([]string, len(files))
+	for i, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			RaiseSystemError(fmt.Sprintf("error reading file %s", file))
+		}
+		sources[i] = string(data)
+		formatted[i], _ = FormatSource(ToAbsolutePath(file), sources[i])
+	}
+	// A script with a syntax error is left as it is
+	Diagnostics.Check()
+	changed := false
+	for i, file := range files {
+		if formatted[i] == sources[i] {
+			continue
+		}
+		changed = true
+		if check {
+			fmt.Println(file)
+		}
+		if diff {
+			fmt.Print(lineDiff(file, sources[i], formatted[i]))
+		}
+		if !check && !diff {
+			if err := os.WriteFile(file, []byte(formatted[i]), 0644); err != nil {
+				RaiseSystemError(fmt.Sprintf("error writing file %s", file))
+			}
+		}
+	}
+	if changed && (check || diff) {
+		os.Exit(1)
+	}
+}
+
+// Returns the unified diff of two texts, with 3 lines of context.
+func lineDiff(file string, before string, after string) string {
+	a := strings.Split(strings.TrimSuffix(before, "\n"), "\n")
+	b := strings.Split(strings.TrimSuffix(after, "\n"), "\n")
+	// Longest common subsequence of the lines that follow each pair
+	common := make([][]int, len(a)+1)
+	for i := range common {
+		common[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+	// Each line of the diff, ' ' kept, '-' removed or '+' added
+	type TLine struct {
+		Kind byte
+		A    int
+		B    int
+	}
+	lines := make([]TLine, 0)
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		if i < len(a) && j < len(b) && a[i] == b[j] {
+			lines = append(lines, TLine{' ', i, j})
+			i++
+			j++
+		} else if i < len(a) && (j == len(b) || common[i+1][j] >= common[i][j+1]) {
+			lines = append(lines, TLine{'-', i, j})
+			i++
+		} else {
+			lines = append(lines, TLine{'+', i, j})
+			j++
+		}
+	}
+	var out strings.Builder
+	out.WriteString("--- " + file + "\n+++ " + file + " (formatted)\n")
+	for start := 0; start < len(lines); {
+		if lines[start].Kind == ' ' {
+			start++
+			continue
+		}
+		// A hunk runs until 6 kept lines in a row
+		first := max(start-3, 0)
+		end := start
+		for end < len(lines) {
+			kept := 0
+			for end+kept < len(lines) && lines[end+kept].Kind == ' ' {
+				kept++
+			}
+			if end+kept == len(lines) || kept > 6 {
+				end += min(kept, 3)
+				break
+			}
+			end += kept + 1
+		}
+		removed, added := 0, 0
+		for _, line := range lines[first:end] {
+			if line.Kind != '+' {
+				removed++
+			}
+			if line.Kind != '-' {
+				added++
+			}
+		}
+		out.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", lines[first].A+1, removed, lines[first].B+1, added))
+		for _, line := range lines[first:end] {
+			if line.Kind == '+' {
+				out.WriteString("+" + b[line.B] + "\n")
+			} else {
+				out.WriteString(string(line.Kind) + a[line.A] + "\n")
+			}
+		}
+		start = end
+	}
+	return out.String()
+}
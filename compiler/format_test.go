@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the formatter")
+
+// Each testdata/format/<name>.ns formats to <name>.golden, which the
+// formatter leaves as it is.
+func TestFormatGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "format", "*.ns"))
+	if err != nil || len(inputs) == 0 {
+		t.Fatalf("no inputs in testdata/format: %v", err)
+	}
+	for _, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), ".ns")
+		t.Run(name, func(t *testing.T) {
+			source, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			formatted, ok := FormatSource(input, string(source))
+			if !ok {
+				t.Fatalf("%s has syntax errors", input)
+			}
+			golden := strings.TrimSuffix(input, ".ns") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, []byte(formatted), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if formatted != string(expected) {
+				t.Errorf("formatted %s differs from %s:\n%s", input, golden, lineDiff(golden, string(expected), formatted))
+			}
+			again, _ := FormatSource(golden, formatted)
+			if again != formatted {
+				t.Errorf("formatting %s again changes it:\n%s", golden, lineDiff(golden, formatted, again))
+			}
+		})
+	}
+}
+
+// Runs "fmt" with the arguments in FORMAT_TEST_ARGS, in the process the
+// exit code test starts, see formatCommand.
+func TestFormatCommand(t *testing.T) {
+	args := os.Getenv("FORMAT_TEST_ARGS")
+	if args == "" {
+		t.Skip("only run by TestFormatExitCodes")
+	}
+	format(strings.Fields(args))
+	os.Exit(0)
+}
+
+// Runs "fmt args" on the files of dir, returns its output and exit code.
+func formatCommand(t *testing.T, dir string, args string) (string, int) {
+	command := exec.Command(os.Args[0], "-test.run=^TestFormatCommand$")
+	command.Dir = dir
+	command.Env = append(os.Environ(), "FORMAT_TEST_ARGS="+args)
+	output, err := command.Output()
+	if exit, ok := err.(*exec.ExitError); ok {
+		return string(output), exit.ExitCode()
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	return string(output), 0
+}
+
+func TestFormatExitCodes(t *testing.T) {
+	source, err := os.ReadFile(filepath.Join("testdata", "format", "layout.ns"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden, err := os.ReadFile(filepath.Join("testdata", "format", "layout.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	file := filepath.Join(dir, "layout.ns")
+	if err := os.WriteFile(file, source, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	output, code := formatCommand(t, dir, FLAG_FMT_CHECK+" layout.ns")
+	if code != 1 || !strings.Contains(output, "layout.ns") {
+		t.Errorf("--check of an unformatted file: exit %d, output %q", code, output)
+	}
+	output, code = formatCommand(t, dir, FLAG_FMT_DIFF+" layout.ns")
+	if code != 1 || !strings.Contains(output, "+++ ") || !strings.Contains(output, "\n+    X i32;") {
+		t.Errorf("--diff of an unformatted file: exit %d, output %q", code, output)
+	}
+	if unchanged, _ := os.ReadFile(file); string(unchanged) != string(source) {
+		t.Errorf("--check and --diff must not write the file")
+	}
+
+	if _, code = formatCommand(t, dir, "layout.ns"); code != 0 {
+		t.Errorf("formatting in place: exit %d", code)
+	}
+	if formatted, _ := os.ReadFile(file); string(formatted) != string(golden) {
+		t.Errorf("formatted in place:\n%s", lineDiff(file, string(golden), string(formatted)))
+	}
+	output, code = formatCommand(t, dir, FLAG_FMT_CHECK+" "+FLAG_FMT_DIFF+" layout.ns")
+	if code != 0 || output != "" {
+		t.Errorf("--check --diff of a formatted file: exit %d, output %q", code, output)
+	}
+}
@@ -3,7 +3,7 @@ package main
 import (
 	"dev/types"
 	"fmt"
-	"os"
+	gotypes "go/types"
 	"strings"
 )
 
@@ -40,9 +40,10 @@ type TDelayedDefine struct {
 }
 
 type TImportLater struct {
-	Src TFileJob // The file that we need to import
-	Dst TFileJob // The file that we are importing to
-	Ast *TAst
+	Src  TFileJob // The file that we need to import
+	Dst  TFileJob // The file that we are importing to
+	Ast  *TAst
+	Name string // The name the symbol is imported as
 }
 
 type TForward struct {
@@ -256,6 +257,8 @@ func (f *TForward) getType(fileJob TFileJob, node *TAst) *types.TTyping {
 		return f.State.TBit
 	case AstTypeError:
 		return f.State.TErr
+	case AstTypeAny:
+		return f.State.TAny
 	case AstTypeVoid:
 		return f.State.TVoid
 	case AstTypeTuple:
@@ -288,6 +291,7 @@ func (f *TForward) getType(fileJob TFileJob, node *TAst) *types.TTyping {
 			RaiseLanguageCompileError(
 				fileJob.Path,
 				fileJob.Data,
+				CODE_INVALID_TYPE,
 				INVALID_ARRAY_ELEMENT_TYPE,
 				elementAst.Position,
 			)
@@ -296,6 +300,42 @@ func (f *TForward) getType(fileJob TFileJob, node *TAst) *types.TTyping {
 			f.State.AddArrayType(elementType)
 		}
 		return types.TArray(elementType)
+	case AstTypeChan:
+		elementAst := node.Ast0
+		elementType := f.getType(fileJob, elementAst)
+		if elementType == nil {
+			f.pushMissingTypes(TMissingTypeJob{
+				file:    fileJob,
+				NameAst: elementAst,
+				TypeAst: elementAst,
+			})
+			return nil
+		}
+		if types.IsVoid(elementType) {
+			RaiseLanguageCompileError(
+				fileJob.Path,
+				fileJob.Data,
+				CODE_INVALID_TYPE,
+				INVALID_CHAN_ELEMENT_TYPE,
+				elementAst.Position,
+			)
+		}
+		return types.TChan(elementType, types.ChanBoth)
+	case AstTypeFuture:
+		resultAst := node.Ast0
+		resultType := f.getType(fileJob, resultAst)
+		if resultType == nil {
+			f.pushMissingTypes(TMissingTypeJob{
+				file:    fileJob,
+				NameAst: resultAst,
+				TypeAst: resultAst,
+			})
+			return nil
+		}
+		if !f.State.FutureTypeExists(resultType) {
+			f.State.AddFutureType(resultType)
+		}
+		return types.TFuture(resultType, node.Flg0)
 	case AstTypeHashMap:
 		keyAst := node.Ast0
 		valAst := node.Ast1
@@ -322,7 +362,8 @@ func (f *TForward) getType(fileJob TFileJob, node *TAst) *types.TTyping {
 			RaiseLanguageCompileError(
 				fileJob.Path,
 				fileJob.Data,
-				INVALID_HASHMAP_KEY_TYPE,
+				CODE_INVALID_TYPE,
+				GetMapKeyError(keyType),
 				keyAst.Position,
 			)
 		}
@@ -330,6 +371,7 @@ func (f *TForward) getType(fileJob TFileJob, node *TAst) *types.TTyping {
 			RaiseLanguageCompileError(
 				fileJob.Path,
 				fileJob.Data,
+				CODE_INVALID_TYPE,
 				INVALID_HASHMAP_VALUE_TYPE,
 				valAst.Position,
 			)
@@ -387,6 +429,7 @@ func (f *TForward) forwardStruct(fileJob TFileJob, node *TAst) {
 		RaiseLanguageCompileError(
 			fileJob.Path,
 			fileJob.Data,
+			CODE_MISSING_NAME,
 			INVALID_STRUCT_NAME,
 			nameNode.Position,
 		)
@@ -395,6 +438,7 @@ func (f *TForward) forwardStruct(fileJob TFileJob, node *TAst) {
 		RaiseLanguageCompileError(
 			fileJob.Path,
 			fileJob.Data,
+			CODE_INVALID_STRUCT,
 			INVALID_STRUCT_ATTR_EMPTY,
 			node.Position,
 		)
@@ -411,6 +455,7 @@ func (f *TForward) forwardStruct(fileJob TFileJob, node *TAst) {
 			RaiseLanguageCompileError(
 				fileJob.Path,
 				fileJob.Data,
+				CODE_MISSING_NAME,
 				INVALID_STRUCT_ATTR_NAME,
 				attrN.Position,
 			)
@@ -430,6 +475,7 @@ func (f *TForward) forwardStruct(fileJob TFileJob, node *TAst) {
 			RaiseLanguageCompileError(
 				fileJob.Path,
 				fileJob.Data,
+				CODE_DUPLICATE,
 				INVALID_STRUCT_ATTR_DUPLICATE,
 				attrN.Position,
 			)
@@ -450,11 +496,14 @@ func (f *TForward) forwardStruct(fileJob TFileJob, node *TAst) {
 		RaiseLanguageCompileError(
 			fileJob.Path,
 			fileJob.Data,
+			CODE_DUPLICATE,
 			INVALID_STRUCT_NAME_DUPLICATE,
 			nameNode.Position,
+			NoteDeclaredAt(fileJob.Env.GetSymbol(nameNode.Str0)),
 		)
 	}
-	dataType := types.TStruct(JoinVariableName(GetFileNameWithoutExtension(fileJob.Path), nameNode.Str0), attributes)
+	structName := f.declarationName(fileJob, node)
+	dataType := types.SetScriptName(types.TStruct(structName, attributes), nameNode.Str0)
 	if len(missingTypes) > 0 {
 		f.pushMissingAttributes(TMissingAttributeJob{
 			file:         fileJob,
@@ -465,7 +514,7 @@ func (f *TForward) forwardStruct(fileJob TFileJob, node *TAst) {
 	}
 	fileJob.Env.AddSymbol(TSymbol{
 		Name:         nameNode.Str0,
-		NameSpace:    JoinVariableName(GetFileNameWithoutExtension(fileJob.Path), nameNode.Str0),
+		NameSpace:    structName,
 		Module:       GetFileNameWithoutExtension(fileJob.Path),
 		DataType:     dataType,
 		Position:     node.Position,
@@ -473,6 +522,7 @@ func (f *TForward) forwardStruct(fileJob TFileJob, node *TAst) {
 		IsConst:      true,
 		IsUsed:       true,
 		IsInitialize: true,
+		File:         fileJob.Path,
 	})
 }
 
@@ -487,6 +537,7 @@ func (f *TForward) forwardFunction(fileJob TFileJob, node *TAst, error bool) {
 		RaiseLanguageCompileError(
 			fileJob.Path,
 			fileJob.Data,
+			CODE_MISSING_NAME,
 			INVALID_FUNCTION_NAME,
 			nameNode.Position,
 		)
@@ -498,6 +549,7 @@ func (f *TForward) forwardFunction(fileJob TFileJob, node *TAst, error bool) {
 			RaiseLanguageCompileError(
 				fileJob.Path,
 				fileJob.Data,
+				CODE_MISSING_NAME,
 				INVALID_FUNCTION_PARAM_NAME,
 				nameNode.Position,
 			)
@@ -508,6 +560,7 @@ func (f *TForward) forwardFunction(fileJob TFileJob, node *TAst, error bool) {
 				RaiseLanguageCompileError(
 					fileJob.Path,
 					fileJob.Data,
+					CODE_INVALID_TYPE,
 					INVALID_TYPE_OR_MISSING,
 					paramTypeNode.Position,
 				)
@@ -522,6 +575,7 @@ func (f *TForward) forwardFunction(fileJob TFileJob, node *TAst, error bool) {
 			RaiseLanguageCompileError(
 				fileJob.Path,
 				fileJob.Data,
+				CODE_DUPLICATE,
 				INVALID_FUNCTION_PARAM_NAME_DUPLICATE,
 				nameNode.Position,
 			)
@@ -544,6 +598,7 @@ func (f *TForward) forwardFunction(fileJob TFileJob, node *TAst, error bool) {
 			RaiseLanguageCompileError(
 				fileJob.Path,
 				fileJob.Data,
+				CODE_INVALID_TYPE,
 				INVALID_TYPE_OR_MISSING,
 				returnTypeNode.Position,
 			)
@@ -558,13 +613,15 @@ func (f *TForward) forwardFunction(fileJob TFileJob, node *TAst, error bool) {
 		RaiseLanguageCompileError(
 			fileJob.Path,
 			fileJob.Data,
+			CODE_DUPLICATE,
 			INVALID_FUNCTION_NAME_DUPLICATE,
 			nameNode.Position,
+			NoteDeclaredAt(fileJob.Env.GetSymbol(nameNode.Str0)),
 		)
 	}
 	fileJob.Env.AddSymbol(TSymbol{
 		Name:         nameNode.Str0,
-		NameSpace:    JoinVariableName(GetFileNameWithoutExtension(fileJob.Path), nameNode.Str0),
+		NameSpace:    f.declarationName(fileJob, node),
 		Module:       GetFileNameWithoutExtension(fileJob.Path),
 		DataType:     types.TFunc(false, parameters, returnType, panics),
 		Position:     node.Position,
@@ -572,24 +629,74 @@ func (f *TForward) forwardFunction(fileJob TFileJob, node *TAst, error bool) {
 		IsConst:      true,
 		IsUsed:       true,
 		IsInitialize: true,
+		File:         fileJob.Path,
 	})
 }
 
+// Returns the Go name of the struct or function node declares.
+// The modules of a library share its package, so an exported name
+// must be unique across them.
+func (f *TForward) declarationName(fileJob TFileJob, node *TAst) string {
+	name := f.State.DeclarationName(fileJob.Path, node)
+	if f.State.IsLibrary() && node.Flg1 {
+		if f.State.Exports[name] {
+			RaiseLanguageCompileError(
+				fileJob.Path,
+				fileJob.Data,
+				CODE_DUPLICATE,
+				fmt.Sprintf(INVALID_EXPORT_NAME_DUPLICATE, name),
+				node.Ast0.Position,
+			)
+		}
+		f.State.Exports[name] = true
+	}
+	return name
+}
+
+// Returns the name an imported symbol is bound to, which is its alias if it has one.
+func (f *TForward) importName(fileJob TFileJob, nameNode *TAst, aliasNode *TAst) string {
+	if nameNode.Ttype != AstIDN || (aliasNode != nil && aliasNode.Ttype != AstIDN) {
+		RaiseLanguageCompileError(
+			fileJob.Path,
+			fileJob.Data,
+			CODE_IMPORT,
+			INVALID_IMPORT_NAME,
+			nameNode.Position,
+		)
+	}
+	if aliasNode != nil {
+		nameNode = aliasNode
+	}
+	if fileJob.Env.HasLocalSymbol(nameNode.Str0) {
+		RaiseLanguageCompileError(
+			fileJob.Path,
+			fileJob.Data,
+			CODE_DUPLICATE,
+			INVALID_IMPORT_NAME_DUPLICATE,
+			nameNode.Position,
+		)
+	}
+	return nameNode.Str0
+}
+
 func (f *TForward) forwardImport(fileJob TFileJob, node *TAst) {
 	pathNode := node.Ast0
 	namesNode := node.AstArr0
+	aliasNodes := node.AstArr1
 	if pathNode.Ttype != AstStr {
 		RaiseLanguageCompileError(
 			fileJob.Path,
 			fileJob.Data,
+			CODE_IMPORT,
 			INVALID_IMPORT_PATH,
 			pathNode.Position,
 		)
 	}
-	if len(namesNode) <= 0 {
+	if !node.Flg0 && len(namesNode) <= 0 {
 		RaiseLanguageCompileError(
 			fileJob.Path,
 			fileJob.Data,
+			CODE_IMPORT,
 			INVALID_IMPORT_NAMES_EMPTY,
 			node.Position,
 		)
@@ -597,51 +704,126 @@ func (f *TForward) forwardImport(fileJob TFileJob, node *TAst) {
 	if strings.HasPrefix(pathNode.Str0, "go:") {
 		pkg := pathNode.Str0[3:]
 		if !HasGoPackage(pkg) {
+			message := INVALID_IMPORT_GO_PACKAGE
+			if !IsStdPackage(pkg) {
+				message = INVALID_IMPORT_GO_MODULE
+			}
 			RaiseLanguageCompileError(
 				fileJob.Path,
 				fileJob.Data,
-				fmt.Sprintf("package %s not found", pkg),
+				CODE_IMPORT,
+				fmt.Sprintf(message, pkg),
 				pathNode.Position,
 			)
 		}
 
 		packages := GetGoPackages(pkg)
+		if node.Flg0 {
+			// The members are referred to through the Go import of the package
+			name := f.importName(fileJob, node.Ast1, nil)
+			importName := JoinVariableName(GetFileNameWithoutExtension(fileJob.Path), name)
+			members := CreateEnv(nil)
+			for _, memberName := range PackagesNames(packages) {
+				if members.HasLocalSymbol(memberName) {
+					continue
+				}
+				object := PackagesGetName(packages, memberName)
+				dataType := f.State.GoTypes.FromObject(object)
+				var value interface{}
+				if constant, ok := object.(*gotypes.Const); ok {
+					// A constant no type holds is rejected where it is used, see symbolValue
+					value, dataType, _ = GoConstant(f.State, constant)
+				}
+				members.AddSymbol(TSymbol{
+					Name:         memberName,
+					NameSpace:    importName + "." + memberName,
+					Module:       GetFileNameWithoutExtension(fileJob.Path),
+					DataType:     dataType,
+					Position:     node.Ast1.Position,
+					IsGlobal:     true,
+					IsConst:      true,
+					IsUsed:       true,
+					IsInitialize: true,
+					Value:        value,
+					File:         fileJob.Path,
+					Object:       object,
+				})
+			}
+			fileJob.Env.AddSymbol(TSymbol{
+				Name:         name,
+				NameSpace:    importName,
+				Module:       GetFileNameWithoutExtension(fileJob.Path),
+				DataType:     types.TVoid(),
+				Position:     node.Ast1.Position,
+				IsGlobal:     true,
+				IsConst:      true,
+				IsUsed:       true,
+				IsInitialize: true,
+				Members:      members,
+				Package:      pkg,
+				File:         fileJob.Path,
+			})
+			return
+		}
 		for _, pkg := range packages {
-			for _, nameNode := range namesNode {
-				if nameNode.Ttype != AstIDN {
+			for index, nameNode := range namesNode {
+				name := f.importName(fileJob, nameNode, aliasNodes[index])
+
+				if !PackagesHasName(packages, nameNode.Str0) {
 					RaiseLanguageCompileError(
 						fileJob.Path,
 						fileJob.Data,
-						INVALID_IMPORT_NAME,
+						CODE_IMPORT_SYMBOL,
+						fmt.Sprintf("symbol %s not found in package %s", nameNode.Str0, pkg),
 						nameNode.Position,
 					)
 				}
 
-				if !PackagesHasName(packages, nameNode.Str0) {
+				symbol := PackagesGetName(packages, nameNode.Str0)
+				if IsGenericGoType(symbol) {
 					RaiseLanguageCompileError(
 						fileJob.Path,
 						fileJob.Data,
-						fmt.Sprintf("symbol %s not found in package %s", nameNode.Str0, pkg),
+						CODE_IMPORT_SYMBOL,
+						fmt.Sprintf(INVALID_IMPORT_GENERIC_TYPE, nameNode.Str0),
 						nameNode.Position,
 					)
 				}
 
-				symbol := PackagesGetName(packages, nameNode.Str0)
-				symbolType := symbol.Type()
-
-				convertedType := types.TFromGoTypes(symbolType)
+				convertedType := f.State.GoTypes.FromObject(symbol)
 				if convertedType == nil {
 					RaiseLanguageCompileError(
 						fileJob.Path,
 						fileJob.Data,
-						fmt.Sprintf("symbol %s has invalid type %s (unsupported go type conversion)", nameNode.Str0, symbolType.String()),
+						CODE_IMPORT_SYMBOL,
+						fmt.Sprintf("symbol %s has invalid type %s (unsupported go type conversion)", nameNode.Str0, symbol.Type().String()),
 						nameNode.Position,
 					)
 				}
 
+				var value interface{}
+				if constant, ok := symbol.(*gotypes.Const); ok {
+					// Declared as a Go constant, see visitImport, one no type
+					// holds is rejected where it is used, see symbolValue
+					value, convertedType, _ = GoConstant(f.State, constant)
+				}
+
+				nameSpace := JoinVariableName(GetFileNameWithoutExtension(fileJob.Path), name)
+				goPackage := ""
+				if types.IsGeneric(convertedType) {
+					// Generic functions are instantiated where they are called, see
+					// genericCall, the package is imported under the mangled name,
+					// which no name of the script shadows
+					nameSpace = nameSpace + "." + nameNode.Str0
+					goPackage = pkg.PkgPath
+				} else if types.IsStruct(convertedType) {
+					// The type is declared as an alias of the Go one, see visitImport
+					convertedType = types.WithGoName(convertedType, nameSpace)
+				}
+
 				fileJob.Env.AddSymbol(TSymbol{
-					Name:         nameNode.Str0,
-					NameSpace:    JoinVariableName(GetFileNameWithoutExtension(fileJob.Path), nameNode.Str0),
+					Name:         name,
+					NameSpace:    nameSpace,
 					Module:       GetFileNameWithoutExtension(fileJob.Path),
 					DataType:     convertedType,
 					Position:     nameNode.Position,
@@ -649,31 +831,58 @@ func (f *TForward) forwardImport(fileJob TFileJob, node *TAst) {
 					IsConst:      true,
 					IsUsed:       true,
 					IsInitialize: true,
+					Value:        value,
+					Package:      goPackage,
+					File:         fileJob.Path,
+					Object:       symbol,
 				})
 			}
 		}
 		return
 	}
 
-	if !(strings.HasPrefix(pathNode.Str0, "./") || strings.HasPrefix(pathNode.Str0, "../")) {
+	actualPath := ""
+	if IsLibPath(pathNode.Str0) {
+		libPath, ok := ResolveLibPath(f.State.LibPath, pathNode.Str0)
+		if !ok {
+			RaiseLanguageCompileError(
+				fileJob.Path,
+				fileJob.Data,
+				CODE_IMPORT,
+				INVALID_IMPORT_LIB_NAME,
+				pathNode.Position,
+			)
+		}
+		actualPath = libPath
+	} else if strings.HasPrefix(pathNode.Str0, "./") || strings.HasPrefix(pathNode.Str0, "../") {
+		actualPath = ResolvePath(GetDir(fileJob.Path), pathNode.Str0)
+	} else {
 		RaiseLanguageCompileError(
 			fileJob.Path,
 			fileJob.Data,
+			CODE_IMPORT,
 			INVALID_IMPORT_PATH_VALUE,
 			pathNode.Position,
 		)
 	}
 
-	actualPath := ResolvePath(GetDir(fileJob.Path), pathNode.Str0)
-
 	// If wala pa nakita sa f.Files
 	// E push sa pending imports (f.Imports)
 	if !f.hasFile(actualPath) {
-		data, err := os.ReadFile(actualPath)
-		if err != nil {
+		data, err := f.State.ReadFile(actualPath)
+		if err != nil && IsLibPath(pathNode.Str0) {
 			RaiseLanguageCompileError(
 				fileJob.Path,
 				fileJob.Data,
+				CODE_IMPORT,
+				fmt.Sprintf(INVALID_IMPORT_LIB_NOT_FOUND, pathNode.Str0),
+				pathNode.Position,
+			)
+		} else if err != nil {
+			RaiseLanguageCompileError(
+				fileJob.Path,
+				fileJob.Data,
+				CODE_IMPORT,
 				INVALID_IMPORT_PATH_NOT_FOUND,
 				pathNode.Position,
 			)
@@ -702,45 +911,53 @@ func (f *TForward) forwardImport(fileJob TFileJob, node *TAst) {
 
 	childFile := f.getFile(actualPath)
 
+	if node.Flg0 {
+		// The members are looked up in the module when they are used
+		name := f.importName(fileJob, node.Ast1, nil)
+		fileJob.Env.AddSymbol(TSymbol{
+			Name:         name,
+			NameSpace:    JoinVariableName(GetFileNameWithoutExtension(fileJob.Path), name),
+			Module:       GetFileNameWithoutExtension(childFile.Path),
+			DataType:     types.TVoid(),
+			Position:     node.Ast1.Position,
+			IsGlobal:     true,
+			IsConst:      true,
+			IsUsed:       true,
+			IsInitialize: true,
+			Members:      childFile.Env,
+			File:         fileJob.Path,
+		})
+		return
+	}
+
 	for i := range namesNode {
 		nameNode := namesNode[i]
-		if nameNode.Ttype != AstIDN {
-			RaiseLanguageCompileError(
-				fileJob.Path,
-				fileJob.Data,
-				INVALID_IMPORT_NAME,
-				nameNode.Position,
-			)
-		}
+		name := f.importName(fileJob, nameNode, aliasNodes[i])
 		if !childFile.Env.HasLocalSymbol(nameNode.Str0) {
 			f.pushImportLater(TImportLater{
-				Src: childFile,
-				Dst: fileJob,
-				Ast: nameNode,
+				Src:  childFile,
+				Dst:  fileJob,
+				Ast:  nameNode,
+				Name: name,
 			})
 			continue
 		}
-		if fileJob.Env.HasGlobalSymbol(nameNode.Str0) {
-			RaiseLanguageCompileError(
-				fileJob.Path,
-				fileJob.Data,
-				INVALID_IMPORT_NAME_DUPLICATE,
-				nameNode.Position,
-			)
-		}
 		// Get the symbol
 		childFile.Env.UpdateSymbolIsUsed(nameNode.Str0, true)
+		origin := childFile.Env.GetSymbol(nameNode.Str0)
 		// Copy the symbol
 		fileJob.Env.AddSymbol(TSymbol{
-			Name:         nameNode.Str0,
+			Name:         name,
 			NameSpace:    JoinVariableName(GetFileNameWithoutExtension(childFile.Path), nameNode.Str0),
 			Module:       GetFileNameWithoutExtension(childFile.Path),
-			DataType:     childFile.Env.GetSymbol(nameNode.Str0).DataType,
+			DataType:     origin.DataType,
 			Position:     nameNode.Position,
 			IsGlobal:     true,
 			IsConst:      true,
 			IsUsed:       true,
 			IsInitialize: false,
+			File:         fileJob.Path,
+			Origin:       &origin,
 		})
 	}
 }
@@ -757,6 +974,7 @@ func (f *TForward) forwardVar(fileJob TFileJob, node *TAst) {
 			RaiseLanguageCompileError(
 				fileJob.Path,
 				fileJob.Data,
+				CODE_MISSING_NAME,
 				INVALID_VARIABLE_NAME,
 				nameNode.Position,
 			)
@@ -774,6 +992,7 @@ func (f *TForward) forwardVar(fileJob TFileJob, node *TAst) {
 			RaiseLanguageCompileError(
 				fileJob.Path,
 				fileJob.Data,
+				CODE_DUPLICATE,
 				INVALID_VARIABLE_NAME_DUPLICATE,
 				nameNode.Position,
 			)
@@ -788,6 +1007,7 @@ func (f *TForward) forwardVar(fileJob TFileJob, node *TAst) {
 			IsConst:      false,
 			IsUsed:       false,
 			IsInitialize: valuNode != nil,
+			File:         fileJob.Path,
 		})
 	}
 }
@@ -804,6 +1024,7 @@ func (f *TForward) forwardConst(fileJob TFileJob, node *TAst) {
 			RaiseLanguageCompileError(
 				fileJob.Path,
 				fileJob.Data,
+				CODE_MISSING_NAME,
 				INVALID_VARIABLE_NAME,
 				nameNode.Position,
 			)
@@ -821,6 +1042,7 @@ func (f *TForward) forwardConst(fileJob TFileJob, node *TAst) {
 			RaiseLanguageCompileError(
 				fileJob.Path,
 				fileJob.Data,
+				CODE_DUPLICATE,
 				INVALID_VARIABLE_NAME_DUPLICATE,
 				nameNode.Position,
 			)
@@ -835,6 +1057,7 @@ func (f *TForward) forwardConst(fileJob TFileJob, node *TAst) {
 			IsConst:      true,
 			IsUsed:       false,
 			IsInitialize: valuNode != nil,
+			File:         fileJob.Path,
 		})
 	}
 }
@@ -843,18 +1066,21 @@ func (f *TForward) forward(fileJob TFileJob) {
 	body := fileJob.Ast.AstArr0
 	for i := range body {
 		child := body[i]
-		switch child.Ttype {
-		case AstStruct:
-			f.forwardStruct(fileJob, child)
-		case AstFunction:
-			f.forwardFunction(fileJob, child, false)
-		case AstImport:
-			f.forwardImport(fileJob, child)
-		case AstVar:
-			f.forwardVar(fileJob, child)
-		case AstConst:
-			f.forwardConst(fileJob, child)
-		}
+		// A declaration with an error is skipped, the others are still declared
+		Recovering(func() {
+			switch child.Ttype {
+			case AstStruct:
+				f.forwardStruct(fileJob, child)
+			case AstFunction:
+				f.forwardFunction(fileJob, child, false)
+			case AstImport:
+				f.forwardImport(fileJob, child)
+			case AstVar:
+				f.forwardVar(fileJob, child)
+			case AstConst:
+				f.forwardConst(fileJob, child)
+			}
+		})
 	}
 }
 
@@ -869,7 +1095,9 @@ func (f *TForward) build() {
 	// Build the delayed imports
 	for f.hasDelayed() {
 		delayedImport := f.popDelayed()
-		f.forwardImport(delayedImport.SrcFile, delayedImport.Node)
+		Recovering(func() {
+			f.forwardImport(delayedImport.SrcFile, delayedImport.Node)
+		})
 	}
 
 	// Missing types resolution
@@ -877,9 +1105,10 @@ func (f *TForward) build() {
 		missingType := f.popMissingTypes()
 		finalType := f.getType(missingType.file, missingType.TypeAst)
 		if finalType == nil {
-			RaiseLanguageCompileError(
+			ReportLanguageCompileError(
 				missingType.file.Path,
 				missingType.file.Data,
+				CODE_INVALID_TYPE,
 				INVALID_TYPE_OR_MISSING,
 				missingType.TypeAst.Position,
 			)
@@ -893,12 +1122,14 @@ func (f *TForward) build() {
 			missingName := missingAttribute.missingNames[index]
 			attrType := f.getType(missingAttribute.file, missingType)
 			if attrType == nil {
-				RaiseLanguageCompileError(
+				ReportLanguageCompileError(
 					missingAttribute.file.Path,
 					missingAttribute.file.Data,
+					CODE_INVALID_TYPE,
 					INVALID_TYPE_OR_MISSING,
 					missingType.Position,
 				)
+				continue
 			}
 			missingAttribute.structType.AddMember(missingName, attrType)
 		}
@@ -907,7 +1138,9 @@ func (f *TForward) build() {
 	// Delayed defines resolution
 	for f.hasDelayedDefines() {
 		delayedDefine := f.popDelayedDefines()
-		f.forwardFunction(delayedDefine.SrcFile, delayedDefine.Node, true)
+		Recovering(func() {
+			f.forwardFunction(delayedDefine.SrcFile, delayedDefine.Node, true)
+		})
 	}
 
 	// Import later resolution
@@ -917,22 +1150,28 @@ func (f *TForward) build() {
 		dst := importLater.Dst
 		ast := importLater.Ast
 		if !src.Env.HasLocalSymbol(ast.Str0) {
-			RaiseLanguageCompileError(
+			ReportLanguageCompileError(
 				dst.Path,
 				dst.Data,
-				fmt.Sprintf("symbol %s not found in import %s", ast.Str0, src.Path),
+				CODE_IMPORT_SYMBOL,
+				fmt.Sprintf("symbol %s not found in import %s", ast.Str0, f.State.GetModuleName(src.Path)),
 				ast.Position,
 			)
+			continue
 		}
-		if dst.Env.HasLocalSymbol(ast.Str0) {
-			RaiseLanguageCompileError(
+		if dst.Env.HasLocalSymbol(importLater.Name) {
+			ReportLanguageCompileError(
 				dst.Path,
 				dst.Data,
-				fmt.Sprintf("symbol %s already exists in import %s", ast.Str0, dst.Path),
+				CODE_DUPLICATE,
+				fmt.Sprintf("symbol %s already exists in import %s", importLater.Name, dst.Path),
 				ast.Position,
 			)
+			continue
 		}
-		dst.Env.AddSymbol(src.Env.GetSymbol(ast.Str0))
+		symbol := src.Env.GetSymbol(ast.Str0)
+		symbol.Name = importLater.Name
+		dst.Env.AddSymbol(symbol)
 	}
 }
 
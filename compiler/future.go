@@ -0,0 +1,120 @@
+package main
+
+import (
+	"dev/types"
+	"strings"
+)
+
+// Future wrapper
+// A future runs a task on its own goroutine and holds its result.
+// Await blocks until the task is done, then returns the result
+// or re-raises the panic the task ended with.
+
+const FutureCode string = `
+type Future{{TypeName}} struct {
+	done chan struct{}
+	value {{GoType}}
+	panic any
+}
+func RunFuture{{TypeName}}(task func() {{GoType}}) *Future{{TypeName}} {
+	future := new(Future{{TypeName}})
+	future.done = make(chan struct{})
+	go func() {
+		defer func() {
+			future.panic = recover()
+			close(future.done)
+		}()
+		future.value = task()
+	}()
+	return future
+}
+func (future *Future{{TypeName}}) Await() {{GoType}} {
+	<-future.done
+	if future.panic != nil {
+		panic(future.panic)
+	}
+	return future.value
+}
+`
+
+// Same as FutureCode, for tasks that return nothing.
+const FutureVoidCode string = `
+type Future struct {
+	done chan struct{}
+	panic any
+}
+func RunFuture(task func()) *Future {
+	future := new(Future)
+	future.done = make(chan struct{})
+	go func() {
+		defer func() {
+			future.panic = recover()
+			close(future.done)
+		}()
+		task()
+	}()
+	return future
+}
+func (future *Future) Await() {
+	<-future.done
+	if future.panic != nil {
+		panic(future.panic)
+	}
+}
+`
+
+// Task group of a parallel block
+// Wait blocks until every task of the group is done,
+// then re-raises the first panic a task ended with.
+
+const TaskGroupCode string = `
+type TaskGroup struct {
+	wait sync.WaitGroup
+	lock sync.Mutex
+	panic any
+}
+func (group *TaskGroup) Run(task func()) {
+	group.wait.Add(1)
+	go func() {
+		defer func() {
+			if value := recover(); value != nil {
+				group.lock.Lock()
+				if group.panic == nil {
+					group.panic = value
+				}
+				group.lock.Unlock()
+			}
+			group.wait.Done()
+		}()
+		task()
+	}()
+}
+func (group *TaskGroup) Wait() {
+	group.wait.Wait()
+	if group.panic != nil {
+		panic(group.panic)
+	}
+}
+`
+
+type TFutureResultTemplate struct {
+	resultType *types.TTyping
+}
+
+func GetFutureHeader(t *types.TTyping) string {
+	return "Future" + t.ToNormalName()
+}
+
+func GetFutureRunner(t *types.TTyping) string {
+	return "RunFuture" + t.ToNormalName()
+}
+
+func GenerateFutureCode(t *types.TTyping) string {
+	if types.IsVoid(t) {
+		return FutureVoidCode
+	}
+	code := FutureCode
+	code = strings.ReplaceAll(code, "{{TypeName}}", t.ToNormalName())
+	code = strings.ReplaceAll(code, "{{GoType}}", t.ToGoType())
+	return code
+}
@@ -6,15 +6,26 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"sync"
 )
 
+// First line of each generated Go file, go vet and the editors of Go
+// recognize the files by it.
+const GENERATED_HEADER = "// Code generated by parrot. DO NOT EDIT.\n\n"
+
+// Lets the go commands add the requirements of the cache module to its
+// go.sum, a flag given to a command overrides the one in GOFLAGS.
+const MOD_FLAG = "-mod=mod"
+
 type TGoBinding struct {
 	// Cache paths to avoid repeated calculations
 	execPathCache  string
 	cachePath      string
 	goPathCache    string
 	goFmtPathCache string
+	// Project file of the script, nil if it has none
+	project *TProject
 	// Mutex to protect concurrent access to cache fields
 	mu sync.RWMutex
 }
@@ -23,6 +34,28 @@ func CreateGo() *TGoBinding {
 	return &TGoBinding{}
 }
 
+// API:Export
+func (g *TGoBinding) SetProject(project *TProject) {
+	g.project = project
+}
+
+// Environment of the go commands run on the cache. Modules are never
+// downloaded, they come from the proxy directory of the project or
+// GOMODCACHE. The GOFLAGS of the user are kept, the commands are given
+// MOD_FLAG instead.
+func (g *TGoBinding) getEnv() []string {
+	proxy := "off"
+	if g.project != nil && g.project.Proxy() != "" {
+		proxy = "file://" + filepath.ToSlash(g.project.Proxy())
+	}
+	return append(
+		os.Environ(),
+		"GOWORK=off",
+		"GOPROXY="+proxy,
+		"GOSUMDB=off",
+	)
+}
+
 func (g *TGoBinding) getExecPath() (string, error) {
 	// Check cache with read lock first
 	g.mu.RLock()
@@ -144,6 +177,22 @@ func (g *TGoBinding) GetGoFmt() (string, error) {
 	return "", errors.New("gofmt executable path does not exist")
 }
 
+// API:Export
+func (g *TGoBinding) GetLib() (string, error) {
+	execPath, err := g.getExecPath()
+	if err != nil {
+		return "", err
+	}
+
+	// Standard library modules are shipped next to the executable
+	libPath := filepath.Join(execPath, "lib")
+	if _, err := os.Stat(libPath); err != nil {
+		return "", errors.New("standard library path does not exist")
+	}
+
+	return libPath, nil
+}
+
 // API:Export
 func (g *TGoBinding) InitGoModToCache() (bool, error) {
 	goPath, err := g.GetGo()
@@ -166,21 +215,48 @@ func (g *TGoBinding) InitGoModToCache() (bool, error) {
 		}
 	}
 
-	// Check if the module already exists.
-	if _, err := os.Stat(goModPath); !os.IsNotExist(err) {
-		return true, nil
-	}
+	// Create the module if it does not exist yet.
+	if _, err := os.Stat(goModPath); os.IsNotExist(err) {
+		cmd := exec.Command(goPath, "mod", "init", modulePath)
+		cmd.Dir = cachePath
+		cmd.Env = g.getEnv()
 
-	cmd := exec.Command(goPath, "mod", "init", modulePath)
-	cmd.Dir = cachePath
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return false, errors.New(string(output))
+		}
+	}
 
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return false, errors.New(string(output))
+	if err := g.writeRequires(goModPath); err != nil {
+		return false, err
 	}
 
+	// Type check go: imports against the modules of the cache
+	SetGoPackagesConfig(cachePath, g.getEnv())
 	return true, nil
 }
 
+// Replaces the requirements of the cache go.mod with the ones of the project.
+func (g *TGoBinding) writeRequires(goModPath string) error {
+	const marker = "// Requirements of the project file"
+	data, err := os.ReadFile(goModPath)
+	if err != nil {
+		return err
+	}
+	goMod := string(data)
+	if index := strings.Index(goMod, marker); index >= 0 {
+		goMod = goMod[:index]
+	}
+	goMod = strings.TrimRight(goMod, "\n") + "\n\n" + marker + "\n"
+	if g.project != nil && len(g.project.Requires) > 0 {
+		goMod += "require (\n"
+		for _, require := range g.project.Requires {
+			goMod += fmt.Sprintf("\t%s %s\n", require.Path, require.Version)
+		}
+		goMod += ")\n"
+	}
+	return os.WriteFile(goModPath, []byte(goMod), 0644)
+}
+
 // API:Export
 func (g *TGoBinding) GoExecFmt(file string) (bool, error) {
 	goPath, err := g.GetGoFmt()
@@ -215,8 +291,10 @@ func (g *TGoBinding) GoRunCache() (string, error) {
 		return "", err
 	}
 
-	cmd := exec.Command(goPath, "run", cachePath)
+	cmd := exec.Command(goPath, "run", MOD_FLAG, cachePath)
 	cmd.Dir = cachePath
+	cmd.Env = g.getEnv()
+	cmd.Stdin = os.Stdin
 
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -238,8 +316,9 @@ func (g *TGoBinding) GoCompileCache(scriptPath string, output string) (bool, err
 		return false, err
 	}
 
-	cmd := exec.Command(goPath, "build", "-o", fmt.Sprintf("%s.exe", output), cachePath)
+	cmd := exec.Command(goPath, "build", MOD_FLAG, "-o", fmt.Sprintf("%s.exe", output), cachePath)
 	cmd.Dir = scriptPath
+	cmd.Env = g.getEnv()
 
 	if _, err := cmd.CombinedOutput(); err != nil {
 		return false, err
@@ -248,6 +327,95 @@ func (g *TGoBinding) GoCompileCache(scriptPath string, output string) (bool, err
 	return true, nil
 }
 
+// Removes the Go files generated by an earlier build from the cache.
+// API:Export
+func (g *TGoBinding) ClearCache() (bool, error) {
+	cachePath, err := g.getCachePath()
+	if err != nil {
+		return false, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(cachePath, "*.go"))
+	if err != nil {
+		return false, err
+	}
+	for _, file := range files {
+		if err := os.Remove(file); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// Builds the cache without linking it, which type checks a library.
+// API:Export
+func (g *TGoBinding) GoBuildCache() (bool, error) {
+	goPath, err := g.GetGo()
+	if err != nil {
+		return false, err
+	}
+
+	cachePath, err := g.getCachePath()
+	if err != nil {
+		return false, err
+	}
+
+	cmd := exec.Command(goPath, "build", MOD_FLAG, ".")
+	cmd.Dir = cachePath
+	cmd.Env = g.getEnv()
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return false, errors.New(string(output))
+	}
+
+	return true, nil
+}
+
+// Copies the generated files of the cache to the package directory output.
+// API:Export
+func (g *TGoBinding) ExportCache(files []string, output string) (bool, error) {
+	cachePath, err := g.getCachePath()
+	if err != nil {
+		return false, err
+	}
+
+	if err := os.MkdirAll(output, 0755); err != nil {
+		return false, err
+	}
+
+	// Remove the files of an earlier export, which the scripts may no
+	// longer generate, and leave the files written by hand
+	exported, err := filepath.Glob(filepath.Join(output, "*.go"))
+	if err != nil {
+		return false, err
+	}
+	for _, file := range exported {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return false, err
+		}
+		if !strings.HasPrefix(string(data), GENERATED_HEADER) {
+			continue
+		}
+		if err := os.Remove(file); err != nil {
+			return false, err
+		}
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(cachePath, file))
+		if err != nil {
+			return false, err
+		}
+		if err := os.WriteFile(filepath.Join(output, file), data, 0644); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
 // API:Export
 func (g *TGoBinding) Generate(file string, data string) (bool, error) {
 	cachePath, err := g.getCachePath()
@@ -262,7 +430,7 @@ func (g *TGoBinding) Generate(file string, data string) (bool, error) {
 	}
 
 	cacheFile := filepath.Join(cachePath, file)
-	if err := os.WriteFile(cacheFile, []byte(data), 0644); err != nil {
+	if err := os.WriteFile(cacheFile, []byte(GENERATED_HEADER+data), 0644); err != nil {
 		return false, err
 	}
 
@@ -4,6 +4,7 @@ const (
 	// Keywords
 	KeyStruct   = "struct"
 	KeyFunction = "function"
+	KeyExport   = "export"
 	KeyImport   = "import"
 	KeyFrom     = "from"
 	KeyVar      = "var"
@@ -18,6 +19,7 @@ const (
 	KeyCase     = "case"
 	KeyDefault  = "default"
 	KeyRun      = "run"
+	KeyAwait    = "await"
 	KeyContinue = "continue"
 	KeyBreak    = "break"
 	KeyReturn   = "return"
@@ -26,20 +28,28 @@ const (
 	KeyFalse    = "false"
 	KeyNull     = "null"
 	KeyNew      = "new"
-	KeyInt8     = "i8"    // Typing
-	KeyInt16    = "i16"   // Typing
-	KeyInt32    = "i32"   // Typing
-	KeyInt64    = "i64"   // Typing
-	KeyNum      = "num"   // Typing
-	KeyStr      = "str"   // Typing
-	KeyBool     = "bool"  // Typing
-	KeyVoid     = "void"  // Typing
-	KeyError    = "error" // Typing
+	KeyAs       = "as"
+	KeyIs       = "is"
+	KeySelect   = "select"
+	KeyParallel = "parallel"
+	KeyInt8     = "i8"     // Typing
+	KeyInt16    = "i16"    // Typing
+	KeyInt32    = "i32"    // Typing
+	KeyInt64    = "i64"    // Typing
+	KeyNum      = "num"    // Typing
+	KeyStr      = "str"    // Typing
+	KeyBool     = "bool"   // Typing
+	KeyVoid     = "void"   // Typing
+	KeyError    = "error"  // Typing
+	KeyAny      = "any"    // Typing
+	KeyChan     = "chan"   // Typing
+	KeyFuture   = "Future" // Typing
 )
 
 var Keywords = []string{
 	KeyStruct,
 	KeyFunction,
+	KeyExport,
 	KeyImport,
 	KeyFrom,
 	KeyVar,
@@ -54,6 +64,7 @@ var Keywords = []string{
 	KeyCase,
 	KeyDefault,
 	KeyRun,
+	KeyAwait,
 	KeyContinue,
 	KeyBreak,
 	KeyReturn,
@@ -62,6 +73,10 @@ var Keywords = []string{
 	KeyFalse,
 	KeyNull,
 	KeyNew,
+	KeyAs,
+	KeyIs,
+	KeySelect,
+	KeyParallel,
 	KeyInt8,
 	KeyInt16,
 	KeyInt32,
@@ -71,6 +86,9 @@ var Keywords = []string{
 	KeyBool,
 	KeyVoid,
 	KeyError,
+	KeyAny,
+	KeyChan,
+	KeyFuture,
 }
 
 func IsKeyword(str string) bool {
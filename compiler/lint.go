@@ -0,0 +1,645 @@
+package main
+
+import (
+	"dev/types"
+	"fmt"
+	gotypes "go/types"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var pascalCaseRegex = regexp.MustCompile(`^[A-Z][a-z0-9]*(?:[A-Z][a-z0-9]*)*$`)
+
+var camelCaseRegex = regexp.MustCompile(`^[a-z][a-z0-9]*(?:[A-Z][a-z0-9]*)*$`)
+
+var snakeCaseRegex = regexp.MustCompile(`^[a-z][a-z0-9]*(?:_[a-z][a-z0-9]*)*$`)
+
+var capitalCaseRegex = regexp.MustCompile(`^[A-Z][A-Z0-9]*$`)
+
+func IsPascalCase(s string) bool {
+	return pascalCaseRegex.MatchString(s)
+}
+
+func IsCamelCase(s string) bool {
+	return camelCaseRegex.MatchString(s)
+}
+
+func IsSnakeCase(s string) bool {
+	return snakeCaseRegex.MatchString(s)
+}
+
+func IsCapitalCase(s string) bool {
+	return capitalCaseRegex.MatchString(s)
+}
+
+// A rule of parrot lint. Visit is called with every statement and
+// expression of a file, and reports through the linter. The check of
+// a rule sets its level with -W and turns it off with parrot:allow.
+type TLintRule struct {
+	Check string
+	Code  TErrorCode
+	Visit func(linter *TLinter, node *TAst)
+}
+
+var LintRules = []TLintRule{
+	{Check: CHECK_NAMING, Code: CODE_NAMING, Visit: lintNaming},
+	{Check: CHECK_SHADOW, Code: CODE_SHADOW, Visit: lintShadow},
+	{Check: CHECK_UNREACHABLE, Code: CODE_UNREACHABLE, Visit: lintUnreachable},
+	{Check: CHECK_REDUNDANT, Code: CODE_CONSTANT_CONDITION, Visit: lintRedundant},
+	{Check: CHECK_CAPTURE, Code: CODE_LOOP_CAPTURE, Visit: lintCapture},
+	{Check: CHECK_UNCHECKED, Code: CODE_UNCHECKED_ERROR, Visit: lintUnchecked},
+}
+
+// Walks an analyzed file, keeping the locals in scope and the loops
+// around the node that the rules visit.
+type TLinter struct {
+	file        TFileJob
+	index       *TIndex
+	rules       []TLintRule
+	rule        TLintRule // Rule being visited
+	isStatement bool      // Whether the node visited is a statement
+	previous    *TAst     // Statement before the one visited in its block
+	scopes      []map[string]*TAst
+	loops       []*TAst // Loops of the function around the node visited
+}
+
+// Creates a linter of the rules that are not turned off, index holds
+// the references the analysis of the file recorded.
+func CreateLinter(file TFileJob, index *TIndex) *TLinter {
+	rules := make([]TLintRule, 0, len(LintRules))
+	for _, rule := range LintRules {
+		if Diagnostics.Level(rule.Check) != LEVEL_OFF {
+			rules = append(rules, rule)
+		}
+	}
+	return &TLinter{file: file, index: index, rules: rules}
+}
+
+// Creates a linter that walks with a rule of its own, how a rule looks
+// into a part of the file.
+func (linter *TLinter) with(visit func(linter *TLinter, node *TAst)) *TLinter {
+	return &TLinter{
+		file:  linter.file,
+		index: linter.index,
+		rules: []TLintRule{{Check: linter.rule.Check, Code: linter.rule.Code, Visit: visit}},
+	}
+}
+
+func (linter *TLinter) Lint() {
+	linter.statements(linter.file.Ast.AstArr0)
+}
+
+func (linter *TLinter) report(message string, position TPosition, notes ...string) {
+	ReportLanguageWarning(linter.rule.Check, linter.file.Path, linter.file.Data, linter.rule.Code, message, position, notes...)
+}
+
+func (linter *TLinter) visit(node *TAst, isStatement bool, previous *TAst) {
+	for _, rule := range linter.rules {
+		linter.rule = rule
+		linter.isStatement = isStatement
+		linter.previous = previous
+		rule.Visit(linter, node)
+	}
+}
+
+func (linter *TLinter) enter() {
+	linter.scopes = append(linter.scopes, make(map[string]*TAst))
+}
+
+func (linter *TLinter) leave() {
+	linter.scopes = linter.scopes[:len(linter.scopes)-1]
+}
+
+// Declares a local, names outside a function are globals and not kept,
+// nor is the blank identifier, which declares nothing.
+func (linter *TLinter) declare(name *TAst) {
+	if len(linter.scopes) > 0 && name.Str0 != "_" {
+		linter.scopes[len(linter.scopes)-1][name.Str0] = name
+	}
+}
+
+// Returns the declaration of the local or parameter name, in a scope
+// that encloses the innermost one when outer is true.
+func (linter *TLinter) lookup(name string, outer bool) *TAst {
+	last := len(linter.scopes)
+	if outer {
+		last--
+	}
+	for i := last - 1; i >= 0; i-- {
+		if declaration, ok := linter.scopes[i][name]; ok {
+			return declaration
+		}
+	}
+	return nil
+}
+
+// Reports whether the linter is in the global scope, outside any function.
+func (linter *TLinter) inGlobal() bool {
+	return len(linter.scopes) == 0
+}
+
+func (linter *TLinter) statements(nodes []*TAst) {
+	var previous *TAst = nil
+	for _, node := range nodes {
+		if node.Ttype == AstEmptyStmnt {
+			continue
+		}
+		linter.statement(node, previous)
+		previous = node
+	}
+}
+
+// Walks the body of an if, else or loop in a scope of its own.
+func (linter *TLinter) body(node *TAst) {
+	linter.enter()
+	if node.Ttype == AstCodeBlock {
+		linter.statements(node.AstArr0)
+	} else if node.Ttype != AstEmptyStmnt {
+		linter.statement(node, nil)
+	}
+	linter.leave()
+}
+
+func (linter *TLinter) loop(node *TAst, body *TAst) {
+	linter.loops = append(linter.loops, node)
+	linter.body(body)
+	linter.loops = linter.loops[:len(linter.loops)-1]
+}
+
+func (linter *TLinter) statement(node *TAst, previous *TAst) {
+	linter.visit(node, true, previous)
+	switch node.Ttype {
+	case AstFunction, AstMethod:
+		linter.function(node)
+	case AstVar, AstConst, AstLocal:
+		for i, name := range node.AstArr0 {
+			linter.expression(node.AstArr2[i])
+			linter.declare(name)
+		}
+	case AstFor:
+		linter.enter()
+		if node.Ast0 != nil && isVariableNode(node.Ast0) {
+			linter.statement(node.Ast0, nil)
+		} else {
+			linter.expression(node.Ast0)
+		}
+		linter.expression(node.Ast1)
+		linter.expression(node.Ast2)
+		linter.loop(node, node.Ast3)
+		linter.leave()
+	case AstForIf:
+		linter.loop(node, node.Ast3)
+		linter.expression(node.Ast1)
+	case AstDo:
+		linter.loop(node, node.Ast1)
+		linter.expression(node.Ast0)
+	case AstWhile:
+		linter.expression(node.Ast0)
+		linter.loop(node, node.Ast1)
+	case AstIf:
+		linter.expression(node.Ast0)
+		linter.body(node.Ast1)
+		if node.Ast2 != nil && node.Ast2.Ttype == AstIf {
+			linter.statement(node.Ast2, nil)
+		} else if node.Ast2 != nil {
+			linter.body(node.Ast2)
+		}
+	case AstSwitch, AstSelect:
+		linter.expression(node.Ast0)
+		linter.clauses(node)
+	case AstParallel:
+		linter.body(node.Ast0)
+	case AstRunStmnt, AstReturnStmnt, AstExpressionStmnt:
+		linter.expression(node.Ast0)
+	case AstCodeBlock:
+		linter.body(node)
+	}
+}
+
+func isVariableNode(node *TAst) bool {
+	return node.Ttype == AstVar || node.Ttype == AstConst || node.Ttype == AstLocal
+}
+
+// Walks the cases of a switch or select, each in a scope of its own.
+func (linter *TLinter) clauses(node *TAst) {
+	clauses := node.AstArr0
+	if node.Ast1 != nil {
+		clauses = append(append(make([]*TAst, 0), clauses...), node.Ast1)
+	}
+	for _, clause := range clauses {
+		linter.enter()
+		if clause.Str0 != KeyDefault && node.Ttype == AstSelect {
+			linter.expression(clause.Ast0)
+		} else if clause.Str0 != KeyDefault && !clause.Flg0 {
+			for _, label := range clause.AstArr0 {
+				linter.expression(label)
+			}
+		}
+		linter.statements(clause.AstArr1)
+		linter.leave()
+	}
+}
+
+// Walks a function, method or function expression. Its parameters
+// share the scope of its body, and the loops around it are not its own.
+func (linter *TLinter) function(node *TAst) {
+	loops := linter.loops
+	linter.loops = nil
+	linter.enter()
+	for _, name := range node.AstArr0 {
+		linter.declare(name)
+	}
+	linter.statements(node.AstArr2)
+	linter.leave()
+	linter.loops = loops
+}
+
+func (linter *TLinter) expression(node *TAst) {
+	if node == nil {
+		return
+	}
+	linter.visit(node, false, nil)
+	switch node.Ttype {
+	case AstFunction:
+		linter.function(node)
+	case AstBindAssign:
+		linter.expression(node.Ast1)
+		for _, name := range assignedNames(node.Ast0) {
+			linter.declare(name)
+		}
+	case AstStruct:
+		for _, value := range node.AstArr1 {
+			linter.expression(value)
+		}
+	case AstArray, AstTupleExpression:
+		for _, item := range node.AstArr0 {
+			linter.expression(item)
+		}
+	case AstHashMap:
+		for i, key := range node.AstArr0 {
+			linter.expression(key)
+			linter.expression(node.AstArr1[i])
+		}
+	case AstCall:
+		linter.expression(node.Ast0)
+		for _, argument := range node.AstArr0 {
+			linter.expression(argument)
+		}
+	case AstChan:
+		linter.expression(node.Ast1)
+	case AstMember, AstNullSafeMember, AstCast, AstIs:
+		linter.expression(node.Ast0)
+	case AstIf:
+		linter.expression(node.Ast0)
+		linter.expression(node.Ast1)
+		linter.expression(node.Ast2)
+	default:
+		linter.expression(node.Ast0)
+		linter.expression(node.Ast1)
+	}
+}
+
+// Returns the names that the left side of an assignment assigns.
+func assignedNames(node *TAst) []*TAst {
+	if node.Ttype == AstIDN {
+		return []*TAst{node}
+	}
+	names := make([]*TAst, 0)
+	if node.Ttype == AstTupleExpression {
+		for _, item := range node.AstArr0 {
+			if item.Ttype == AstIDN {
+				names = append(names, item)
+			}
+		}
+	}
+	return names
+}
+
+// Reports whether node assigns to, increments or decrements what is on its left.
+func isAssignmentNode(node *TAst) bool {
+	return (node.Ttype >= AstAssign && node.Ttype <= AstXorAssign && node.Ttype != AstBindAssign) ||
+		node.Ttype == AstPlus2 || node.Ttype == AstMinus2
+}
+
+// Structs, attributes, methods, global variables and global constants
+// are pascal case, functions, parameters and locals camel case. The
+// blank identifier is not a name.
+func lintNaming(linter *TLinter, node *TAst) {
+	switch {
+	case node.Ttype == AstStruct && linter.isStatement:
+		if !IsPascalCase(node.Ast0.Str0) {
+			linter.report("invalid struct name, struct name must be in a form of pascal case", node.Ast0.Position)
+		}
+		for _, attribute := range node.AstArr0 {
+			if !IsPascalCase(attribute.Str0) {
+				linter.report("invalid attribute name, struct attribute must be in a form of pascal case", attribute.Position)
+			}
+		}
+	case node.Ttype == AstFunction || node.Ttype == AstMethod:
+		parameters := node.AstArr0
+		if node.Ttype == AstMethod {
+			if !IsPascalCase(node.Ast0.Str0) {
+				linter.report("invalid method name, method name must be in a form of pascal case", node.Ast0.Position)
+			}
+			// The receiver is named as the script likes
+			parameters = parameters[1:]
+		} else if node.Ast0 != nil && !IsCamelCase(node.Ast0.Str0) {
+			linter.report("invalid function name, function name must be in a form of camel case", node.Ast0.Position)
+		}
+		for _, parameter := range parameters {
+			if parameter.Str0 != "_" && !IsCamelCase(parameter.Str0) {
+				linter.report("invalid parameter name, parameter name must be in a form of camel case", parameter.Position)
+			}
+		}
+	case node.Ttype == AstVar:
+		for _, name := range node.AstArr0 {
+			if !IsPascalCase(name.Str0) {
+				linter.report("invalid variable name, global variable must be in a form of pascal case", name.Position)
+			}
+		}
+	case node.Ttype == AstConst:
+		for _, name := range node.AstArr0 {
+			if linter.inGlobal() && !IsPascalCase(name.Str0) {
+				linter.report("invalid constant name, global constant name must be in a form of pascal case", name.Position)
+			} else if !linter.inGlobal() && !IsCamelCase(name.Str0) {
+				linter.report("invalid constant name, local constant name must be in a form of camel case", name.Position)
+			}
+		}
+	case node.Ttype == AstLocal:
+		for _, name := range node.AstArr0 {
+			if name.Str0 != "_" && !IsCamelCase(name.Str0) {
+				linter.report("invalid variable name, local variable must be in a form of camel case", name.Position)
+			}
+		}
+	}
+}
+
+// A local, or a parameter of a function expression, that hides a local
+// or parameter of an enclosing block. Globals may be hidden.
+func lintShadow(linter *TLinter, node *TAst) {
+	names := make([]*TAst, 0)
+	outer := true
+	switch {
+	case (node.Ttype == AstLocal || node.Ttype == AstConst) && linter.isStatement:
+		names = node.AstArr0
+	case node.Ttype == AstBindAssign:
+		names = assignedNames(node.Ast0)
+	case node.Ttype == AstFunction && !linter.isStatement:
+		// The parameters are declared in a scope that is not entered yet
+		names = node.AstArr0
+		outer = false
+	}
+	for _, name := range names {
+		if declaration := linter.lookup(name.Str0, outer); declaration != nil {
+			linter.report(
+				fmt.Sprintf("%s shadows a local of an enclosing block", name.Str0),
+				name.Position,
+				fmt.Sprintf("%s is declared at line %d", name.Str0, declaration.Position.SLine),
+			)
+		}
+	}
+}
+
+// The first statement after a return, break or continue of its block.
+func lintUnreachable(linter *TLinter, node *TAst) {
+	if !linter.isStatement || linter.previous == nil {
+		return
+	}
+	keyword := ""
+	switch linter.previous.Ttype {
+	case AstReturnStmnt:
+		keyword = KeyReturn
+	case AstBreakStmnt:
+		keyword = KeyBreak
+	case AstContinueStmnt:
+		keyword = KeyContinue
+	default:
+		return
+	}
+	linter.report(fmt.Sprintf("unreachable code, statement follows %s", keyword), node.Position)
+}
+
+// An if, statement or expression, whose condition is the literal true
+// or false, or its negation.
+func lintRedundant(linter *TLinter, node *TAst) {
+	if node.Ttype != AstIf {
+		return
+	}
+	condition := node.Ast0
+	negated := false
+	for condition.Ttype == AstNot {
+		condition = condition.Ast0
+		negated = !negated
+	}
+	if condition.Ttype != AstBool {
+		return
+	}
+	value := (condition.Str0 == KeyTrue) != negated
+	linter.report(fmt.Sprintf("redundant if, condition is always %t", value), node.Ast0.Position)
+}
+
+// A run in a loop whose function expression reads a variable that the
+// loop changes. Arguments are read when the task starts and are not
+// reported.
+func lintCapture(linter *TLinter, node *TAst) {
+	if (node.Ttype != AstRun && node.Ttype != AstRunStmnt) || len(linter.loops) == 0 {
+		return
+	}
+	variables := make(map[string]bool)
+	for _, loop := range linter.loops {
+		linter.loopVariables(loop, variables)
+	}
+	reported := make(map[string]bool)
+	linter.with(func(finder *TLinter, name *TAst) {
+		// Names outside a function are the arguments of the task
+		if name.Ttype != AstIDN || finder.inGlobal() || !variables[name.Str0] || reported[name.Str0] {
+			return
+		}
+		if finder.lookup(name.Str0, false) != nil {
+			return
+		}
+		reported[name.Str0] = true
+		linter.report(fmt.Sprintf("run captures loop variable %s, which may change before the task reads it", name.Str0), name.Position)
+	}).expression(node.Ast0)
+}
+
+// Adds the variables that a loop changes, those its header declares or
+// assigns and those its body assigns without declaring.
+func (linter *TLinter) loopVariables(loop *TAst, variables map[string]bool) {
+	finder := linter.with(func(finder *TLinter, node *TAst) {
+		if !isAssignmentNode(node) {
+			return
+		}
+		for _, name := range assignedNames(node.Ast0) {
+			if finder.lookup(name.Str0, false) == nil {
+				variables[name.Str0] = true
+			}
+		}
+	})
+	switch loop.Ttype {
+	case AstFor:
+		if loop.Ast0 != nil && isVariableNode(loop.Ast0) {
+			for _, name := range loop.Ast0.AstArr0 {
+				variables[name.Str0] = true
+			}
+		} else {
+			finder.expression(loop.Ast0)
+		}
+		finder.expression(loop.Ast2)
+		finder.body(loop.Ast3)
+	case AstForIf:
+		finder.body(loop.Ast3)
+	default:
+		finder.body(loop.Ast1)
+	}
+}
+
+// A call of a Go function or method, as a statement, whose results
+// include an error. A call whose only result is an error is not a
+// statement the analyzer allows.
+func lintUnchecked(linter *TLinter, node *TAst) {
+	if !linter.isStatement || node.Ttype != AstExpressionStmnt || node.Ast0.Ttype != AstCall || linter.index == nil {
+		return
+	}
+	name := node.Ast0.Ast0
+	if name.Ttype == AstMember || name.Ttype == AstNullSafeMember {
+		name = name.Ast1
+	}
+	if name.Ttype != AstIDN {
+		return
+	}
+	reference, ok := linter.index.At(linter.file.Path, name.Position.SLine, name.Position.SColm)
+	if !ok || reference.DataType == nil || !types.IsFunc(reference.DataType) {
+		return
+	}
+	object, ok := goReference(reference)
+	if !ok || (object != nil && object.Pkg() != nil && uncheckedExceptions[object.Pkg().Path()+"."+object.Name()]) {
+		return
+	}
+	returnType := reference.DataType.GetReturnType()
+	returnsError := types.IsError(returnType)
+	if types.IsTuple(returnType) {
+		for _, element := range returnType.GetElements() {
+			returnsError = returnsError || types.IsError(element)
+		}
+	}
+	if returnsError {
+		linter.report(fmt.Sprintf("unchecked error, the error %s returns is ignored", name.Str0), name.Position)
+	}
+}
+
+// Go functions whose error is not reported, printing to standard output
+// fails only when nothing could be reported either.
+var uncheckedExceptions = map[string]bool{
+	"fmt.Print":   true,
+	"fmt.Printf":  true,
+	"fmt.Println": true,
+}
+
+// Reports whether a reference names a function, method or value of Go,
+// and returns the Go object of a name imported from Go.
+func goReference(reference TReference) (gotypes.Object, bool) {
+	if reference.Symbol != nil {
+		symbol := reference.Symbol
+		for symbol.Origin != nil {
+			symbol = symbol.Origin
+		}
+		return symbol.Object, symbol.Object != nil
+	}
+	return nil, reference.Owner != nil && types.GoType(reference.Owner) != nil
+}
+
+// Lints the scripts of paths. An error in the analysis of a script, or
+// of a module it imports, stops it, and a rule that -W or --Werror
+// makes an error exits with 1.
+func lint(goBinding *TGoBinding, args []string) {
+	options := make(map[string]string)
+	paths := make([]string, 0)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "" || arg[0] != '-' {
+			paths = append(paths, arg)
+			continue
+		}
+		key := strings.TrimLeft(arg, "-")
+		if key == FLAG_WERROR {
+			options[key] = "true"
+			continue
+		}
+		if key != FLAG_WARN && key != FLAG_DIAG && key != FLAG_MAX_ERR {
+			RaiseSystemError(fmt.Sprintf("error reading options, %s is not an option of lint", arg))
+		}
+		if i+1 >= len(args) {
+			RaiseSystemError(fmt.Sprintf("error reading options, %s needs a value", arg))
+		}
+		i++
+		if key == FLAG_WARN && options[key] != "" {
+			options[key] += "," + args[i]
+		} else {
+			options[key] = args[i]
+		}
+	}
+	processDiagnosticArgs(options)
+	if len(paths) == 0 {
+		RaiseSystemError("error linting, no file is specified")
+	}
+	libPath, libErr := goBinding.GetLib()
+	project := ""
+	for i, file := range scriptFiles(paths) {
+		absPath := ToAbsolutePath(file)
+		data, err := os.ReadFile(absPath)
+		if err != nil {
+			RaiseSystemError(fmt.Sprintf("error reading file %s", absPath))
+		}
+		state := CreateState()
+		state.Index = CreateIndex()
+		if libErr == nil {
+			state.LibPath = libPath
+		}
+		parser := CreateParser(absPath, string(data))
+		ast := parser.Parse()
+		Diagnostics.Check()
+		// A script without main is linted as the library it is
+		if !declaresMain(ast) {
+			state.Package = strings.ToLower(GetFileNameWithoutExtension(absPath))
+		}
+		// Loaded packages are kept while the files share a project
+		found := FindProject(GetDir(absPath))
+		key := ""
+		if found != nil {
+			key = found.Path
+		}
+		if i == 0 || key != project {
+			goBinding.SetProject(found)
+			if _, err := goBinding.InitGoModToCache(); err != nil {
+				RaiseSystemError(err.Error())
+			}
+			project = key
+		}
+		files := ForwardDeclairation(state, absPath, parser.Tokenizer.Data, ast)
+		state.SetFile(files)
+		Diagnostics.Check()
+		for _, file := range files {
+			CreateAnalyzer(state, file).Analyze()
+		}
+		Diagnostics.Check()
+		// Imported modules are linted when they are named themselves
+		for _, file := range files {
+			if file.Path == absPath {
+				CreateLinter(file, state.Index).Lint()
+			}
+		}
+	}
+	Diagnostics.Check()
+	Diagnostics.Print()
+}
+
+func declaresMain(program *TAst) bool {
+	for _, node := range program.AstArr0 {
+		if node.Ttype == AstFunction && node.Ast0.Str0 == "main" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	gotypes "go/types"
+	"strings"
+	"testing"
+)
+
+// Type checks Go packages of one file each, keyed by their path, and
+// returns their exported functions.
+func goFunctions(t *testing.T, sources map[string]string) []*gotypes.Func {
+	functions := make([]*gotypes.Func, 0)
+	for path, source := range sources {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, path+".go", source, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkg, err := new(gotypes.Config).Check(path, fset, []*ast.File{file}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range pkg.Scope().Names() {
+			if function, ok := pkg.Scope().Lookup(name).(*gotypes.Func); ok && function.Exported() {
+				functions = append(functions, function)
+			}
+		}
+	}
+	return functions
+}
+
+// Analyzes source as the main file of a program and lints it with the
+// rule of check alone. The functions of goSources are declared in the
+// file as if imported from Go, without loading the packages of Go.
+// Returns "line:column: message" of each warning of the rule.
+func lintSource(t *testing.T, check string, source string, goSources map[string]string) []string {
+	keep := Diagnostics.Keep
+	Diagnostics.Keep = true
+	Diagnostics.Reset()
+	t.Cleanup(func() {
+		Diagnostics.Keep = keep
+		Diagnostics.Reset()
+	})
+	var rule TLintRule
+	for _, each := range LintRules {
+		if each.Check == check {
+			rule = each
+		}
+	}
+	state := CreateState()
+	state.Index = CreateIndex()
+	Recovering(func() {
+		parser := CreateParser("main.ns", source)
+		program := parser.Parse()
+		if Diagnostics.HasErrors() {
+			return
+		}
+		files := ForwardDeclairation(state, "main.ns", parser.Tokenizer.Data, program)
+		state.SetFile(files)
+		if Diagnostics.HasErrors() {
+			return
+		}
+		for _, function := range goFunctions(t, goSources) {
+			files[0].Env.AddSymbol(TSymbol{
+				Name:         function.Name(),
+				NameSpace:    function.Pkg().Name() + "." + function.Name(),
+				DataType:     state.GoTypes.FromObject(function),
+				IsGlobal:     true,
+				IsConst:      true,
+				IsUsed:       true,
+				IsInitialize: true,
+				File:         "main.ns",
+				Object:       function,
+			})
+		}
+		for _, file := range files {
+			CreateAnalyzer(state, file).Analyze()
+		}
+		if Diagnostics.HasErrors() {
+			return
+		}
+		for _, file := range files {
+			if file.Path == "main.ns" {
+				linter := &TLinter{file: file, index: state.Index, rules: []TLintRule{rule}}
+				linter.Lint()
+			}
+		}
+	})
+	reported := make([]string, 0)
+	for _, item := range Diagnostics.Items {
+		if item.Severity == SEVERITY_ERROR {
+			t.Fatalf("%d:%d: %s", item.Position.SLine, item.Position.SColm, item.Message)
+		}
+		if item.Code == rule.Code {
+			reported = append(reported, fmt.Sprintf("%d:%d: %s", item.Position.SLine, item.Position.SColm, item.Message))
+		}
+	}
+	return reported
+}
+
+// Wraps statements in a main function, the first of them at line 2.
+func inMain(statements ...string) string {
+	return "function main(args [str]) i32 {\n" + strings.Join(statements, "\n") + "\nreturn 0;\n}\n"
+}
+
+func TestLintRules(t *testing.T) {
+	tests := []struct {
+		name      string
+		check     string
+		source    string
+		goSources map[string]string
+		expected  []string
+	}{
+		{
+			"naming of declarations", CHECK_NAMING,
+			strings.Join([]string{
+				"struct point { x i32; }",
+				"var count i32 = 0;",
+				"const limit i32 = 3;",
+				"function Helper(A i32, _ i32) i32 { return A; }",
+				"function (p point) show() void { println(p.x); }",
+				inMain("local p point = point{x: 1};", "p.show();", "println(count, Helper(limit, 0));"),
+			}, "\n"),
+			nil,
+			[]string{
+				"1:8: invalid struct name, struct name must be in a form of pascal case",
+				"1:16: invalid attribute name, struct attribute must be in a form of pascal case",
+				"2:5: invalid variable name, global variable must be in a form of pascal case",
+				"3:7: invalid constant name, global constant name must be in a form of pascal case",
+				"4:10: invalid function name, function name must be in a form of camel case",
+				"4:17: invalid parameter name, parameter name must be in a form of camel case",
+				"5:20: invalid method name, method name must be in a form of pascal case",
+			},
+		},
+		{
+			"naming of locals", CHECK_NAMING,
+			inMain("local Total i32 = 1;", "const Step i32 = 2;", "local _ i32 = 3;", "println(Total, Step);"),
+			nil,
+			[]string{
+				"2:7: invalid variable name, local variable must be in a form of camel case",
+				"3:7: invalid constant name, local constant name must be in a form of camel case",
+			},
+		},
+		{
+			"well named", CHECK_NAMING,
+			strings.Join([]string{
+				"struct Point { X i32; }",
+				"const Limit i32 = 3;",
+				"function helper(a i32) i32 { return a; }",
+				"function (p Point) Show() void { println(p.X); }",
+				inMain("local p Point = Point{X: helper(Limit)};", "p.Show();"),
+			}, "\n"),
+			nil,
+			[]string{},
+		},
+		{
+			"shadowed locals", CHECK_SHADOW,
+			inMain(
+				"local total i32 = 0;",
+				"if (total == 0) {",
+				"    local total i32 = 1;",
+				"    println(total);",
+				"}",
+				"f := function(total i32) void { println(total); };",
+				"f(total);",
+			),
+			nil,
+			[]string{
+				"4:11: total shadows a local of an enclosing block",
+				"7:15: total shadows a local of an enclosing block",
+			},
+		},
+		{
+			"shadowed globals", CHECK_SHADOW,
+			"var Total i32 = 0;\n" + inMain("local Total i32 = 1;", "println(Total);"),
+			nil,
+			[]string{},
+		},
+		{
+			"unreachable statements", CHECK_UNREACHABLE,
+			inMain(
+				"for (local j i32 = 0; j < 2; j++) {",
+				"    if (j > 0) {",
+				"        break;",
+				"        println(\"never\");",
+				"    }",
+				"}",
+			) + "function stop() void {\n    return;\n    println(\"after\");\n}\n",
+			nil,
+			[]string{
+				"5:9: unreachable code, statement follows break",
+				"12:5: unreachable code, statement follows return",
+			},
+		},
+		{
+			"reachable statements", CHECK_UNREACHABLE,
+			inMain("if (args.Length() > 0) {", "    return 1;", "}", "println(\"reached\");"),
+			nil,
+			[]string{},
+		},
+		{
+			"redundant conditions", CHECK_REDUNDANT,
+			inMain(
+				"if (true) {",
+				"    println(\"always\");",
+				"}",
+				"local v i32 = if (!true) 1 else 2;",
+				"if (!!false) {",
+				"    println(v);",
+				"}",
+				"if (args.Length() > 0) {",
+				"    println(v);",
+				"}",
+			),
+			nil,
+			[]string{
+				"2:5: redundant if, condition is always true",
+				"5:20: redundant if, condition is always false",
+				"6:7: redundant if, condition is always false",
+			},
+		},
+		{
+			"captured loop variables", CHECK_CAPTURE,
+			"function work(n i32) void {\n    println(n);\n}\n" + inMain(
+				"local total i32 = 0;",
+				"for (local i i32 = 0; i < 3; i++) {",
+				"    total += i;",
+				"    run function() void { println(i, total); }();",
+				"    run function(n i32) void { println(n); }(i);",
+				"    run function() void { local j i32 = 0; println(j); }();",
+				"    run work(i);",
+				"}",
+			),
+			nil,
+			[]string{
+				"8:35: run captures loop variable i, which may change before the task reads it",
+				"8:38: run captures loop variable total, which may change before the task reads it",
+			},
+		},
+		{
+			"run outside a loop", CHECK_CAPTURE,
+			inMain("local i i32 = 1;", "run function() void { println(i); }();"),
+			nil,
+			[]string{},
+		},
+		{
+			"unchecked errors", CHECK_UNCHECKED,
+			inMain(
+				"Atoi(\"1\");",
+				"Println(\"checked\");",
+				"n, err := Atoi(\"2\");",
+				"println(n, err);",
+			),
+			map[string]string{
+				"strconv": "package strconv\nfunc Atoi(s string) (int, error) { return 0, nil }\n",
+				"fmt":     "package fmt\nfunc Println(a ...any) (int, error) { return 0, nil }\n",
+			},
+			[]string{
+				"2:1: unchecked error, the error Atoi returns is ignored",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reported := lintSource(t, test.check, test.source, test.goSources)
+			if strings.Join(reported, "\n") != strings.Join(test.expected, "\n") {
+				t.Errorf("reported:\n%s\nexpected:\n%s", strings.Join(reported, "\n"), strings.Join(test.expected, "\n"))
+			}
+		})
+	}
+}
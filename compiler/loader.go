@@ -12,9 +12,11 @@ const (
 )
 
 const (
-	FMT_PRINTLN  = "Println"
-	FMT_PRINT    = "Print"
-	GLOBAL_PANIC = "panic"
+	FMT_PRINTLN    = "Println"
+	FMT_PRINT      = "Print"
+	GLOBAL_PANIC   = "panic"
+	GLOBAL_CLOSE   = "close"
+	GLOBAL_TIMEOUT = "timeout"
 )
 
 func Load(env *TEnv) {
@@ -46,4 +48,22 @@ func Load(env *TEnv) {
 		MODULE_GLOBAL,
 		types.TFunc(true, []*types.TPair{types.CreatePair("value", types.TAny())}, types.TVoid(), true),
 	)
+
+	// Define the close function, its channel argument is checked by the analyzer
+	DefineSymbol(
+		env,
+		GLOBAL_CLOSE,
+		GLOBAL_CLOSE,
+		MODULE_GLOBAL,
+		types.TFunc(false, []*types.TPair{types.CreatePair("channel", types.TAny())}, types.TVoid(), false),
+	)
+
+	// Define the timeout function, which is only a case of a select
+	DefineSymbol(
+		env,
+		GLOBAL_TIMEOUT,
+		GLOBAL_TIMEOUT,
+		MODULE_GLOBAL,
+		types.TFunc(false, []*types.TPair{types.CreatePair("milliseconds", types.TInt64())}, types.TVoid(), false),
+	)
 }
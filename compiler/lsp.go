@@ -0,0 +1,840 @@
+package main
+
+import (
+	"bufio"
+	"dev/types"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Language server
+// "parrot lsp" serves the Language Server Protocol over stdio. Each open
+// document is compiled as a program on every change, up to the analysis,
+// and its diagnostics are published for the document and the modules it
+// imports. Hovers, definitions and completions are answered from the
+// names the analyzer resolved, see TIndex.
+//
+// Lines and characters of the protocol count from 0, the ones of the
+// compiler from 1. Characters are counted as runes, which are UTF-16
+// units for everything outside of the astral planes.
+
+// Kinds of the protocol, only the ones the server sends.
+const (
+	LSP_SEVERITY_ERROR   = 1
+	LSP_SEVERITY_WARNING = 2
+
+	LSP_COMPLETION_METHOD   = 2
+	LSP_COMPLETION_FUNCTION = 3
+	LSP_COMPLETION_FIELD    = 5
+	LSP_COMPLETION_VARIABLE = 6
+	LSP_COMPLETION_MODULE   = 9
+	LSP_COMPLETION_KEYWORD  = 14
+	LSP_COMPLETION_STRUCT   = 22
+	LSP_COMPLETION_CONSTANT = 21
+
+	LSP_SYMBOL_METHOD   = 6
+	LSP_SYMBOL_FIELD    = 8
+	LSP_SYMBOL_FUNCTION = 12
+	LSP_SYMBOL_VARIABLE = 13
+	LSP_SYMBOL_CONSTANT = 14
+	LSP_SYMBOL_STRUCT   = 23
+
+	LSP_SYNC_FULL = 1
+
+	LSP_METHOD_NOT_FOUND = -32601
+	LSP_INTERNAL_ERROR   = -32603
+)
+
+type TLspMessage struct {
+	Jsonrpc string           `json:"jsonrpc"`
+	Id      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+type TLspResponse struct {
+	Jsonrpc string           `json:"jsonrpc"`
+	Id      *json.RawMessage `json:"id"`
+	Result  any              `json:"result"`
+}
+
+type TLspErrorResponse struct {
+	Jsonrpc string           `json:"jsonrpc"`
+	Id      *json.RawMessage `json:"id"`
+	Error   TLspError        `json:"error"`
+}
+
+type TLspError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type TLspNotification struct {
+	Jsonrpc string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params"`
+}
+
+type TLspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type TLspRange struct {
+	Start TLspPosition `json:"start"`
+	End   TLspPosition `json:"end"`
+}
+
+type TLspLocation struct {
+	Uri   string    `json:"uri"`
+	Range TLspRange `json:"range"`
+}
+
+type TLspTextDocument struct {
+	Uri  string `json:"uri"`
+	Text string `json:"text"`
+}
+
+type TLspDocumentParams struct {
+	TextDocument   TLspTextDocument `json:"textDocument"`
+	Position       TLspPosition     `json:"position"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+type TLspDiagnostic struct {
+	Range    TLspRange `json:"range"`
+	Severity int       `json:"severity"`
+	Code     string    `json:"code"`
+	Source   string    `json:"source"`
+	Message  string    `json:"message"`
+}
+
+type TLspPublishDiagnostics struct {
+	Uri         string           `json:"uri"`
+	Diagnostics []TLspDiagnostic `json:"diagnostics"`
+}
+
+type TLspMarkup struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+type TLspHover struct {
+	Contents TLspMarkup `json:"contents"`
+	Range    TLspRange  `json:"range"`
+}
+
+type TLspCompletionItem struct {
+	Label  string `json:"label"`
+	Kind   int    `json:"kind"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type TLspCompletionList struct {
+	IsIncomplete bool                 `json:"isIncomplete"`
+	Items        []TLspCompletionItem `json:"items"`
+}
+
+type TLspDocumentSymbol struct {
+	Name           string               `json:"name"`
+	Detail         string               `json:"detail,omitempty"`
+	Kind           int                  `json:"kind"`
+	Range          TLspRange            `json:"range"`
+	SelectionRange TLspRange            `json:"selectionRange"`
+	Children       []TLspDocumentSymbol `json:"children,omitempty"`
+}
+
+// A document compiled as a program, with the modules it imports.
+type TProgram struct {
+	Path        string
+	Ast         *TAst
+	Files       []TFileJob // Empty if the program did not get to the analysis
+	Index       *TIndex
+	Diagnostics []TDiagnostic
+}
+
+type TLanguageServer struct {
+	goBinding *TGoBinding
+	reader    *bufio.Reader
+	writer    io.Writer
+	documents map[string]string    // Text of the open documents, by path
+	programs  map[string]*TProgram // Last program of each open document that got to the analysis
+	parsed    map[string]*TAst     // Last syntax tree of each open document
+	published map[string][]string  // Files the diagnostics of each open document were published for
+	project   *string              // Project file the Go module is set up for, nil before the first
+	builtins  *TEnv
+	shutdown  bool
+}
+
+func CreateLanguageServer(goBinding *TGoBinding, reader io.Reader, writer io.Writer) *TLanguageServer {
+	builtins := CreateEnv(nil)
+	Load(builtins)
+	return &TLanguageServer{
+		goBinding: goBinding,
+		reader:    bufio.NewReader(reader),
+		writer:    writer,
+		documents: make(map[string]string),
+		programs:  make(map[string]*TProgram),
+		parsed:    make(map[string]*TAst),
+		published: make(map[string][]string),
+		builtins:  builtins,
+	}
+}
+
+// Serves requests until the client exits. Returns the exit code.
+func (server *TLanguageServer) Serve() int {
+	for {
+		message, err := server.read()
+		if err == io.EOF {
+			return 1
+		}
+		if err != nil {
+			server.log(err.Error())
+			return 1
+		}
+		if message.Method == "exit" {
+			if server.shutdown {
+				return 0
+			}
+			return 1
+		}
+		server.handle(message)
+	}
+}
+
+// Reads a message framed by a Content-Length header.
+func (server *TLanguageServer) read() (TLspMessage, error) {
+	length := -1
+	for {
+		line, err := server.reader.ReadString('\n')
+		if err != nil {
+			return TLspMessage{}, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		if name, value, ok := strings.Cut(line, ":"); ok && strings.EqualFold(name, "Content-Length") {
+			length, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return TLspMessage{}, fmt.Errorf("invalid Content-Length %s", value)
+			}
+		}
+	}
+	if length < 0 {
+		return TLspMessage{}, fmt.Errorf("message without Content-Length")
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(server.reader, data); err != nil {
+		return TLspMessage{}, err
+	}
+	var message TLspMessage
+	if err := json.Unmarshal(data, &message); err != nil {
+		return TLspMessage{}, err
+	}
+	return message, nil
+}
+
+func (server *TLanguageServer) write(message any) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		server.log(err.Error())
+		return
+	}
+	fmt.Fprintf(server.writer, "Content-Length: %d\r\n\r\n%s", len(data), data)
+}
+
+func (server *TLanguageServer) notify(method string, params any) {
+	server.write(TLspNotification{Jsonrpc: "2.0", Method: method, Params: params})
+}
+
+func (server *TLanguageServer) log(message string) {
+	fmt.Fprintf(os.Stderr, "parrot lsp: %s\n", message)
+}
+
+// Answers a request or handles a notification. A failure of the compiler
+// fails the request, the server goes on.
+func (server *TLanguageServer) handle(message TLspMessage) {
+	defer func() {
+		if value := recover(); value != nil {
+			reason := fmt.Sprint(value)
+			if systemError, ok := value.(TSystemError); ok {
+				reason = systemError.Message
+			}
+			server.log(fmt.Sprintf("%s failed: %s", message.Method, reason))
+			Diagnostics.Reset()
+			if message.Id != nil {
+				server.write(TLspErrorResponse{Jsonrpc: "2.0", Id: message.Id, Error: TLspError{Code: LSP_INTERNAL_ERROR, Message: reason}})
+			}
+		}
+	}()
+	var params TLspDocumentParams
+	if len(message.Params) > 0 {
+		// Requests the server does not know may have other parameters
+		_ = json.Unmarshal(message.Params, &params)
+	}
+	path := uriPath(params.TextDocument.Uri)
+	var result any
+	switch message.Method {
+	case "initialize":
+		result = map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync": map[string]any{
+					"openClose": true,
+					"change":    LSP_SYNC_FULL,
+				},
+				"hoverProvider":          true,
+				"definitionProvider":     true,
+				"documentSymbolProvider": true,
+				"completionProvider": map[string]any{
+					"triggerCharacters": []string{"."},
+				},
+			},
+			"serverInfo": map[string]any{"name": "parrot"},
+		}
+	case "shutdown":
+		server.shutdown = true
+	case "textDocument/didOpen":
+		server.documents[path] = params.TextDocument.Text
+		server.check(path)
+	case "textDocument/didChange":
+		if len(params.ContentChanges) > 0 {
+			server.documents[path] = params.ContentChanges[len(params.ContentChanges)-1].Text
+		}
+		server.check(path)
+	case "textDocument/didClose":
+		delete(server.documents, path)
+		delete(server.programs, path)
+		delete(server.parsed, path)
+		for _, file := range server.published[path] {
+			server.publish(file, nil)
+		}
+		delete(server.published, path)
+	case "textDocument/hover":
+		result = server.hover(path, params.Position)
+	case "textDocument/definition":
+		result = server.definition(path, params.Position)
+	case "textDocument/completion":
+		result = server.completion(path, params.Position)
+	case "textDocument/documentSymbol":
+		result = server.documentSymbols(path)
+	default:
+		if message.Id != nil && !strings.HasPrefix(message.Method, "$/") {
+			server.write(TLspErrorResponse{Jsonrpc: "2.0", Id: message.Id, Error: TLspError{Code: LSP_METHOD_NOT_FOUND, Message: "unsupported method " + message.Method}})
+		}
+		return
+	}
+	if message.Id != nil {
+		server.write(TLspResponse{Jsonrpc: "2.0", Id: message.Id, Result: result})
+	}
+}
+
+// Compiles the document at path up to the analysis and publishes the
+// diagnostics of the files of the program.
+func (server *TLanguageServer) check(path string) {
+	program := server.compile(path)
+	server.parsed[path] = program.Ast
+	if len(program.Files) > 0 {
+		server.programs[path] = program
+	}
+	files := []string{path}
+	for _, file := range program.Files {
+		if file.Path != path {
+			files = append(files, file.Path)
+		}
+	}
+	for _, diagnostic := range program.Diagnostics {
+		if !contains(files, diagnostic.File) {
+			files = append(files, diagnostic.File)
+		}
+	}
+	// A module that is no longer imported keeps no diagnostics of this one
+	for _, file := range server.published[path] {
+		if !contains(files, file) {
+			server.publish(file, nil)
+		}
+	}
+	for _, file := range files {
+		diagnostics := make([]TDiagnostic, 0)
+		for _, diagnostic := range program.Diagnostics {
+			if diagnostic.File == file {
+				diagnostics = append(diagnostics, diagnostic)
+			}
+		}
+		server.publish(file, diagnostics)
+	}
+	server.published[path] = files
+}
+
+// Runs the phases of processFile on the open text of the document,
+// without generating any Go.
+func (server *TLanguageServer) compile(path string) *TProgram {
+	Diagnostics.Reset()
+	program := &TProgram{Path: path, Index: CreateIndex()}
+	state := CreateState()
+	for file, text := range server.documents {
+		state.Sources[file] = text
+	}
+	state.Index = program.Index
+	if libPath, err := server.goBinding.GetLib(); err == nil {
+		state.LibPath = libPath
+	}
+	Recovering(func() {
+		parser := CreateParser(path, server.documents[path])
+		program.Ast = parser.Parse()
+		if Diagnostics.HasErrors() {
+			return
+		}
+		server.setProject(GetDir(path))
+		files := ForwardDeclairation(state, path, parser.Tokenizer.Data, program.Ast)
+		state.SetFile(files)
+		if Diagnostics.HasErrors() {
+			return
+		}
+		program.Files = files
+		for _, file := range files {
+			CreateAnalyzer(state, file).Analyze()
+		}
+		// The rules of parrot lint run on an analysis without errors
+		if Diagnostics.HasErrors() {
+			return
+		}
+		for _, file := range files {
+			if file.Path == path {
+				CreateLinter(file, program.Index).Lint()
+			}
+		}
+	})
+	program.Diagnostics = append(program.Diagnostics, Diagnostics.Items...)
+	Diagnostics.Reset()
+	return program
+}
+
+// Sets the Go module up for the project of the document, go: imports
+// are type checked against it. Loaded packages are kept until the
+// project changes.
+func (server *TLanguageServer) setProject(dir string) {
+	project := FindProject(dir)
+	key := ""
+	if project != nil {
+		key = project.Path
+	}
+	if server.project != nil && *server.project == key {
+		return
+	}
+	server.goBinding.SetProject(project)
+	if _, err := server.goBinding.InitGoModToCache(); err != nil {
+		RaiseSystemError(err.Error())
+	}
+	server.project = &key
+}
+
+func (server *TLanguageServer) publish(file string, diagnostics []TDiagnostic) {
+	items := make([]TLspDiagnostic, 0, len(diagnostics))
+	for _, diagnostic := range diagnostics {
+		severity := LSP_SEVERITY_ERROR
+		if diagnostic.Severity == SEVERITY_WARNING {
+			severity = LSP_SEVERITY_WARNING
+		}
+		message := diagnostic.Message
+		for _, note := range diagnostic.Notes {
+			message += "\nnote: " + note
+		}
+		items = append(items, TLspDiagnostic{
+			Range:    diagnosticRange(diagnostic),
+			Severity: severity,
+			Code:     string(diagnostic.Code),
+			Source:   "parrot",
+			Message:  message,
+		})
+	}
+	server.notify("textDocument/publishDiagnostics", TLspPublishDiagnostics{
+		Uri:         pathUri(file),
+		Diagnostics: items,
+	})
+}
+
+func (server *TLanguageServer) hover(path string, position TLspPosition) any {
+	program, ok := server.programs[path]
+	if !ok {
+		return nil
+	}
+	reference, ok := program.Index.At(path, position.Line+1, position.Character+1)
+	if !ok || reference.DataType == nil {
+		return nil
+	}
+	text := fmt.Sprintf("%s %s", reference.Name, reference.DataType.ScriptString())
+	if types.IsInvalid(reference.DataType) {
+		// Its declaration failed, the error says why
+		text = reference.Name
+	}
+	if reference.Owner != nil && reference.Owner.HasMethod(reference.Name) {
+		text = "(method) " + text
+	} else if reference.Owner != nil {
+		text = "(field) " + text
+	} else if reference.Symbol != nil && reference.Symbol.Members != nil {
+		text = fmt.Sprintf("import %s", reference.Name)
+	}
+	return TLspHover{
+		Contents: TLspMarkup{Kind: "markdown", Value: "```parrot\n" + text + "\n```"},
+		Range:    nameRange(reference.Position, reference.Name),
+	}
+}
+
+func (server *TLanguageServer) definition(path string, position TLspPosition) any {
+	program, ok := server.programs[path]
+	if !ok {
+		return nil
+	}
+	reference, ok := program.Index.At(path, position.Line+1, position.Character+1)
+	if !ok {
+		return nil
+	}
+	location, ok := program.Index.Definition(reference)
+	if !ok {
+		return nil
+	}
+	start := TLspPosition{Line: location.Line - 1, Character: location.Column - 1}
+	return TLspLocation{
+		Uri:   pathUri(goSourcePath(location.File)),
+		Range: TLspRange{Start: start, End: start},
+	}
+}
+
+// Completes the members and methods of the value before a ".", or the
+// names in scope and the keywords anywhere else.
+func (server *TLanguageServer) completion(path string, position TLspPosition) any {
+	list := TLspCompletionList{Items: make([]TLspCompletionItem, 0)}
+	program, ok := server.programs[path]
+	if !ok {
+		return list
+	}
+	lines := strings.Split(server.documents[path], "\n")
+	if position.Line >= len(lines) {
+		return list
+	}
+	line := []rune(lines[position.Line])
+	before := line[:min(position.Character, len(line))]
+	// The name being typed is filtered by the editor
+	end := len(before)
+	for end > 0 && isNameRune(before[end-1]) {
+		end--
+	}
+	if end > 0 && before[end-1] == '.' {
+		return server.memberCompletion(program, path, position.Line+1, before[:end-1])
+	}
+	seen := make(map[string]bool)
+	add := func(symbol TSymbol) {
+		if seen[symbol.Name] || symbol.Name == "" {
+			return
+		}
+		seen[symbol.Name] = true
+		list.Items = append(list.Items, TLspCompletionItem{
+			Label:  symbol.Name,
+			Kind:   completionKind(symbol),
+			Detail: symbolDetail(symbol),
+		})
+	}
+	for _, declaration := range server.localsAt(program, path, position.Line+1) {
+		add(*declaration.Symbol)
+	}
+	if env := programEnv(program, path); env != nil {
+		for _, symbol := range env.Symbols {
+			add(symbol)
+		}
+	}
+	for _, symbol := range server.builtins.Symbols {
+		add(symbol)
+	}
+	for _, keyword := range Keywords {
+		if !seen[keyword] {
+			seen[keyword] = true
+			list.Items = append(list.Items, TLspCompletionItem{Label: keyword, Kind: LSP_COMPLETION_KEYWORD})
+		}
+	}
+	return list
+}
+
+// Completes after "object.", where object is a chain of names such as
+// "point.Position". The chain is looked up by name, the text of the
+// document is usually not valid while the member is typed.
+func (server *TLanguageServer) memberCompletion(program *TProgram, path string, line int, text []rune) TLspCompletionList {
+	list := TLspCompletionList{Items: make([]TLspCompletionItem, 0)}
+	start := len(text)
+	for start > 0 && (isNameRune(text[start-1]) || text[start-1] == '.') {
+		start--
+	}
+	names := strings.Split(string(text[start:]), ".")
+	if len(names) == 0 || names[0] == "" {
+		return list
+	}
+	symbol, ok := server.lookup(program, path, line, names[0])
+	if !ok {
+		return list
+	}
+	dataType := symbol.DataType
+	for _, name := range names[1:] {
+		if symbol.Members != nil {
+			if !symbol.Members.HasLocalSymbol(name) {
+				return list
+			}
+			symbol = symbol.Members.GetSymbol(name)
+			dataType = symbol.DataType
+			continue
+		}
+		member := dataType.GetMember(name)
+		if member == nil {
+			return list
+		}
+		symbol = TSymbol{}
+		dataType = member.DataType
+	}
+	if symbol.Members != nil {
+		for _, member := range symbol.Members.Symbols {
+			if member.Members == nil && !strings.Contains(member.Name, ".") {
+				list.Items = append(list.Items, TLspCompletionItem{
+					Label:  member.Name,
+					Kind:   completionKind(member),
+					Detail: symbolDetail(member),
+				})
+			}
+		}
+		return list
+	}
+	if dataType == nil || types.IsInvalid(dataType) {
+		return list
+	}
+	for _, member := range dataType.GetMembers() {
+		list.Items = append(list.Items, TLspCompletionItem{
+			Label:  member.Name,
+			Kind:   LSP_COMPLETION_FIELD,
+			Detail: member.DataType.ScriptString(),
+		})
+	}
+	for _, method := range dataType.GetMethods() {
+		list.Items = append(list.Items, TLspCompletionItem{
+			Label:  method.Name,
+			Kind:   LSP_COMPLETION_METHOD,
+			Detail: method.DataType.ScriptString(),
+		})
+	}
+	return list
+}
+
+// Looks a name up in the locals in scope at line, then in the module.
+func (server *TLanguageServer) lookup(program *TProgram, path string, line int, name string) (TSymbol, bool) {
+	locals := server.localsAt(program, path, line)
+	for i := len(locals) - 1; i >= 0; i-- {
+		if locals[i].Name == name {
+			return *locals[i].Symbol, true
+		}
+	}
+	if env := programEnv(program, path); env != nil && env.HasGlobalSymbol(name) {
+		return env.GetSymbol(name), true
+	}
+	return TSymbol{}, false
+}
+
+// Returns the locals declared before line in the declaration of the
+// document that line is in.
+func (server *TLanguageServer) localsAt(program *TProgram, path string, line int) []TReference {
+	ast := server.parsed[path]
+	if ast == nil {
+		ast = program.Ast
+	}
+	var enclosing *TAst
+	for _, child := range ast.AstArr0 {
+		if child.Position.SLine <= line && line <= child.Position.ELine {
+			enclosing = child
+		}
+	}
+	locals := make([]TReference, 0)
+	if enclosing == nil {
+		return locals
+	}
+	for _, declaration := range program.Index.DeclaredBefore(path, line) {
+		if declaration.Position.SLine >= enclosing.Position.SLine && !declaration.Symbol.IsGlobal {
+			locals = append(locals, declaration)
+		}
+	}
+	return locals
+}
+
+// Lists the structs, functions, methods, variables and constants the
+// document declares, from its last syntax tree.
+func (server *TLanguageServer) documentSymbols(path string) any {
+	symbols := make([]TLspDocumentSymbol, 0)
+	ast := server.parsed[path]
+	if ast == nil {
+		return symbols
+	}
+	for _, child := range ast.AstArr0 {
+		switch child.Ttype {
+		case AstStruct:
+			if child.Ast0 == nil || child.Ast0.Ttype != AstIDN {
+				continue
+			}
+			symbol := declarationSymbol(child, child.Ast0, LSP_SYMBOL_STRUCT)
+			for _, attribute := range child.AstArr0 {
+				if attribute.Ttype == AstIDN {
+					symbol.Children = append(symbol.Children, declarationSymbol(attribute, attribute, LSP_SYMBOL_FIELD))
+				}
+			}
+			symbols = append(symbols, symbol)
+		case AstFunction, AstMethod:
+			if child.Ast0 == nil || child.Ast0.Ttype != AstIDN {
+				continue
+			}
+			kind := LSP_SYMBOL_FUNCTION
+			if child.Ttype == AstMethod {
+				kind = LSP_SYMBOL_METHOD
+			}
+			symbols = append(symbols, declarationSymbol(child, child.Ast0, kind))
+		case AstVar, AstConst:
+			kind := LSP_SYMBOL_VARIABLE
+			if child.Ttype == AstConst {
+				kind = LSP_SYMBOL_CONSTANT
+			}
+			for _, name := range child.AstArr0 {
+				if name.Ttype == AstIDN {
+					symbols = append(symbols, declarationSymbol(child, name, kind))
+				}
+			}
+		}
+	}
+	return symbols
+}
+
+func declarationSymbol(node *TAst, name *TAst, kind int) TLspDocumentSymbol {
+	selection := nameRange(name.Position, name.Str0)
+	whole := TLspRange{
+		Start: TLspPosition{Line: node.Position.SLine - 1, Character: node.Position.SColm - 1},
+		End:   TLspPosition{Line: node.Position.ELine - 1, Character: node.Position.EColm - 1},
+	}
+	// The range of a declaration has to contain the range of its name
+	if whole.End.Line < selection.End.Line || (whole.End.Line == selection.End.Line && whole.End.Character < selection.End.Character) {
+		whole.End = selection.End
+	}
+	return TLspDocumentSymbol{Name: name.Str0, Kind: kind, Range: whole, SelectionRange: selection}
+}
+
+// Returns the module scope of a file of the program.
+func programEnv(program *TProgram, path string) *TEnv {
+	for _, file := range program.Files {
+		if file.Path == path {
+			return file.Env
+		}
+	}
+	return nil
+}
+
+func completionKind(symbol TSymbol) int {
+	switch {
+	case symbol.Members != nil:
+		return LSP_COMPLETION_MODULE
+	case symbol.DataType == nil:
+		return LSP_COMPLETION_VARIABLE
+	case types.IsStruct(symbol.DataType):
+		return LSP_COMPLETION_STRUCT
+	case types.IsFunc(symbol.DataType) && symbol.IsGlobal:
+		return LSP_COMPLETION_FUNCTION
+	case symbol.IsConst && symbol.IsGlobal:
+		return LSP_COMPLETION_CONSTANT
+	default:
+		return LSP_COMPLETION_VARIABLE
+	}
+}
+
+func symbolDetail(symbol TSymbol) string {
+	if symbol.Members != nil || symbol.DataType == nil || types.IsInvalid(symbol.DataType) {
+		return ""
+	}
+	return symbol.DataType.ScriptString()
+}
+
+func isNameRune(char rune) bool {
+	return char == '_' || unicode.IsLetter(char) || unicode.IsDigit(char)
+}
+
+// Returns the range of a name that starts at position.
+func nameRange(position TPosition, name string) TLspRange {
+	start := TLspPosition{Line: position.SLine - 1, Character: position.SColm - 1}
+	return TLspRange{Start: start, End: TLspPosition{Line: start.Line, Character: start.Character + len([]rune(name))}}
+}
+
+// Returns the range of a diagnostic. Most positions are the start of a
+// token only, those cover the word that starts there.
+func diagnosticRange(diagnostic TDiagnostic) TLspRange {
+	position := diagnostic.Position
+	start := TLspPosition{Line: max(position.SLine-1, 0), Character: max(position.SColm-1, 0)}
+	end := TLspPosition{Line: max(position.ELine-1, 0), Character: max(position.EColm-1, 0)}
+	if end.Line > start.Line || (end.Line == start.Line && end.Character > start.Character) {
+		return TLspRange{Start: start, End: end}
+	}
+	lines := strings.Split(string(diagnostic.Data), "\n")
+	end = TLspPosition{Line: start.Line, Character: start.Character + 1}
+	if start.Line < len(lines) {
+		line := []rune(lines[start.Line])
+		character := start.Character
+		for character < len(line) && isNameRune(line[character]) {
+			character++
+		}
+		if character > start.Character {
+			end.Character = character
+		}
+	}
+	return TLspRange{Start: start, End: end}
+}
+
+func contains(items []string, item string) bool {
+	for _, each := range items {
+		if each == item {
+			return true
+		}
+	}
+	return false
+}
+
+func pathUri(path string) string {
+	uri := url.URL{Scheme: "file", Path: filepath.ToSlash(path)}
+	return uri.String()
+}
+
+func uriPath(uri string) string {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Scheme != "file" {
+		return uri
+	}
+	return filepath.FromSlash(parsed.Path)
+}
+
+// Positions read from the export data of the standard library name
+// their files relative to $GOROOT.
+func goSourcePath(path string) string {
+	if rest, ok := strings.CutPrefix(path, "$GOROOT"); ok {
+		return filepath.Join(runtime.GOROOT(), rest)
+	}
+	return path
+}
+
+// Serves the language server on stdio. Anything the compiler prints
+// goes to stderr, stdout only carries the protocol.
+func languageServer(goBinding *TGoBinding) {
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	Diagnostics.Keep = true
+	server := CreateLanguageServer(goBinding, os.Stdin, stdout)
+	code := server.Serve()
+	CollectAndFree()
+	os.Exit(code)
+}
@@ -2,7 +2,10 @@ package main
 
 import (
 	"fmt"
+	"go/token"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -10,6 +13,12 @@ const (
 	FLAG_COMPILE = "compile"
 	FLAG_OUT     = "out"
 	FLAG_RUN     = "run"
+	FLAG_LIB     = "lib"
+	FLAG_PACKAGE = "package"
+	FLAG_MAX_ERR = "max-errors"
+	FLAG_DIAG    = "diagnostics"
+	FLAG_WARN    = "W"
+	FLAG_WERROR  = "Werror"
 )
 
 func parseArgs() map[string]string {
@@ -35,7 +44,12 @@ func parseArgs() map[string]string {
 
 		// Check if there's a value following this flag
 		if i+1 < len(args) && (len(args[i+1]) == 0 || args[i+1][0] != '-') {
-			result[key] = args[i+1]
+			if key == FLAG_WARN && result[key] != "" {
+				// -W may be repeated, one check each time
+				result[key] += "," + args[i+1]
+			} else {
+				result[key] = args[i+1]
+			}
 			i++ // Skip the value in the next iteration
 		} else {
 			result[key] = "true" // Flag without value
@@ -52,12 +66,51 @@ func showHelp() {
 	fmt.Println("  --compile <file>  Compile the specified file")
 	fmt.Println("  --out     <file>  Specify the output file for compilation")
 	fmt.Println("  --run     <file>  Run the specified file")
+	fmt.Println("  --lib     <file>  Emit the specified file as a Go package in the --out directory")
+	fmt.Println("  --package <name>  Specify the name of the Go package, the file name by default")
+	fmt.Println("  --max-errors <n>  Stop after n errors, 20 by default")
+	fmt.Println("  --diagnostics <f> Print errors as text, json or sarif, text by default")
+	fmt.Println("  -W <check=level>  Set a check (unused, panics or a lint rule) to off, warn or error")
+	fmt.Println("  --Werror          Report warnings as errors")
+	fmt.Println("  explain <code>    Explain an error code, such as NS0012")
+	fmt.Println("  fmt <files>       Format scripts in place, or with --check or --diff report those to format")
+	fmt.Println("  lint <files>      Check scripts against the rules naming, shadow, unreachable,")
+	fmt.Println("                    redundant, capture and unchecked, which -W sets")
+	fmt.Println("  lsp               Serve the Language Server Protocol on stdio")
 	fmt.Println("\nExamples:")
 	fmt.Println("  To compile:  parrot --compile myfile.ns --out output")
 	fmt.Println("  To run:      parrot --run myfile.ns")
+	fmt.Println("  To export:   parrot --lib mylib.ns --out ./mylib")
+	fmt.Println("  To explain:  parrot explain NS0012")
+	fmt.Println("  To format:   parrot fmt --check .")
+	fmt.Println("  To lint:     parrot lint -W shadow=error .")
+}
+
+// Sets how diagnostics are limited, printed and leveled from the options.
+func processDiagnosticArgs(args map[string]string) {
+	if maxErrors, ok := args[FLAG_MAX_ERR]; ok {
+		limit, err := strconv.Atoi(maxErrors)
+		if err != nil || limit <= 0 {
+			RaiseSystemError(fmt.Sprintf("error reading options, %s is not a valid error limit", maxErrors))
+		}
+		Diagnostics.Limit = limit
+	}
+	if output, ok := args[FLAG_DIAG]; ok {
+		if output != OUTPUT_TEXT && output != OUTPUT_JSON && output != OUTPUT_SARIF {
+			RaiseSystemError(fmt.Sprintf("error reading options, %s is not a diagnostics format, use text, json or sarif", output))
+		}
+		Diagnostics.Output = output
+	}
+	if levels, ok := args[FLAG_WARN]; ok {
+		if err := Diagnostics.SetLevels(levels); err != nil {
+			RaiseSystemError(fmt.Sprintf("error reading options, %s", err))
+		}
+	}
+	_, Diagnostics.Werror = args[FLAG_WERROR]
 }
 
 func processArgs(goBinding *TGoBinding, args map[string]string) {
+	processDiagnosticArgs(args)
 	if compileFile := args[FLAG_COMPILE]; compileFile != "true" && compileFile != "" {
 		output := args[FLAG_OUT]
 		if output == "true" || output == "" {
@@ -66,12 +119,28 @@ func processArgs(goBinding *TGoBinding, args map[string]string) {
 		compile(goBinding, compileFile, output)
 	} else if runFile := args[FLAG_RUN]; runFile != "true" && runFile != "" {
 		run(goBinding, runFile)
+	} else if libFile := args[FLAG_LIB]; libFile != "true" && libFile != "" {
+		output := args[FLAG_OUT]
+		if output == "true" || output == "" {
+			RaiseSystemError("error exporting library, output directory is not specified")
+		}
+		packageName := args[FLAG_PACKAGE]
+		if packageName == "true" || packageName == "" {
+			packageName = strings.ToLower(GetFileNameWithoutExtension(libFile))
+		}
+		if !token.IsIdentifier(packageName) || packageName == "main" {
+			RaiseSystemError(fmt.Sprintf("error exporting library, %s is not a valid package name", packageName))
+		}
+		library(goBinding, libFile, packageName, output)
 	} else {
 		showHelp()
 	}
 }
 
-func processFile(goBinding *TGoBinding, path string) {
+// Generates the Go files of the script at path into the cache, as the
+// package packageName, or as a program if it is empty. Returns the names
+// of the generated files.
+func processFile(goBinding *TGoBinding, path string, packageName string) []string {
 	// Read the file
 	absPath := ToAbsolutePath(path)
 	data, err := os.ReadFile(absPath)
@@ -101,30 +170,54 @@ func processFile(goBinding *TGoBinding, path string) {
 
 	// Create the state
 	tstate := CreateState()
+	tstate.Package = packageName
+
+	// Locate the standard library, a missing one only fails "lib:" imports
+	if libPath, err := goBinding.GetLib(); err == nil {
+		tstate.LibPath = libPath
+	}
 
 	// Parse the file
 	parser := CreateParser(absPath, string(data))
 	ast := parser.Parse()
+	Diagnostics.Check()
 
-	// Forward declaration
-	files := ForwardDeclairation(tstate, absPath, parser.Tokenizer.Data, ast)
-	tstate.SetFile(files)
-
-	// Initialize Go module
+	// Initialize Go module with the requirements of the project,
+	// go: imports are type checked against it
+	goBinding.SetProject(FindProject(GetDir(absPath)))
 	_, modInitErr := goBinding.InitGoModToCache()
 	if modInitErr != nil {
 		RaiseSystemError(modInitErr.Error())
 	}
 
-	// Analyze the files
-	for _, file := range files {
+	// Files of an earlier build would be compiled along with this one
+	if _, err := goBinding.ClearCache(); err != nil {
+		RaiseSystemError(fmt.Sprintf("error clearing cache: %s", err))
+	}
+
+	// Forward declaration
+	files := ForwardDeclairation(tstate, absPath, parser.Tokenizer.Data, ast)
+	tstate.SetFile(files)
+	Diagnostics.Check()
+
+	// Analyze the files, all of them report their errors before any is generated
+	sources := make([]string, len(files))
+	for i, file := range files {
 		analyzer := CreateAnalyzer(tstate, file)
-		src := analyzer.Analyze()
-		ok, err := goBinding.Generate(GetFileNameWithoutExtension(file.Path)+".go", src)
+		sources[i] = analyzer.Analyze()
+	}
+	Diagnostics.Check()
+	Diagnostics.Print()
+
+	generated := make([]string, 0, len(files)+3)
+	for i, file := range files {
+		ok, err := goBinding.Generate(GetFileNameWithoutExtension(file.Path)+".go", sources[i])
 		if err != nil || !ok {
 			RaiseSystemError(fmt.Sprintf("[%s]: error generating file %s", err.Error(), file.Path))
 		}
+		generated = append(generated, GetFileNameWithoutExtension(file.Path)+".go")
 	}
+	generated = append(generated, "arrays.go", "maps.go", "futures.go")
 
 	// Generate arrays
 	arrayCode := tstate.GenerateArrays()
@@ -137,6 +230,13 @@ func processFile(goBinding *TGoBinding, path string) {
 		RaiseSystemError(fmt.Sprintf("error generating maps.go: %s", err))
 	}
 
+	// Generate futures
+	futureCode := tstate.GenerateFutures()
+	ok, err = goBinding.Generate("futures.go", futureCode)
+	if err != nil || !ok {
+		RaiseSystemError(fmt.Sprintf("error generating futures.go: %s", err))
+	}
+
 	// Signal the goroutine to stop
 	done <- true
 
@@ -151,10 +251,11 @@ func processFile(goBinding *TGoBinding, path string) {
 
 	// Collect and free memory
 	CollectAndFree()
+	return generated
 }
 
 func compile(goBinding *TGoBinding, scriptPath string, output string) {
-	processFile(goBinding, scriptPath)
+	processFile(goBinding, scriptPath, "")
 
 	// Compile the cache
 	ok, err := goBinding.GoCompileCache(GetDir(ToAbsolutePath(scriptPath)), output)
@@ -168,7 +269,7 @@ func compile(goBinding *TGoBinding, scriptPath string, output string) {
 }
 
 func run(goBinding *TGoBinding, scriptPath string) {
-	processFile(goBinding, scriptPath)
+	processFile(goBinding, scriptPath, "")
 
 	// Run the cache
 	out, err := goBinding.GoRunCache()
@@ -178,7 +279,59 @@ func run(goBinding *TGoBinding, scriptPath string) {
 	fmt.Print(out)
 }
 
+func library(goBinding *TGoBinding, scriptPath string, packageName string, output string) {
+	generated := processFile(goBinding, scriptPath, packageName)
+
+	// Type check the package before it is handed to a Go module
+	if _, err := goBinding.GoBuildCache(); err != nil {
+		RaiseSystemError(fmt.Sprintf("error building library: %s", err))
+	}
+	if _, err := goBinding.ExportCache(generated, ToAbsolutePath(output)); err != nil {
+		RaiseSystemError(fmt.Sprintf("error exporting library: %s", err))
+	}
+	fmt.Printf("exported package %s\n", packageName)
+}
+
+// Prints the long-form explanation of each code, or lists the codes.
+func explain(codes []string) {
+	if len(codes) == 0 {
+		for _, code := range SortedErrorCodes() {
+			fmt.Printf("%s  %s\n", code, ErrorCodes[code].Title)
+		}
+		return
+	}
+	for _, code := range codes {
+		text, ok := ExplainErrorCode(code)
+		if !ok {
+			RaiseSystemError(fmt.Sprintf("unknown error code %s, run parrot explain to list them", code))
+		}
+		fmt.Print(text)
+	}
+}
+
 func main() {
+	// An error out of any recovering step still reports what is collected
+	defer func() {
+		if RecoverAbort(recover()) {
+			Diagnostics.Flush()
+		}
+	}()
+	if len(os.Args) > 1 && os.Args[1] == "explain" {
+		explain(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "fmt" {
+		format(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "lint" {
+		lint(CreateGo(), os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "lsp" {
+		languageServer(CreateGo())
+		return
+	}
 	// Parse and process arguments
 	processArgs(CreateGo(), parseArgs())
 }
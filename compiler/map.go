@@ -2,6 +2,7 @@ package main
 
 import (
 	"dev/types"
+	"fmt"
 	"strings"
 )
 
@@ -12,27 +13,32 @@ import (
 // It is also used to generate the map code.
 
 const MapCode string = `
-type Map{{KeyTypeName}}{{ValueType}} struct {
+type Map{{KeyTypeName}}{{ValueTypeName}} struct {
 	elements map[{{KeyType}}]{{ValueType}}
 }
-func NewMap{{KeyTypeName}}{{ValueType}}(elements map[{{KeyType}}]{{ValueType}}) *Map{{KeyTypeName}}{{ValueType}} {
-	mp := new(Map{{KeyTypeName}}{{ValueType}})
+func NewMap{{KeyTypeName}}{{ValueTypeName}}(elements map[{{KeyType}}]{{ValueType}}) *Map{{KeyTypeName}}{{ValueTypeName}} {
+	mp := new(Map{{KeyTypeName}}{{ValueTypeName}})
 	mp.elements = make(map[{{KeyType}}]{{ValueType}})
 	for key, value := range elements {
 		mp.elements[key] = value
 	}
 	return mp
 }
-func (mp *Map{{KeyTypeName}}{{ValueType}}) Get(key {{KeyType}}) {{ValueType}} {
+func WrapMap{{KeyTypeName}}{{ValueTypeName}}(elements map[{{KeyType}}]{{ValueType}}) *Map{{KeyTypeName}}{{ValueTypeName}} {
+	mp := new(Map{{KeyTypeName}}{{ValueTypeName}})
+	mp.elements = elements
+	return mp
+}
+func (mp *Map{{KeyTypeName}}{{ValueTypeName}}) Get(key {{KeyType}}) {{ValueType}} {
 	return mp.elements[key]
 }
-func (mp *Map{{KeyTypeName}}{{ValueType}}) Set(key {{KeyType}}, value {{ValueType}}) {
+func (mp *Map{{KeyTypeName}}{{ValueTypeName}}) Set(key {{KeyType}}, value {{ValueType}}) {
 	mp.elements[key] = value
 }
-func (mp *Map{{KeyTypeName}}{{ValueType}}) Delete(key {{KeyType}}) {
+func (mp *Map{{KeyTypeName}}{{ValueTypeName}}) Delete(key {{KeyType}}) {
 	delete(mp.elements, key)
 }
-func (mp *Map{{KeyTypeName}}{{ValueType}}) String() string {
+func (mp *Map{{KeyTypeName}}{{ValueTypeName}}) String() string {
 	str := "{"
 	for key, value := range mp.elements {
 		str += fmt.Sprintf("%v: %v, ", key, value)
@@ -56,11 +62,28 @@ func GetMapConstructor(k *types.TTyping, v *types.TTyping) string {
 	return code
 }
 
+// Wraps a Go map without copying it, the map shares its entries.
+func GetMapWrapper(k *types.TTyping, v *types.TTyping) string {
+	return "WrapMap" + k.ToNormalName() + v.ToNormalName()
+}
+
 func GenerateMapCode(k *types.TTyping, v *types.TTyping) string {
 	code := MapCode
 	code = strings.ReplaceAll(code, "{{KeyTypeName}}", k.ToNormalName())
-	code = strings.ReplaceAll(code, "{{ValueType}}", v.ToNormalName())
+	code = strings.ReplaceAll(code, "{{ValueTypeName}}", v.ToNormalName())
 	code = strings.ReplaceAll(code, "{{KeyType}}", k.ToGoType())
 	code = strings.ReplaceAll(code, "{{ValueType}}", v.ToGoType())
 	return code
 }
+
+// Explains why the key type cannot be used as a hashmap key.
+func GetMapKeyError(k *types.TTyping) string {
+	if !types.IsStructInstance(k) {
+		return INVALID_HASHMAP_KEY_TYPE
+	}
+	member := types.GetNonComparableMember(k)
+	if member == nil {
+		return INVALID_HASHMAP_KEY_TYPE
+	}
+	return fmt.Sprintf(INVALID_HASHMAP_KEY_NOT_COMPARABLE, k.ToString(), member.Name, member.DataType.ToString())
+}
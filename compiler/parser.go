@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"strings"
 )
 
 type TParser struct {
@@ -35,6 +36,7 @@ func (parser *TParser) acceptV(value string) {
 	RaiseLanguageCompileError(
 		parser.Tokenizer.File,
 		parser.Tokenizer.Data,
+		CODE_INVALID_SYNTAX,
 		fmt.Sprintf("expected %s, got %s", value, parser.look.Value),
 		parser.look.Position,
 	)
@@ -49,6 +51,7 @@ func (parser *TParser) acceptT(ttype TTokenType) {
 	RaiseLanguageCompileError(
 		parser.Tokenizer.File,
 		parser.Tokenizer.Data,
+		CODE_INVALID_SYNTAX,
 		fmt.Sprintf("expected %s, got %s", GetTokenTypeName(ttype), GetTokenTypeName(parser.look.Type)),
 		parser.look.Position,
 	)
@@ -119,10 +122,27 @@ func (parser *TParser) group() *TAst {
 		return node
 	} else if parser.matchV(KeyFunction) {
 		return parser.functionExpression()
+	} else if parser.matchV(KeyChan) {
+		return parser.chanExpression()
 	}
 	return parser.terminal()
 }
 
+// Parses "chan<T>()" or the buffered "chan<T>(size)".
+func (parser *TParser) chanExpression() *TAst {
+	typeAst := parser.chanType()
+	parser.acceptV("(")
+	size := parser.expression()
+	ended := parser.look.Position
+	parser.acceptV(")")
+	return AstDouble(
+		AstChan,
+		typeAst.Position.Merge(ended),
+		typeAst.Ast0,
+		size,
+	)
+}
+
 func (parser *TParser) array() *TAst {
 	start := parser.look.Position
 	ended := start
@@ -133,11 +153,16 @@ func (parser *TParser) array() *TAst {
 		elements = append(elements, elementN)
 		for parser.matchV(",") {
 			parser.acceptV(",")
+			if parser.matchV("]") {
+				// A trailing comma, the element list ends
+				break
+			}
 			elementN = parser.expression()
 			if elementN == nil {
 				RaiseLanguageCompileError(
 					parser.Tokenizer.File,
 					parser.Tokenizer.Data,
+					CODE_MISSING_EXPRESSION,
 					"missing expression after comma",
 					parser.look.Position,
 				)
@@ -170,11 +195,15 @@ func (parser *TParser) hashmap() *TAst {
 		vals = append(vals, valN)
 		for parser.matchV(",") {
 			parser.acceptV(",")
+			if parser.matchV("}") {
+				break
+			}
 			keyN = parser.expression()
 			if keyN == nil {
 				RaiseLanguageCompileError(
 					parser.Tokenizer.File,
 					parser.Tokenizer.Data,
+					CODE_MISSING_EXPRESSION,
 					"missing key expression after comma",
 					parser.look.Position,
 				)
@@ -215,6 +244,7 @@ func (parser *TParser) functionExpression() *TAst {
 				RaiseLanguageCompileError(
 					parser.Tokenizer.File,
 					parser.Tokenizer.Data,
+					CODE_MISSING_NAME,
 					"missing field name",
 					parser.look.Position,
 				)
@@ -264,6 +294,7 @@ func (parser *TParser) memberOrCall() *TAst {
 				RaiseLanguageCompileError(
 					parser.Tokenizer.File,
 					parser.Tokenizer.Data,
+					CODE_MISSING_EXPRESSION,
 					"missing member or expression",
 					parser.look.Position,
 				)
@@ -298,6 +329,7 @@ func (parser *TParser) memberOrCall() *TAst {
 						RaiseLanguageCompileError(
 							parser.Tokenizer.File,
 							parser.Tokenizer.Data,
+							CODE_MISSING_EXPRESSION,
 							"missing expression after comma",
 							parser.look.Position,
 						)
@@ -344,6 +376,7 @@ func (parser *TParser) structExpression() *TAst {
 			RaiseLanguageCompileError(
 				parser.Tokenizer.File,
 				parser.Tokenizer.Data,
+				CODE_MISSING_EXPRESSION,
 				"missing value",
 				parser.look.Position,
 			)
@@ -352,11 +385,15 @@ func (parser *TParser) structExpression() *TAst {
 		values = append(values, value)
 		for parser.matchV(",") {
 			parser.acceptV(",")
+			if parser.matchV("}") {
+				break
+			}
 			name = parser.terminal()
 			if name == nil {
 				RaiseLanguageCompileError(
 					parser.Tokenizer.File,
 					parser.Tokenizer.Data,
+					CODE_MISSING_NAME,
 					"missing name",
 					parser.look.Position,
 				)
@@ -367,6 +404,7 @@ func (parser *TParser) structExpression() *TAst {
 				RaiseLanguageCompileError(
 					parser.Tokenizer.File,
 					parser.Tokenizer.Data,
+					CODE_MISSING_EXPRESSION,
 					"missing value",
 					parser.look.Position,
 				)
@@ -399,6 +437,7 @@ func (parser *TParser) ifExpression() *TAst {
 			RaiseLanguageCompileError(
 				parser.Tokenizer.File,
 				parser.Tokenizer.Data,
+				CODE_INVALID_SYNTAX,
 				"missing if body",
 				parser.look.Position,
 			)
@@ -409,6 +448,7 @@ func (parser *TParser) ifExpression() *TAst {
 			RaiseLanguageCompileError(
 				parser.Tokenizer.File,
 				parser.Tokenizer.Data,
+				CODE_INVALID_SYNTAX,
 				"missing else body",
 				parser.look.Position,
 			)
@@ -426,7 +466,7 @@ func (parser *TParser) ifExpression() *TAst {
 }
 
 func (parser *TParser) unary() *TAst {
-	if parser.matchV("+") || parser.matchV("-") || parser.matchV("!") || parser.matchV("~") {
+	if parser.matchV("+") || parser.matchV("-") || parser.matchV("!") || parser.matchV("~") || parser.matchV("<-") {
 		opt := parser.look.Value
 		parser.acceptT(TokenSYM)
 		node := parser.unary()
@@ -434,6 +474,7 @@ func (parser *TParser) unary() *TAst {
 			RaiseLanguageCompileError(
 				parser.Tokenizer.File,
 				parser.Tokenizer.Data,
+				CODE_MISSING_EXPRESSION,
 				"missing right-hand expression",
 				parser.look.Position,
 			)
@@ -445,6 +486,28 @@ func (parser *TParser) unary() *TAst {
 			node,
 			opt,
 		)
+	} else if parser.matchV(KeyRun) || parser.matchV(KeyAwait) {
+		start := parser.look.Position
+		astType := AstRun
+		if parser.matchV(KeyAwait) {
+			astType = AstAwait
+		}
+		parser.acceptT(TokenKEY)
+		node := parser.unary()
+		if node == nil {
+			RaiseLanguageCompileError(
+				parser.Tokenizer.File,
+				parser.Tokenizer.Data,
+				CODE_MISSING_EXPRESSION,
+				"missing right-hand expression",
+				parser.look.Position,
+			)
+		}
+		return AstSingle(
+			astType,
+			start.Merge(node.Position),
+			node,
+		)
 	} else if parser.matchV(KeyNew) {
 		start := parser.look.Position
 		ended := start
@@ -454,6 +517,7 @@ func (parser *TParser) unary() *TAst {
 			RaiseLanguageCompileError(
 				parser.Tokenizer.File,
 				parser.Tokenizer.Data,
+				CODE_MISSING_EXPRESSION,
 				"missing expression",
 				parser.look.Position,
 			)
@@ -468,19 +532,44 @@ func (parser *TParser) unary() *TAst {
 	return parser.ifExpression()
 }
 
-func (parser *TParser) multiplicative() *TAst {
+func (parser *TParser) cast() *TAst {
 	lhs := parser.unary()
 	if lhs == nil {
 		return nil
 	}
+	for parser.matchV(KeyAs) || parser.matchV(KeyIs) {
+		opt := parser.look.Value
+		parser.acceptT(TokenKEY)
+		rhs := parser.typing()
+		astType := AstCast
+		if opt == KeyIs {
+			astType = AstIs
+		}
+		lhs = AstBinary(
+			astType,
+			lhs.Position.Merge(rhs.Position),
+			lhs,
+			rhs,
+			opt,
+		)
+	}
+	return lhs
+}
+
+func (parser *TParser) multiplicative() *TAst {
+	lhs := parser.cast()
+	if lhs == nil {
+		return nil
+	}
 	for parser.matchV("*") || parser.matchV("/") || parser.matchV("%") {
 		opt := parser.look.Value
 		parser.acceptT(TokenSYM)
-		rhs := parser.unary()
+		rhs := parser.cast()
 		if rhs == nil {
 			RaiseLanguageCompileError(
 				parser.Tokenizer.File,
 				parser.Tokenizer.Data,
+				CODE_MISSING_EXPRESSION,
 				"missing right-hand expression",
 				lhs.Position,
 			)
@@ -509,6 +598,7 @@ func (parser *TParser) additive() *TAst {
 			RaiseLanguageCompileError(
 				parser.Tokenizer.File,
 				parser.Tokenizer.Data,
+				CODE_MISSING_EXPRESSION,
 				"missing right-hand expression",
 				lhs.Position,
 			)
@@ -537,6 +627,7 @@ func (parser *TParser) shift() *TAst {
 			RaiseLanguageCompileError(
 				parser.Tokenizer.File,
 				parser.Tokenizer.Data,
+				CODE_MISSING_EXPRESSION,
 				"missing right-hand expression",
 				lhs.Position,
 			)
@@ -566,6 +657,7 @@ func (parser *TParser) relational() *TAst {
 			RaiseLanguageCompileError(
 				parser.Tokenizer.File,
 				parser.Tokenizer.Data,
+				CODE_MISSING_EXPRESSION,
 				"missing right-hand expression",
 				lhs.Position,
 			)
@@ -594,6 +686,7 @@ func (parser *TParser) equality() *TAst {
 			RaiseLanguageCompileError(
 				parser.Tokenizer.File,
 				parser.Tokenizer.Data,
+				CODE_MISSING_EXPRESSION,
 				"missing right-hand expression",
 				lhs.Position,
 			)
@@ -622,6 +715,7 @@ func (parser *TParser) bitwise() *TAst {
 			RaiseLanguageCompileError(
 				parser.Tokenizer.File,
 				parser.Tokenizer.Data,
+				CODE_MISSING_EXPRESSION,
 				"missing right-hand expression",
 				lhs.Position,
 			)
@@ -650,6 +744,7 @@ func (parser *TParser) logical() *TAst {
 			RaiseLanguageCompileError(
 				parser.Tokenizer.File,
 				parser.Tokenizer.Data,
+				CODE_MISSING_EXPRESSION,
 				"missing right-hand expression",
 				lhs.Position,
 			)
@@ -676,14 +771,85 @@ func (parser *TParser) mandatoryExpression() *TAst {
 	RaiseLanguageCompileError(
 		parser.Tokenizer.File,
 		parser.Tokenizer.Data,
+		CODE_MISSING_EXPRESSION,
 		"missing expression",
 		parser.look.Position,
 	)
 	return nil
 }
 
+// Parses "chan<T>". A ">>" closing nested channel types is split,
+// the first '>' closes this type and the second is left for the outer one.
+func (parser *TParser) chanType() *TAst {
+	start := parser.look.Position
+	ended := start
+	parser.acceptV(KeyChan)
+	parser.acceptV("<")
+	elementType := parser.typeOrNil()
+	if elementType == nil {
+		RaiseLanguageCompileError(
+			parser.Tokenizer.File,
+			parser.Tokenizer.Data,
+			CODE_INVALID_TYPE,
+			"missing element type",
+			parser.look.Position,
+		)
+	}
+	ended = parser.look.Position
+	if parser.matchV(">>") {
+		parser.look.Value = ">"
+		parser.look.Position.SColm++
+	} else {
+		parser.acceptV(">")
+	}
+	return AstSingle(
+		AstTypeChan,
+		start.Merge(ended),
+		elementType,
+	)
+}
+
+func (parser *TParser) futureType() *TAst {
+	start := parser.look.Position
+	ended := start
+	parser.acceptV(KeyFuture)
+	parser.acceptV("<")
+	resultType := parser.typeOrNil()
+	if resultType == nil {
+		RaiseLanguageCompileError(
+			parser.Tokenizer.File,
+			parser.Tokenizer.Data,
+			CODE_INVALID_TYPE,
+			"missing result type",
+			parser.look.Position,
+		)
+	}
+	panics := parser.matchV(KeyPanics)
+	if panics {
+		parser.acceptV(KeyPanics)
+	}
+	ended = parser.look.Position
+	if parser.matchV(">>") {
+		parser.look.Value = ">"
+		parser.look.Position.SColm++
+	} else {
+		parser.acceptV(">")
+	}
+	node := AstSingle(
+		AstTypeFuture,
+		start.Merge(ended),
+		resultType,
+	)
+	node.Flg0 = panics
+	return node
+}
+
 func (parser *TParser) baseType() *TAst {
-	if parser.matchV("{") {
+	if parser.matchV(KeyChan) {
+		return parser.chanType()
+	} else if parser.matchV(KeyFuture) {
+		return parser.futureType()
+	} else if parser.matchV("{") {
 		start := parser.look.Position
 		ended := start
 		parser.acceptV("{")
@@ -692,6 +858,7 @@ func (parser *TParser) baseType() *TAst {
 			RaiseLanguageCompileError(
 				parser.Tokenizer.File,
 				parser.Tokenizer.Data,
+				CODE_INVALID_TYPE,
 				"missing key type",
 				parser.look.Position,
 			)
@@ -702,6 +869,7 @@ func (parser *TParser) baseType() *TAst {
 			RaiseLanguageCompileError(
 				parser.Tokenizer.File,
 				parser.Tokenizer.Data,
+				CODE_INVALID_TYPE,
 				"missing value type",
 				parser.look.Position,
 			)
@@ -723,6 +891,7 @@ func (parser *TParser) baseType() *TAst {
 			RaiseLanguageCompileError(
 				parser.Tokenizer.File,
 				parser.Tokenizer.Data,
+				CODE_INVALID_TYPE,
 				"missing element type",
 				parser.look.Position,
 			)
@@ -749,6 +918,7 @@ func (parser *TParser) baseType() *TAst {
 					RaiseLanguageCompileError(
 						parser.Tokenizer.File,
 						parser.Tokenizer.Data,
+						CODE_MISSING_EXPRESSION,
 						"missing expression after comma",
 						parser.look.Position,
 					)
@@ -772,6 +942,7 @@ func (parser *TParser) baseType() *TAst {
 			RaiseLanguageCompileError(
 				parser.Tokenizer.File,
 				parser.Tokenizer.Data,
+				CODE_INVALID_TYPE,
 				"missing return type",
 				parser.look.Position,
 			)
@@ -781,6 +952,7 @@ func (parser *TParser) baseType() *TAst {
 			RaiseLanguageCompileError(
 				parser.Tokenizer.File,
 				parser.Tokenizer.Data,
+				CODE_INVALID_TYPE,
 				"missing return type",
 				parser.look.Position,
 			)
@@ -863,6 +1035,14 @@ func (parser *TParser) baseType() *TAst {
 		)
 		parser.acceptT(TokenKEY)
 		return node
+	} else if parser.matchT(TokenKEY) && parser.matchV(KeyAny) {
+		node := AstTerminal(
+			AstTypeAny,
+			parser.look.Position,
+			parser.look.Value,
+		)
+		parser.acceptT(TokenKEY)
+		return node
 	}
 	return parser.terminal()
 }
@@ -898,6 +1078,7 @@ func (parser *TParser) typing() *TAst {
 		RaiseLanguageCompileError(
 			parser.Tokenizer.File,
 			parser.Tokenizer.Data,
+			CODE_INVALID_TYPE,
 			"missing type",
 			parser.look.Position,
 		)
@@ -905,11 +1086,83 @@ func (parser *TParser) typing() *TAst {
 	return dtypeAst
 }
 
-func (parser *TParser) statement() *TAst {
+// Parses a statement. A statement with an error is skipped in panic mode,
+// up to the next ";" or to the end of its block, and an empty statement
+// takes its place so that the parse goes on with the next one.
+func (parser *TParser) statement() (node *TAst) {
+	start := parser.look.Position
+	defer func() {
+		if RecoverAbort(recover()) {
+			parser.synchronize()
+			node = CreateAst(AstEmptyStmnt, start)
+		}
+	}()
+	checks := parser.allowedChecks(start)
+	node = parser.statementOrNil()
+	if node != nil && len(checks) > 0 {
+		Diagnostics.Suppress(parser.Tokenizer.File, node.Position, checks)
+	}
+	return node
+}
+
+// Returns the checks that a "parrot:allow" comment right above position
+// turns off, such as "// parrot:allow unused naming".
+func (parser *TParser) allowedChecks(position TPosition) []string {
+	checks := make([]string, 0)
+	for _, comment := range parser.commentsAbove(position) {
+		directive, ok := strings.CutPrefix(strings.TrimSpace(comment.Value), ALLOW_DIRECTIVE)
+		if !ok {
+			continue
+		}
+		for _, check := range strings.FieldsFunc(directive, func(r rune) bool { return r == ' ' || r == ',' }) {
+			if !IsCheck(check) {
+				ReportLanguageCompileError(
+					parser.Tokenizer.File,
+					parser.Tokenizer.Data,
+					CODE_INVALID_SYNTAX,
+					fmt.Sprintf(INVALID_ALLOW_CHECK, check, strings.Join(Checks, ", ")),
+					comment.Position,
+				)
+				continue
+			}
+			checks = append(checks, check)
+		}
+	}
+	return checks
+}
+
+// Skips the tokens of a statement with an error. Stops after a ";" or
+// after a block that the statement opened, and before a "}" that closes
+// the block around the statement.
+func (parser *TParser) synchronize() {
+	depth := 0
+	for !parser.matchT(TokenEOF) {
+		switch {
+		case parser.matchV(";") && depth == 0:
+			parser.look = parser.Tokenizer.Next()
+			return
+		case parser.matchV("{"):
+			depth++
+		case parser.matchV("}") && depth == 0:
+			return
+		case parser.matchV("}"):
+			depth--
+			if depth == 0 {
+				parser.look = parser.Tokenizer.Next()
+				return
+			}
+		}
+		parser.look = parser.Tokenizer.Next()
+	}
+}
+
+func (parser *TParser) statementOrNil() *TAst {
 	if parser.matchV(KeyStruct) {
 		return parser.structDecl()
 	} else if parser.matchV(KeyFunction) {
 		return parser.functionDecl()
+	} else if parser.matchV(KeyExport) {
+		return parser.exportDecl()
 	} else if parser.matchV(KeyImport) {
 		return parser.importDecl()
 	} else if parser.matchV(KeyVar) {
@@ -926,6 +1179,12 @@ func (parser *TParser) statement() *TAst {
 		return parser.whileDecl()
 	} else if parser.matchV(KeyIf) {
 		return parser.ifDecl()
+	} else if parser.matchV(KeySwitch) {
+		return parser.switchDecl()
+	} else if parser.matchV(KeySelect) {
+		return parser.selectDecl()
+	} else if parser.matchV(KeyParallel) {
+		return parser.parallelDecl()
 	} else if parser.matchV(KeyRun) {
 		return parser.runStmnt()
 	} else if parser.matchV(KeyContinue) {
@@ -949,6 +1208,7 @@ func (parser *TParser) structDecl() *TAst {
 		RaiseLanguageCompileError(
 			parser.Tokenizer.File,
 			parser.Tokenizer.Data,
+			CODE_MISSING_NAME,
 			"missing struct name",
 			parser.look.Position,
 		)
@@ -961,6 +1221,7 @@ func (parser *TParser) structDecl() *TAst {
 		RaiseLanguageCompileError(
 			parser.Tokenizer.File,
 			parser.Tokenizer.Data,
+			CODE_INVALID_STRUCT,
 			"struct must have at least one field",
 			parser.look.Position,
 		)
@@ -1001,6 +1262,7 @@ func (parser *TParser) functionDecl() *TAst {
 			RaiseLanguageCompileError(
 				parser.Tokenizer.File,
 				parser.Tokenizer.Data,
+				CODE_MISSING_NAME,
 				"missing method name",
 				parser.look.Position,
 			)
@@ -1013,6 +1275,7 @@ func (parser *TParser) functionDecl() *TAst {
 		RaiseLanguageCompileError(
 			parser.Tokenizer.File,
 			parser.Tokenizer.Data,
+			CODE_MISSING_NAME,
 			"missing function name",
 			parser.look.Position,
 		)
@@ -1038,6 +1301,7 @@ func (parser *TParser) functionDecl() *TAst {
 				RaiseLanguageCompileError(
 					parser.Tokenizer.File,
 					parser.Tokenizer.Data,
+					CODE_MISSING_NAME,
 					"missing field name",
 					parser.look.Position,
 				)
@@ -1078,54 +1342,164 @@ func (parser *TParser) functionDecl() *TAst {
 	)
 }
 
+// Parses a struct, function or method that a library exports.
+// The comments on the lines right above it are its documentation.
+func (parser *TParser) exportDecl() *TAst {
+	start := parser.look.Position
+	doc := parser.docComment(start)
+	parser.acceptV(KeyExport)
+	var node *TAst
+	if parser.matchV(KeyStruct) {
+		node = parser.structDecl()
+	} else if parser.matchV(KeyFunction) {
+		node = parser.functionDecl()
+	} else {
+		RaiseLanguageCompileError(
+			parser.Tokenizer.File,
+			parser.Tokenizer.Data,
+			CODE_NOT_ALLOWED_HERE,
+			"only a struct, function or method can be exported",
+			parser.look.Position,
+		)
+	}
+	node.Position = start.Merge(node.Position)
+	node.Str1 = doc
+	node.Flg1 = true
+	return node
+}
+
+// Returns the text of the comments that end on the line above position,
+// one line of text per comment. Directives are not documentation.
+func (parser *TParser) docComment(position TPosition) string {
+	lines := make([]string, 0)
+	for _, comment := range parser.commentsAbove(position) {
+		if !strings.HasPrefix(strings.TrimSpace(comment.Value), ALLOW_DIRECTIVE) {
+			lines = append(lines, comment.Value)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Returns the comments on the lines right above position, with no other
+// line in between.
+func (parser *TParser) commentsAbove(position TPosition) []TToken {
+	comments := parser.Tokenizer.Comments
+	line := position.SLine - 1
+	first := len(comments)
+	for first > 0 && comments[first-1].Position.SLine == line {
+		first--
+		line--
+	}
+	return comments[first:]
+}
+
 func (parser *TParser) importDecl() *TAst {
 	start := parser.look.Position
 	ended := start
 	parser.acceptV(KeyImport)
+	if parser.matchV("*") {
+		// import * as name from "path";
+		parser.acceptV("*")
+		parser.acceptV(KeyAs)
+		alias := parser.terminal()
+		if alias == nil {
+			RaiseLanguageCompileError(
+				parser.Tokenizer.File,
+				parser.Tokenizer.Data,
+				CODE_IMPORT,
+				"missing import namespace name",
+				parser.look.Position,
+			)
+		}
+		parser.acceptV(KeyFrom)
+		path := parser.importPath()
+		ended = parser.look.Position
+		parser.acceptV(";")
+		ast := AstDouble(
+			AstImport,
+			start.Merge(ended),
+			path,
+			alias,
+		)
+		ast.Flg0 = true
+		return ast
+	}
 	parser.acceptV("(")
 	names := make([]*TAst, 0)
-	nameN := parser.terminal()
+	alias := make([]*TAst, 0)
+	nameN, aliasN := parser.importName()
 	if nameN == nil {
 		RaiseLanguageCompileError(
 			parser.Tokenizer.File,
 			parser.Tokenizer.Data,
+			CODE_IMPORT,
 			"missing import name",
 			parser.look.Position,
 		)
 	}
 	names = append(names, nameN)
+	alias = append(alias, aliasN)
 	for parser.matchV(",") {
 		parser.acceptV(",")
-		nameN = parser.terminal()
+		nameN, aliasN = parser.importName()
 		if nameN == nil {
 			RaiseLanguageCompileError(
 				parser.Tokenizer.File,
 				parser.Tokenizer.Data,
+				CODE_IMPORT,
 				"missing import name after comma",
 				parser.look.Position,
 			)
 		}
 		names = append(names, nameN)
+		alias = append(alias, aliasN)
 	}
 	parser.acceptV(")")
 	parser.acceptV(KeyFrom)
+	path := parser.importPath()
+	ended = parser.look.Position
+	parser.acceptV(";")
+	return AstSingleWithDoubleArray(
+		AstImport,
+		start.Merge(ended),
+		path,
+		names,
+		alias,
+	)
+}
+
+// Parses "Name" or "Name as alias", the alias is nil when not given.
+func (parser *TParser) importName() (*TAst, *TAst) {
+	nameN := parser.terminal()
+	if nameN == nil || !parser.matchV(KeyAs) {
+		return nameN, nil
+	}
+	parser.acceptV(KeyAs)
+	aliasN := parser.terminal()
+	if aliasN == nil {
+		RaiseLanguageCompileError(
+			parser.Tokenizer.File,
+			parser.Tokenizer.Data,
+			CODE_IMPORT,
+			"missing import alias",
+			parser.look.Position,
+		)
+	}
+	return nameN, aliasN
+}
+
+func (parser *TParser) importPath() *TAst {
 	path := parser.terminal()
 	if path == nil {
 		RaiseLanguageCompileError(
 			parser.Tokenizer.File,
 			parser.Tokenizer.Data,
+			CODE_IMPORT,
 			"missing import path",
 			parser.look.Position,
 		)
 	}
-	ended = parser.look.Position
-	parser.acceptV(";")
-	return AstSingleWithArray(
-		AstImport,
-		start.Merge(ended),
-		path,
-		names,
-	)
+	return path
 }
 
 func (parser *TParser) varDecl() *TAst {
@@ -1142,6 +1516,7 @@ func (parser *TParser) varDecl() *TAst {
 		RaiseLanguageCompileError(
 			parser.Tokenizer.File,
 			parser.Tokenizer.Data,
+			CODE_MISSING_NAME,
 			"missing field name",
 			parser.look.Position,
 		)
@@ -1161,6 +1536,7 @@ func (parser *TParser) varDecl() *TAst {
 			RaiseLanguageCompileError(
 				parser.Tokenizer.File,
 				parser.Tokenizer.Data,
+				CODE_MISSING_NAME,
 				"missing field name after comma",
 				parser.look.Position,
 			)
@@ -1199,6 +1575,7 @@ func (parser *TParser) constDecl() *TAst {
 		RaiseLanguageCompileError(
 			parser.Tokenizer.File,
 			parser.Tokenizer.Data,
+			CODE_MISSING_NAME,
 			"missing field name",
 			parser.look.Position,
 		)
@@ -1218,6 +1595,7 @@ func (parser *TParser) constDecl() *TAst {
 			RaiseLanguageCompileError(
 				parser.Tokenizer.File,
 				parser.Tokenizer.Data,
+				CODE_MISSING_NAME,
 				"missing field name after comma",
 				parser.look.Position,
 			)
@@ -1256,6 +1634,7 @@ func (parser *TParser) localDecl() *TAst {
 		RaiseLanguageCompileError(
 			parser.Tokenizer.File,
 			parser.Tokenizer.Data,
+			CODE_MISSING_NAME,
 			"missing field name",
 			parser.look.Position,
 		)
@@ -1275,6 +1654,7 @@ func (parser *TParser) localDecl() *TAst {
 			RaiseLanguageCompileError(
 				parser.Tokenizer.File,
 				parser.Tokenizer.Data,
+				CODE_MISSING_NAME,
 				"missing field name after comma",
 				parser.look.Position,
 			)
@@ -1325,6 +1705,7 @@ func (parser *TParser) forDecl() *TAst {
 		RaiseLanguageCompileError(
 			parser.Tokenizer.File,
 			parser.Tokenizer.Data,
+			CODE_INVALID_SYNTAX,
 			"missing body in for loop",
 			parser.look.Position,
 		)
@@ -1334,6 +1715,7 @@ func (parser *TParser) forDecl() *TAst {
 			RaiseLanguageCompileError(
 				parser.Tokenizer.File,
 				parser.Tokenizer.Data,
+				CODE_INVALID_SYNTAX,
 				"ambiguous 'if' in for loop",
 				parser.look.Position,
 			)
@@ -1354,6 +1736,7 @@ func (parser *TParser) forDecl() *TAst {
 		RaiseLanguageCompileError(
 			parser.Tokenizer.File,
 			parser.Tokenizer.Data,
+			CODE_INVALID_SYNTAX,
 			"invalid 'for' statement",
 			parser.look.Position,
 		)
@@ -1390,6 +1773,7 @@ func (parser *TParser) doWhileDecl() *TAst {
 		RaiseLanguageCompileError(
 			parser.Tokenizer.File,
 			parser.Tokenizer.Data,
+			CODE_INVALID_SYNTAX,
 			"missing body in do while loop",
 			parser.look.Position,
 		)
@@ -1420,6 +1804,7 @@ func (parser *TParser) whileDecl() *TAst {
 		RaiseLanguageCompileError(
 			parser.Tokenizer.File,
 			parser.Tokenizer.Data,
+			CODE_INVALID_SYNTAX,
 			"missing body in while loop",
 			parser.look.Position,
 		)
@@ -1444,6 +1829,7 @@ func (parser *TParser) ifDecl() *TAst {
 		RaiseLanguageCompileError(
 			parser.Tokenizer.File,
 			parser.Tokenizer.Data,
+			CODE_INVALID_SYNTAX,
 			"invalid 'if' statement",
 			parser.look.Position,
 		)
@@ -1462,6 +1848,199 @@ func (parser *TParser) ifDecl() *TAst {
 	)
 }
 
+func (parser *TParser) switchDecl() *TAst {
+	start := parser.look.Position
+	ended := start
+	parser.acceptV(KeySwitch)
+	parser.acceptV("(")
+	subject := parser.mandatoryExpression()
+	parser.acceptV(")")
+	parser.acceptV("{")
+	cases := make([]*TAst, 0)
+	var defaultCase *TAst = nil
+	for parser.matchV(KeyCase) || parser.matchV(KeyDefault) {
+		clause := parser.caseClause()
+		if clause.Str0 != KeyDefault {
+			cases = append(cases, clause)
+			continue
+		}
+		if defaultCase != nil {
+			RaiseLanguageCompileError(
+				parser.Tokenizer.File,
+				parser.Tokenizer.Data,
+				CODE_SWITCH,
+				"multiple defaults in switch",
+				clause.Position,
+			)
+		}
+		defaultCase = clause
+	}
+	ended = parser.look.Position
+	parser.acceptV("}")
+	if len(cases) == 0 {
+		RaiseLanguageCompileError(
+			parser.Tokenizer.File,
+			parser.Tokenizer.Data,
+			CODE_SWITCH,
+			"switch must have at least one case",
+			start.Merge(ended),
+		)
+	}
+	switchAst := AstSingleWithArray(
+		AstSwitch,
+		start.Merge(ended),
+		subject,
+		cases,
+	)
+	switchAst.Ast1 = defaultCase
+	return switchAst
+}
+
+// Parses "case e1, e2: ...", "case is T, is U: ..." or "default: ...".
+// Flg0 marks a type case, whose labels are typings instead of expressions.
+func (parser *TParser) caseClause() *TAst {
+	start := parser.look.Position
+	ended := start
+	labels := make([]*TAst, 0)
+	isType := false
+	opt := parser.look.Value
+	if parser.matchV(KeyDefault) {
+		parser.acceptV(KeyDefault)
+	} else {
+		parser.acceptV(KeyCase)
+		isType = parser.matchV(KeyIs)
+		for {
+			if isType {
+				parser.acceptV(KeyIs)
+				labels = append(labels, parser.typing())
+			} else {
+				labels = append(labels, parser.mandatoryExpression())
+			}
+			if !parser.matchV(",") {
+				break
+			}
+			parser.acceptV(",")
+		}
+	}
+	ended = parser.look.Position
+	parser.acceptV(":")
+	clause := AstDoubleArray(
+		AstCase,
+		start.Merge(ended),
+		labels,
+		parser.caseStatements(),
+	)
+	clause.Str0 = opt
+	clause.Flg0 = isType
+	return clause
+}
+
+// Parses the statements of a case, up to the next case or the closing brace.
+func (parser *TParser) caseStatements() []*TAst {
+	statements := make([]*TAst, 0)
+	for !parser.matchV(KeyCase) && !parser.matchV(KeyDefault) && !parser.matchV("}") {
+		statement := parser.statement()
+		if statement == nil {
+			break
+		}
+		statements = append(statements, statement)
+	}
+	return statements
+}
+
+func (parser *TParser) selectDecl() *TAst {
+	start := parser.look.Position
+	ended := start
+	parser.acceptV(KeySelect)
+	parser.acceptV("{")
+	cases := make([]*TAst, 0)
+	var defaultCase *TAst = nil
+	for parser.matchV(KeyCase) || parser.matchV(KeyDefault) {
+		clause := parser.selectClause()
+		if clause.Str0 != KeyDefault {
+			cases = append(cases, clause)
+			continue
+		}
+		if defaultCase != nil {
+			RaiseLanguageCompileError(
+				parser.Tokenizer.File,
+				parser.Tokenizer.Data,
+				CODE_SWITCH,
+				"multiple defaults in select",
+				clause.Position,
+			)
+		}
+		defaultCase = clause
+	}
+	ended = parser.look.Position
+	parser.acceptV("}")
+	selectAst := AstSingleArray(
+		AstSelect,
+		start.Merge(ended),
+		cases,
+	)
+	selectAst.Ast1 = defaultCase
+	return selectAst
+}
+
+// Parses "case <-ch: ...", "case v := <-ch: ...", "case ch <- v: ...",
+// "case timeout(ms): ..." or "default: ...". The case itself is kept in Ast0.
+func (parser *TParser) selectClause() *TAst {
+	start := parser.look.Position
+	ended := start
+	opt := parser.look.Value
+	var comm *TAst = nil
+	if parser.matchV(KeyDefault) {
+		parser.acceptV(KeyDefault)
+	} else {
+		parser.acceptV(KeyCase)
+		comm = parser.postfix()
+		if comm == nil {
+			RaiseLanguageCompileError(
+				parser.Tokenizer.File,
+				parser.Tokenizer.Data,
+				CODE_SWITCH,
+				"missing select case",
+				parser.look.Position,
+			)
+		}
+		if parser.matchV("<-") {
+			comm = parser.send(comm)
+		}
+	}
+	ended = parser.look.Position
+	parser.acceptV(":")
+	clause := AstSingleWithDoubleArray(
+		AstCase,
+		start.Merge(ended),
+		comm,
+		make([]*TAst, 0),
+		parser.caseStatements(),
+	)
+	clause.Str0 = opt
+	return clause
+}
+
+func (parser *TParser) parallelDecl() *TAst {
+	start := parser.look.Position
+	parser.acceptV(KeyParallel)
+	if !parser.matchV("{") {
+		RaiseLanguageCompileError(
+			parser.Tokenizer.File,
+			parser.Tokenizer.Data,
+			CODE_CONCURRENCY,
+			"parallel must be followed by a block",
+			parser.look.Position,
+		)
+	}
+	body := parser.blockStmnt()
+	return AstSingle(
+		AstParallel,
+		start.Merge(body.Position),
+		body,
+	)
+}
+
 func (parser *TParser) runStmnt() *TAst {
 	start := parser.look.Position
 	ended := start
@@ -1545,6 +2124,7 @@ func (parser *TParser) tupleOrExpression() *TAst {
 			RaiseLanguageCompileError(
 				parser.Tokenizer.File,
 				parser.Tokenizer.Data,
+				CODE_MISSING_NAME,
 				"missing name after comma",
 				parser.look.Position,
 			)
@@ -1571,6 +2151,7 @@ func (parser *TParser) simpleAssign() *TAst {
 			RaiseLanguageCompileError(
 				parser.Tokenizer.File,
 				parser.Tokenizer.Data,
+				CODE_MISSING_EXPRESSION,
 				"missing right-hand expression",
 				lhs.Position,
 			)
@@ -1599,6 +2180,7 @@ func (parser *TParser) mulAssign() *TAst {
 			RaiseLanguageCompileError(
 				parser.Tokenizer.File,
 				parser.Tokenizer.Data,
+				CODE_MISSING_EXPRESSION,
 				"missing right-hand expression",
 				lhs.Position,
 			)
@@ -1627,6 +2209,7 @@ func (parser *TParser) addAssign() *TAst {
 			RaiseLanguageCompileError(
 				parser.Tokenizer.File,
 				parser.Tokenizer.Data,
+				CODE_MISSING_EXPRESSION,
 				"missing right-hand expression",
 				lhs.Position,
 			)
@@ -1655,6 +2238,7 @@ func (parser *TParser) shiftAssign() *TAst {
 			RaiseLanguageCompileError(
 				parser.Tokenizer.File,
 				parser.Tokenizer.Data,
+				CODE_MISSING_EXPRESSION,
 				"missing right-hand expression",
 				lhs.Position,
 			)
@@ -1683,6 +2267,7 @@ func (parser *TParser) bitAssign() *TAst {
 			RaiseLanguageCompileError(
 				parser.Tokenizer.File,
 				parser.Tokenizer.Data,
+				CODE_MISSING_EXPRESSION,
 				"missing right-hand expression",
 				lhs.Position,
 			)
@@ -1716,6 +2301,20 @@ func (parser *TParser) postfix() *TAst {
 	return node
 }
 
+// Sends are statements, so they are only parsed after a full expression
+// statement or as a select case, never within another expression.
+func (parser *TParser) send(lhs *TAst) *TAst {
+	parser.acceptV("<-")
+	rhs := parser.mandatoryExpression()
+	return AstBinary(
+		AstSend,
+		lhs.Position.Merge(rhs.Position),
+		lhs,
+		rhs,
+		"<-",
+	)
+}
+
 func (parser *TParser) expressionStatment() *TAst {
 	start := parser.look.Position
 	ended := start
@@ -1730,6 +2329,9 @@ func (parser *TParser) expressionStatment() *TAst {
 		}
 		return CreateAst(AstEmptyStmnt, start.Merge(ended))
 	}
+	if parser.matchV("<-") {
+		expr = parser.send(expr)
+	}
 	ended = parser.look.Position
 	parser.acceptV(";")
 	return AstSingle(
@@ -1743,12 +2345,28 @@ func (parser *TParser) program() *TAst {
 	start := parser.look.Position
 	ended := start
 	children := make([]*TAst, 0)
-	for child := parser.statement(); child != nil; {
+	for !parser.matchT(TokenEOF) {
+		errors := len(Diagnostics.Items)
+		child := parser.statement()
+		if len(Diagnostics.Items) > errors && parser.matchV("}") {
+			// The "}" closes the declaration that had the error
+			parser.look = parser.Tokenizer.Next()
+		}
+		if child == nil {
+			// A token that cannot start a statement, such as a stray "}"
+			ReportLanguageCompileError(
+				parser.Tokenizer.File,
+				parser.Tokenizer.Data,
+				CODE_INVALID_SYNTAX,
+				fmt.Sprintf("unexpected %s", parser.look.Value),
+				parser.look.Position,
+			)
+			parser.look = parser.Tokenizer.Next()
+			continue
+		}
 		children = append(children, child)
-		child = parser.statement()
 	}
 	ended = parser.look.Position
-	parser.acceptT(TokenEOF)
 	return AstBlock(
 		AstCodeProgram,
 		start.Merge(ended),
@@ -57,6 +57,22 @@ func ToAbsolutePath(path string) string {
 	return absolutePath
 }
 
+// Reports whether an import path names a module of the standard library,
+// such as "lib:io".
+func IsLibPath(path string) bool {
+	return strings.HasPrefix(path, "lib:")
+}
+
+// Resolves a "lib:name" import to the module file in libPath.
+// Returns false if the name is not a valid module name.
+func ResolveLibPath(libPath string, path string) (string, bool) {
+	name := strings.TrimPrefix(path, "lib:")
+	if name == "" || !IsCamelCase(name) {
+		return "", false
+	}
+	return filepath.Join(libPath, name+".ns"), true
+}
+
 func ResolvePath(currentDir string, relativePath string) string {
 	if !(IsAbsolutePath(currentDir) && IsDir(currentDir)) {
 		RaiseSystemError("currentDir must be an absolute path")
@@ -2,7 +2,9 @@ package main
 
 import (
 	"fmt"
+	"go/token"
 	"go/types"
+	"strings"
 	"sync"
 
 	"golang.org/x/tools/go/packages"
@@ -14,6 +16,22 @@ var (
 	cacheMutex    sync.RWMutex
 )
 
+// Module the packages are loaded from, the current directory if unset
+var (
+	packagesDir string
+	packagesEnv []string
+)
+
+// SetGoPackagesConfig loads the packages from the module in dir, with the given environment,
+// so that third-party packages resolve to the versions the module requires.
+func SetGoPackagesConfig(dir string, env []string) {
+	cacheMutex.Lock()
+	packagesDir = dir
+	packagesEnv = env
+	packagesCache = make(map[string][]*packages.Package, 32)
+	cacheMutex.Unlock()
+}
+
 // loadPackages loads packages with caching.
 func loadPackages(path string, full bool) ([]*packages.Package, error) {
 	// Fast path: check cache first with read lock
@@ -27,7 +45,9 @@ func loadPackages(path string, full bool) ([]*packages.Package, error) {
 	// Prepare config based on need - reuse common config settings
 	cfg := &packages.Config{
 		// Optimized build flags
-		BuildFlags: []string{"-gcflags=-N -l"},
+		BuildFlags: []string{"-gcflags=-N -l", MOD_FLAG},
+		Dir:        packagesDir,
+		Env:        packagesEnv,
 	}
 
 	// Set mode flags with bitwise OR for better performance
@@ -58,7 +78,22 @@ func loadPackages(path string, full bool) ([]*packages.Package, error) {
 // Uses minimal loading configuration for speed.
 func HasGoPackage(path string) bool {
 	pkgs, err := loadPackages(path, false)
-	return err == nil && len(pkgs) > 0
+	if err != nil || len(pkgs) == 0 {
+		return false
+	}
+	// A package outside of the required modules loads with errors only
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsStdPackage reports whether path names a package of the Go standard library,
+// whose first path element has no dot, unlike a module path.
+func IsStdPackage(path string) bool {
+	return !strings.Contains(strings.Split(path, "/")[0], ".")
 }
 
 // GetGoPackages loads Go packages with full type and syntax information.
@@ -101,6 +136,33 @@ func PackagesGetName(pkgs []*packages.Package, name string) types.Object {
 	return nil
 }
 
+// GoPackageName returns the name Go code refers to the package at path by,
+// which is not always the last element of the path, as in gopkg.in/yaml.v3.
+func GoPackageName(path string) string {
+	for _, pkg := range GetGoPackages(path) {
+		if pkg.Name != "" {
+			return pkg.Name
+		}
+	}
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+// PackagesNames returns the exported names of the package scope, sorted.
+func PackagesNames(pkgs []*packages.Package) []string {
+	names := make([]string, 0)
+	for _, pkg := range pkgs {
+		if pkg.Types == nil {
+			continue
+		}
+		for _, name := range pkg.Types.Scope().Names() {
+			if token.IsExported(name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
 // IsGoStruct returns true if the given type is a struct or named struct.
 // Fast path for nil check improves performance.
 func IsGoStruct(t types.Type) bool {
@@ -110,3 +172,56 @@ func IsGoStruct(t types.Type) bool {
 	_, ok := t.Underlying().(*types.Struct)
 	return ok
 }
+
+// IsGoConstant returns true if the object is a constant.
+func IsGoConstant(obj types.Object) bool {
+	_, ok := obj.(*types.Const)
+	return ok
+}
+
+// IsGenericGoType returns true if the object is a type with type parameters.
+func IsGenericGoType(obj types.Object) bool {
+	typeName, ok := obj.(*types.TypeName)
+	if !ok {
+		return false
+	}
+	named, ok := typeName.Type().(*types.Named)
+	return ok && named.TypeParams().Len() > 0
+}
+
+// GoObjectPosition returns where a Go object is declared in the sources of the
+// loaded packages, false if no loaded package declares it.
+func GoObjectPosition(obj types.Object) (token.Position, bool) {
+	if obj == nil || obj.Pkg() == nil || !obj.Pos().IsValid() {
+		return token.Position{}, false
+	}
+	cacheMutex.RLock()
+	defer cacheMutex.RUnlock()
+	visited := make(map[*packages.Package]bool)
+	var find func(pkg *packages.Package) *packages.Package
+	find = func(pkg *packages.Package) *packages.Package {
+		if visited[pkg] {
+			return nil
+		}
+		visited[pkg] = true
+		if pkg.Types == obj.Pkg() {
+			return pkg
+		}
+		for _, imported := range pkg.Imports {
+			if found := find(imported); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	for _, pkgs := range packagesCache {
+		for _, pkg := range pkgs {
+			// Positions are only meaningful in the file set of their load
+			if found := find(pkg); found != nil && found.Fset != nil {
+				position := found.Fset.Position(obj.Pos())
+				return position, position.IsValid()
+			}
+		}
+	}
+	return token.Position{}, false
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Project file
+// A script declares the Go modules it imports from in a parrot.mod
+// file, found in the directory of the script or one of its parents:
+//
+//	require github.com/google/uuid v1.6.0
+//
+//	require (
+//	    github.com/fatih/color v1.16.0
+//	)
+//
+// The modules are resolved offline, from the parrot.proxy directory next
+// to the project file if there is one, otherwise from GOMODCACHE. The
+// directory is laid out like a module proxy, as GOMODCACHE/cache/download
+// is, it is not a vendor directory of "go mod vendor".
+
+const (
+	PROJECT_FILE  = "parrot.mod"
+	PROJECT_PROXY = "parrot.proxy"
+)
+
+type TRequire struct {
+	Path    string
+	Version string
+}
+
+type TProject struct {
+	Path     string
+	Requires []TRequire
+}
+
+// Returns the module proxy directory of the project, or "" if it has none.
+func (project *TProject) Proxy() string {
+	proxy := filepath.Join(filepath.Dir(project.Path), PROJECT_PROXY)
+	if info, err := os.Stat(proxy); err == nil && info.IsDir() {
+		return proxy
+	}
+	return ""
+}
+
+// Finds the project file of the script in dir, or one of its parents.
+// Returns nil if there is none.
+func FindProject(dir string) *TProject {
+	for {
+		path := filepath.Join(dir, PROJECT_FILE)
+		if data, err := os.ReadFile(path); err == nil {
+			return ParseProject(path, string(data))
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil
+		}
+		dir = parent
+	}
+}
+
+func ParseProject(path string, data string) *TProject {
+	project := new(TProject)
+	project.Path = path
+	project.Requires = make([]TRequire, 0)
+	inBlock := false
+	for index, line := range strings.Split(data, "\n") {
+		if comment := strings.Index(line, "//"); comment >= 0 {
+			line = line[:comment]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if inBlock && fields[0] == ")" && len(fields) == 1 {
+			inBlock = false
+			continue
+		}
+		if !inBlock {
+			if fields[0] != "require" {
+				raiseProjectError(path, data, fmt.Sprintf("unknown directive %s", fields[0]), index+1, line)
+			}
+			fields = fields[1:]
+			if len(fields) == 1 && fields[0] == "(" {
+				inBlock = true
+				continue
+			}
+		}
+		if len(fields) != 2 || !strings.HasPrefix(fields[1], "v") {
+			raiseProjectError(path, data, "invalid require, expected a module path and a version", index+1, line)
+		}
+		project.Requires = append(project.Requires, TRequire{
+			Path:    fields[0],
+			Version: fields[1],
+		})
+	}
+	if inBlock {
+		raiseProjectError(path, data, "missing ) after require block", strings.Count(data, "\n")+1, "")
+	}
+	return project
+}
+
+func raiseProjectError(path string, data string, message string, line int, text string) {
+	RaiseLanguageCompileError(
+		path,
+		[]rune(data),
+		CODE_PROJECT_FILE,
+		message,
+		InitPosition(line, 1, line, len(text)+1),
+	)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"dev/types"
+	"fmt"
+	gotypes "go/types"
+)
+
+// A name in a script that the analyzer resolved, what the language
+// server answers hovers, definitions and completions from.
+type TReference struct {
+	File          string
+	Position      TPosition // Start of the name
+	Name          string
+	DataType      *types.TTyping
+	Symbol        *TSymbol       // Symbol the name refers to, nil for a member
+	Owner         *types.TTyping // Type the member or method belongs to, nil for a symbol
+	IsDeclaration bool
+}
+
+// A place in a script or a Go source file, lines and columns start at 1.
+type TLocation struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// The references of the files of a program.
+type TIndex struct {
+	References []TReference
+	seen       map[string]bool
+}
+
+func CreateIndex() *TIndex {
+	return &TIndex{
+		References: make([]TReference, 0),
+		seen:       make(map[string]bool),
+	}
+}
+
+// Records a reference, once for a declaration that is analyzed again.
+func (index *TIndex) Add(reference TReference) {
+	key := fmt.Sprintf("%s:%d:%d", reference.File, reference.Position.SLine, reference.Position.SColm)
+	if index.seen[key] {
+		return
+	}
+	index.seen[key] = true
+	index.References = append(index.References, reference)
+}
+
+// Returns the reference whose name covers line and column of file.
+func (index *TIndex) At(file string, line int, column int) (TReference, bool) {
+	for _, reference := range index.References {
+		start := reference.Position.SColm
+		if reference.File == file && reference.Position.SLine == line && column >= start && column < start+len([]rune(reference.Name)) {
+			return reference, true
+		}
+	}
+	return TReference{}, false
+}
+
+// Returns the reference whose name ends right before line and column
+// of file, the object of the member typed after it.
+func (index *TIndex) Before(file string, line int, column int) (TReference, bool) {
+	for _, reference := range index.References {
+		if reference.File == file && reference.Position.SLine == line && reference.Position.SColm+len([]rune(reference.Name)) == column {
+			return reference, true
+		}
+	}
+	return TReference{}, false
+}
+
+// Returns the declarations of file that start before line, the
+// locals that may be in scope there.
+func (index *TIndex) DeclaredBefore(file string, line int) []TReference {
+	declarations := make([]TReference, 0)
+	for _, reference := range index.References {
+		if reference.File == file && reference.IsDeclaration && reference.Symbol != nil && reference.Position.SLine <= line {
+			declarations = append(declarations, reference)
+		}
+	}
+	return declarations
+}
+
+// Returns where the name of a reference is declared, in a script or,
+// for a name imported from Go, in the sources of its package.
+func (index *TIndex) Definition(reference TReference) (TLocation, bool) {
+	if reference.Symbol != nil {
+		symbol := reference.Symbol
+		for symbol.Origin != nil {
+			symbol = symbol.Origin
+		}
+		if symbol.Object != nil {
+			return goLocation(symbol.Object)
+		}
+		file := symbol.File
+		if file == "" {
+			file = reference.File
+		}
+		return TLocation{File: file, Line: symbol.Position.SLine, Column: symbol.Position.SColm}, true
+	}
+	if reference.Owner == nil {
+		return TLocation{}, false
+	}
+	if goType := types.GoType(reference.Owner); goType != nil {
+		object, _, _ := gotypes.LookupFieldOrMethod(goType, true, nil, reference.Name)
+		return goLocation(object)
+	}
+	owner := ownerType(reference.Owner)
+	for _, declaration := range index.References {
+		if declaration.IsDeclaration && declaration.Owner != nil && declaration.Name == reference.Name && ownerType(declaration.Owner) == owner {
+			return TLocation{File: declaration.File, Line: declaration.Position.SLine, Column: declaration.Position.SColm}, true
+		}
+	}
+	return TLocation{}, false
+}
+
+func goLocation(object gotypes.Object) (TLocation, bool) {
+	position, ok := GoObjectPosition(object)
+	if !ok {
+		return TLocation{}, false
+	}
+	return TLocation{File: position.Filename, Line: position.Line, Column: position.Column}, true
+}
+
+// Returns the struct that a value of, or a pointer to, a struct has
+// its members from, any other type as it is.
+func ownerType(typing *types.TTyping) *types.TTyping {
+	if types.IsPointer(typing) && typing.GetInternal0() != nil {
+		typing = typing.GetInternal0()
+	}
+	if types.IsStructInstance(typing) && typing.GetInternal0() != nil {
+		typing = typing.GetInternal0()
+	}
+	return typing
+}
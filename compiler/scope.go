@@ -12,6 +12,9 @@ const (
 	ScopeLoop        TScopeType = iota
 	ScopeConditional TScopeType = iota
 	ScopeSingle      TScopeType = iota
+	ScopeSwitch      TScopeType = iota
+	ScopeSelect      TScopeType = iota
+	ScopeParallel    TScopeType = iota
 )
 
 type TScope struct {
@@ -21,6 +24,10 @@ type TScope struct {
 	Panics   bool
 	HasPanic bool
 	Return   *types.TTyping
+	// Declared return type of a function scope
+	ReturnType *types.TTyping
+	// Task group that a parallel scope waits for
+	Group string
 }
 
 func CreateScope(parent *TScope, scopeType TScopeType) *TScope {
@@ -96,6 +103,10 @@ func (scope *TScope) InLoop() bool {
 		if current.Type == ScopeFunction {
 			return false
 		}
+		// Leaving a parallel block early would skip waiting for its tasks
+		if current.Type == ScopeParallel {
+			return false
+		}
 		if current.Type == ScopeLoop {
 			return true
 		}
@@ -104,6 +115,49 @@ func (scope *TScope) InLoop() bool {
 	return false
 }
 
+func (scope *TScope) InCase() bool {
+	current := scope
+	for current != nil {
+		// A loop nested in a case owns its break statements
+		if current.Type == ScopeFunction || current.Type == ScopeLoop || current.Type == ScopeParallel {
+			return false
+		}
+		if current.Type == ScopeSwitch || current.Type == ScopeSelect {
+			return true
+		}
+		current = current.Parent
+	}
+	return false
+}
+
+// Returns the innermost parallel scope of the current function, or nil.
+func (scope *TScope) Parallel() *TScope {
+	current := scope
+	for current != nil && current.Type != ScopeFunction {
+		if current.Type == ScopeParallel {
+			return current
+		}
+		current = current.Parent
+	}
+	return nil
+}
+
+// Reports whether name is declared in the current function, rather than
+// captured from an enclosing one by a function literal.
+func (scope *TScope) DeclaresLocal(name string) bool {
+	current := scope
+	for current != nil {
+		if current.Env.HasLocalSymbol(name) {
+			return true
+		}
+		if current.Type == ScopeFunction {
+			return false
+		}
+		current = current.Parent
+	}
+	return false
+}
+
 func (scope *TScope) InConditional() bool {
 	current := scope
 	for current != nil {
@@ -2,23 +2,34 @@ package main
 
 import (
 	"dev/types"
+	"os"
 )
 
 type TState struct {
 	// The current state of the parser
-	Files     []TFileJob
-	TI08      *types.TTyping
-	TI16      *types.TTyping
-	TI32      *types.TTyping
-	TI64      *types.TTyping
-	TNum      *types.TTyping
-	TStr      *types.TTyping
-	TBit      *types.TTyping
-	TNil      *types.TTyping
-	TErr      *types.TTyping
-	TVoid     *types.TTyping
-	ListTypes []*TArrayElementTemplate // Array of types
-	MapTypes  []*TMapElementTemplate   // Map of types
+	Files       []TFileJob
+	TI08        *types.TTyping
+	TI16        *types.TTyping
+	TI32        *types.TTyping
+	TI64        *types.TTyping
+	TNum        *types.TTyping
+	TStr        *types.TTyping
+	TBit        *types.TTyping
+	TNil        *types.TTyping
+	TErr        *types.TTyping
+	TAny        *types.TTyping
+	TVoid       *types.TTyping
+	ListTypes   []*TArrayElementTemplate // Array of types
+	MapTypes    []*TMapElementTemplate   // Map of types
+	FutureTypes []*TFutureResultTemplate // Future of types
+	TaskGroup   bool                     // Whether a parallel block is used
+	LibPath     string                   // Directory of the "lib:" modules
+	Bridges     map[string]bool          // Go methods declared for ns structs, by "Struct.Method"
+	Package     string                   // Go package a library is emitted as, empty for a program
+	Exports     map[string]bool          // Go names of the exported functions and structs of a library
+	Sources     map[string]string        // Text of the files open in an editor, by path
+	Index       *TIndex                  // Names the analyzer resolves, nil unless the language server asks
+	GoTypes     *types.TGoTypes          // Converts the types of the Go packages the compile loads
 }
 
 func CreateState() *TState {
@@ -33,9 +44,15 @@ func CreateState() *TState {
 	state.TBit = types.TBool()
 	state.TNil = types.ToPointer(types.TVoid())
 	state.TErr = types.TError()
+	state.TAny = types.TAny()
 	state.TVoid = types.TVoid()
 	state.ListTypes = make([]*TArrayElementTemplate, 0)
 	state.MapTypes = make([]*TMapElementTemplate, 0)
+	state.FutureTypes = make([]*TFutureResultTemplate, 0)
+	state.Bridges = make(map[string]bool)
+	state.Exports = make(map[string]bool)
+	state.Sources = make(map[string]string)
+	state.GoTypes = types.CreateGoTypes()
 	return state
 }
 
@@ -62,6 +79,45 @@ func (state *TState) GetFile(path string) TFileJob {
 	return TFileJob{}
 }
 
+// Names a module the way scripts import it, "lib:io" for the
+// standard library and the file path for any other module.
+func (state *TState) GetModuleName(path string) string {
+	if state.LibPath != "" && GetDir(path) == state.LibPath {
+		return "lib:" + GetFileNameWithoutExtension(path)
+	}
+	return path
+}
+
+func (state *TState) IsLibrary() bool {
+	return state.Package != ""
+}
+
+// Names the Go package the files are emitted in.
+func (state *TState) PackageName() string {
+	if state.IsLibrary() {
+		return state.Package
+	}
+	return "main"
+}
+
+// Names a struct, function or method declared by node in the Go code.
+// A library keeps the names of what it exports readable, anything else
+// is prefixed with its module so that modules cannot collide.
+func (state *TState) DeclarationName(path string, node *TAst) string {
+	if state.IsLibrary() && node.Flg1 {
+		return ToExportedName(node.Ast0.Str0)
+	}
+	return JoinVariableName(GetFileNameWithoutExtension(path), node.Ast0.Str0)
+}
+
+// Reads a module, from the editor if it has the file open.
+func (state *TState) ReadFile(path string) ([]byte, error) {
+	if source, ok := state.Sources[path]; ok {
+		return []byte(source), nil
+	}
+	return os.ReadFile(path)
+}
+
 func (state *TState) ArrayTypeExists(t *types.TTyping) bool {
 	for _, arrayType := range state.ListTypes {
 		if arrayType.elementType.ToNormalName() == t.ToNormalName() {
@@ -93,14 +149,39 @@ func (state *TState) AddMapType(k *types.TTyping, v *types.TTyping) {
 	state.MapTypes = append(state.MapTypes, newTemplate)
 }
 
+func (state *TState) FutureTypeExists(t *types.TTyping) bool {
+	for _, futureType := range state.FutureTypes {
+		if futureType.resultType.ToNormalName() == t.ToNormalName() {
+			return true
+		}
+	}
+	return false
+}
+
+func (state *TState) HasBridge(structName string, method string) bool {
+	return state.Bridges[structName+"."+method]
+}
+
+func (state *TState) AddBridge(structName string, method string) {
+	state.Bridges[structName+"."+method] = true
+}
+
+func (state *TState) AddFutureType(t *types.TTyping) {
+	newTemplate := new(TFutureResultTemplate)
+	newTemplate.resultType = t
+	state.FutureTypes = append(state.FutureTypes, newTemplate)
+}
+
 func (state *TState) GenerateArrays() string {
-	code := "package main"
-	code += "\n\n"
-	code += "import ("
-	code += "\n\t\"strings\""
-	code += "\n\t\"fmt\""
-	code += "\n)"
+	code := "package " + state.PackageName()
 	code += "\n\n"
+	if len(state.ListTypes) > 0 {
+		code += "import ("
+		code += "\n\t\"strings\""
+		code += "\n\t\"fmt\""
+		code += "\n)"
+		code += "\n\n"
+	}
 	for _, arrayType := range state.ListTypes {
 		code += GenerateArrayCode(arrayType.elementType)
 		code += "\n\n"
@@ -110,15 +191,33 @@ func (state *TState) GenerateArrays() string {
 }
 
 func (state *TState) GenerateMaps() string {
-	code := "package main"
-	code += "\n\n"
-	code += "import ("
-	code += "\n\t\"fmt\""
-	code += "\n)"
+	code := "package " + state.PackageName()
 	code += "\n\n"
+	if len(state.MapTypes) > 0 {
+		code += "import ("
+		code += "\n\t\"fmt\""
+		code += "\n)"
+		code += "\n\n"
+	}
 	for _, mapType := range state.MapTypes {
 		code += GenerateMapCode(mapType.keyType, mapType.valueType)
 		code += "\n\n"
 	}
 	return code
 }
+
+func (state *TState) GenerateFutures() string {
+	code := "package " + state.PackageName()
+	code += "\n\n"
+	if state.TaskGroup {
+		code += "import \"sync\""
+		code += "\n\n"
+		code += TaskGroupCode
+		code += "\n\n"
+	}
+	for _, futureType := range state.FutureTypes {
+		code += GenerateFutureCode(futureType.resultType)
+		code += "\n\n"
+	}
+	return code
+}
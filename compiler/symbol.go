@@ -1,6 +1,9 @@
 package main
 
-import "dev/types"
+import (
+	"dev/types"
+	gotypes "go/types"
+)
 
 type TSymbol struct {
 	Name         string
@@ -12,4 +15,11 @@ type TSymbol struct {
 	IsConst      bool
 	IsUsed       bool
 	IsInitialize bool
+	IsTask       bool           // Future started in a parallel block, it can only be awaited
+	Value        interface{}    // Compile-time value of a constant, nil if unknown
+	Members      *TEnv          // Symbols of a namespace import, nil for any other symbol
+	Package      string         // Go package path of a namespace import or a generic function, empty for a ns module
+	File         string         // Script the symbol is declared in, empty for a local
+	Origin       *TSymbol       // Symbol an imported or narrowed symbol stands for
+	Object       gotypes.Object // Go object a symbol imported from Go names
 }
@@ -10,6 +10,7 @@ const (
 	TokenSTR TTokenType = iota
 	TokenSYM TTokenType = iota
 	TokenEOF TTokenType = iota
+	TokenCMT TTokenType = iota
 )
 
 type TToken struct {
@@ -34,6 +35,8 @@ func GetTokenTypeName(tokenType TTokenType) string {
 		return "symbol"
 	case TokenEOF:
 		return "end of file"
+	case TokenCMT:
+		return "comment"
 	default:
 		return "unknown"
 	}
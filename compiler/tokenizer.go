@@ -15,6 +15,9 @@ type TTokenizer struct {
 	indx int
 	line int
 	colm int
+	// Line comments, in the order they appear. They are not tokens
+	// of the grammar, the parser reads them as documentation.
+	Comments []TToken
 }
 
 // API:Export
@@ -85,6 +88,12 @@ func (tokenizer *TTokenizer) isStr() bool {
 	return tokenizer.look == '"'
 }
 
+func (tokenizer *TTokenizer) isCmt() bool {
+	return tokenizer.look == '/' &&
+		tokenizer.indx+1 < tokenizer.size &&
+		tokenizer.Data[tokenizer.indx+1] == '/'
+}
+
 func (tokenizer *TTokenizer) ignWht() {
 	for !tokenizer.IsEof() && tokenizer.isWht() {
 		tokenizer.forward()
@@ -101,6 +110,7 @@ func (tokenizer *TTokenizer) getIdn() TToken {
 		RaiseLanguageCompileError(
 			tokenizer.File,
 			tokenizer.Data,
+			CODE_INVALID_CHARACTER,
 			fmt.Sprintf("invalid identifier start %s", string(tokenizer.look)),
 			position,
 		)
@@ -130,6 +140,7 @@ func (tokenizer *TTokenizer) getNum() TToken {
 		RaiseLanguageCompileError(
 			tokenizer.File,
 			tokenizer.Data,
+			CODE_INVALID_CHARACTER,
 			fmt.Sprintf("invalid number start %s", string(tokenizer.look)),
 			position,
 		)
@@ -147,6 +158,7 @@ func (tokenizer *TTokenizer) getNum() TToken {
 				RaiseLanguageCompileError(
 					tokenizer.File,
 					tokenizer.Data,
+					CODE_INVALID_NUMBER,
 					fmt.Sprintf("invalid hex number %s", value),
 					position,
 				)
@@ -160,6 +172,7 @@ func (tokenizer *TTokenizer) getNum() TToken {
 				RaiseLanguageCompileError(
 					tokenizer.File,
 					tokenizer.Data,
+					CODE_INVALID_NUMBER,
 					fmt.Sprintf("invalid hex number %s", value),
 					position,
 				)
@@ -172,6 +185,7 @@ func (tokenizer *TTokenizer) getNum() TToken {
 				RaiseLanguageCompileError(
 					tokenizer.File,
 					tokenizer.Data,
+					CODE_INVALID_NUMBER,
 					fmt.Sprintf("invalid oct number %s", value),
 					position,
 				)
@@ -185,6 +199,7 @@ func (tokenizer *TTokenizer) getNum() TToken {
 				RaiseLanguageCompileError(
 					tokenizer.File,
 					tokenizer.Data,
+					CODE_INVALID_NUMBER,
 					fmt.Sprintf("invalid oct number %s", value),
 					position,
 				)
@@ -197,6 +212,7 @@ func (tokenizer *TTokenizer) getNum() TToken {
 				RaiseLanguageCompileError(
 					tokenizer.File,
 					tokenizer.Data,
+					CODE_INVALID_NUMBER,
 					fmt.Sprintf("invalid bin number %s", value),
 					position,
 				)
@@ -210,6 +226,7 @@ func (tokenizer *TTokenizer) getNum() TToken {
 				RaiseLanguageCompileError(
 					tokenizer.File,
 					tokenizer.Data,
+					CODE_INVALID_NUMBER,
 					fmt.Sprintf("invalid bin number %s", value),
 					position,
 				)
@@ -235,6 +252,7 @@ func (tokenizer *TTokenizer) getNum() TToken {
 			RaiseLanguageCompileError(
 				tokenizer.File,
 				tokenizer.Data,
+				CODE_INVALID_NUMBER,
 				fmt.Sprintf("invalid number %s", value),
 				position,
 			)
@@ -257,6 +275,7 @@ func (tokenizer *TTokenizer) getNum() TToken {
 			RaiseLanguageCompileError(
 				tokenizer.File,
 				tokenizer.Data,
+				CODE_INVALID_NUMBER,
 				fmt.Sprintf("invalid number %s", value),
 				position,
 			)
@@ -283,6 +302,7 @@ func (tokenizer *TTokenizer) getStr() TToken {
 		RaiseLanguageCompileError(
 			tokenizer.File,
 			tokenizer.Data,
+			CODE_INVALID_CHARACTER,
 			fmt.Sprintf("invalid string start \"%s\"", string(tokenizer.look)),
 			position,
 		)
@@ -326,6 +346,7 @@ func (tokenizer *TTokenizer) getStr() TToken {
 		RaiseLanguageCompileError(
 			tokenizer.File,
 			tokenizer.Data,
+			CODE_INVALID_CHARACTER,
 			fmt.Sprintf("string not properly closed \"%s\"", value),
 			position,
 		)
@@ -380,7 +401,7 @@ func (tokenizer *TTokenizer) getSym() TToken {
 	case '/':
 		value += string(tokenizer.look)
 		tokenizer.forward()
-		if tokenizer.look == '/' {
+		if tokenizer.look == '=' {
 			value += string(tokenizer.look)
 			tokenizer.forward()
 		}
@@ -410,6 +431,11 @@ func (tokenizer *TTokenizer) getSym() TToken {
 	case '<':
 		value += string(tokenizer.look)
 		tokenizer.forward()
+		if tokenizer.look == '-' {
+			value += string(tokenizer.look)
+			tokenizer.forward()
+			break
+		}
 		if tokenizer.look == '<' {
 			value += string(tokenizer.look)
 			tokenizer.forward()
@@ -477,6 +503,7 @@ func (tokenizer *TTokenizer) getSym() TToken {
 		RaiseLanguageCompileError(
 			tokenizer.File,
 			tokenizer.Data,
+			CODE_INVALID_CHARACTER,
 			fmt.Sprintf("invalid symbol %s", string(tokenizer.look)),
 			position,
 		)
@@ -488,6 +515,26 @@ func (tokenizer *TTokenizer) getSym() TToken {
 	}
 }
 
+// Reads a comment up to the end of its line, without the leading "//".
+func (tokenizer *TTokenizer) getCmt() TToken {
+	position := InitPositionFromLineAndColm(
+		tokenizer.line,
+		tokenizer.colm,
+	)
+	tokenizer.forward()
+	tokenizer.forward()
+	value := ""
+	for !tokenizer.IsEof() && tokenizer.look != '\n' {
+		value += string(tokenizer.look)
+		tokenizer.forward()
+	}
+	return TToken{
+		Type:     TokenCMT,
+		Value:    value,
+		Position: position,
+	}
+}
+
 func (tokenizer *TTokenizer) getEof() TToken {
 	position := InitPositionFromLineAndColm(
 		tokenizer.line,
@@ -502,9 +549,34 @@ func (tokenizer *TTokenizer) getEof() TToken {
 
 // API:Export
 func (tokenizer *TTokenizer) Next() TToken {
+	for {
+		if token, ok := tokenizer.scan(); ok {
+			return token
+		}
+	}
+}
+
+// Scans the next token. An invalid token is reported and skipped,
+// at least one character of it, then the scan is not ok.
+func (tokenizer *TTokenizer) scan() (token TToken, ok bool) {
+	start := tokenizer.indx
+	defer func() {
+		if RecoverAbort(recover()) {
+			if tokenizer.indx == start {
+				tokenizer.forward()
+			}
+			ok = false
+		}
+	}()
+	return tokenizer.next(), true
+}
+
+func (tokenizer *TTokenizer) next() TToken {
 	for !tokenizer.IsEof() {
 		if tokenizer.isWht() {
 			tokenizer.ignWht()
+		} else if tokenizer.isCmt() {
+			tokenizer.Comments = append(tokenizer.Comments, tokenizer.getCmt())
 		} else if tokenizer.isIdn() {
 			return tokenizer.getIdn()
 		} else if tokenizer.isNum() {
@@ -9,35 +9,11 @@ import (
 	"golang.org/x/text/language"
 )
 
-var pascalCaseRegex = regexp.MustCompile(`^[A-Z][a-z0-9]*(?:[A-Z][a-z0-9]*)*$`)
-
-var camelCaseRegex = regexp.MustCompile(`^[a-z][a-z0-9]*(?:[A-Z][a-z0-9]*)*$`)
-
-var snakeCaseRegex = regexp.MustCompile(`^[a-z][a-z0-9]*(?:_[a-z][a-z0-9]*)*$`)
-
-var capitalCaseRegex = regexp.MustCompile(`^[A-Z][A-Z0-9]*$`)
-
 var isFunctionCallRegex = regexp.MustCompile(`^[a-zA-Z0-9_]+\([^\)]*\)$`)
 
 // Private attributes is nag start with double underscore and not end with underscore, like python
 var isPrivateAttributeRegex = regexp.MustCompile(`^__[a-z][a-z0-9]*(?:_[a-z][a-z0-9]*)*$`)
 
-func IsPascalCase(s string) bool {
-	return pascalCaseRegex.MatchString(s)
-}
-
-func IsCamelCase(s string) bool {
-	return camelCaseRegex.MatchString(s)
-}
-
-func IsSnakeCase(s string) bool {
-	return snakeCaseRegex.MatchString(s)
-}
-
-func IsCapitalCase(s string) bool {
-	return capitalCaseRegex.MatchString(s)
-}
-
 func IsFunctionCall(s string) bool {
 	return isFunctionCallRegex.MatchString(s)
 }
@@ -50,6 +26,14 @@ func ToPascalCase(s string) string {
 	return cases.Title(language.Und).String(s)
 }
 
+// Capitalizes the first letter, so that Go exports the name.
+func ToExportedName(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
 func ToSnakeCase(s string) string {
 	// Convert PascalCase or camelCase to snake_case
 	var result strings.Builder
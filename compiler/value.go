@@ -5,6 +5,7 @@ import "dev/types"
 type TValue struct {
 	DataType *types.TTyping
 	Data     interface{}
+	Untyped  bool // A literal, or folded from literals only, which Go types from its context
 }
 
 func CreateValue(dataType *types.TTyping, data interface{}) TValue {
@@ -14,3 +15,9 @@ func CreateValue(dataType *types.TTyping, data interface{}) TValue {
 	}
 	return value
 }
+
+func CreateUntypedValue(dataType *types.TTyping, data interface{}) TValue {
+	value := CreateValue(dataType, data)
+	value.Untyped = true
+	return value
+}
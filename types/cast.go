@@ -0,0 +1,72 @@
+package types
+
+import "go/types"
+
+// ===================================
+//        Explicit Conversions       //
+// ===================================
+
+type TCastKind int
+
+const (
+	CastInvalid    TCastKind = iota
+	CastIdentity             // Stored as is, the cast only states the type
+	CastNumeric              // Between i8, i16, i32, i64 and num, may narrow
+	CastStrToBytes           // str to [i8]
+	CastBytesToStr           // [i8] to str
+	CastNominal              // Between distinct types with the same structure
+	CastAssertion            // From any to the type it holds, panics otherwise
+)
+
+// GetCastKind returns how an "expr as T" cast converts src into dst,
+// or CastInvalid if the conversion is not allowed.
+func GetCastKind(dst *TTyping, src *TTyping) TCastKind {
+	// Numbers convert freely, narrowing truncates like Go
+	if IsAnyNumber(dst) && IsAnyNumber(src) {
+		return CastNumeric
+	}
+	if IsArray(dst) && IsInt08(dst.internal0) && IsStr(src) {
+		return CastStrToBytes
+	}
+	if IsStr(dst) && IsArray(src) && IsInt08(src.internal0) {
+		return CastBytesToStr
+	}
+	if IsStr(dst) && IsGoBytes(src) {
+		return CastBytesToStr
+	}
+	if CanStore(dst, src) {
+		return CastIdentity
+	}
+	if IsAny(src) && !IsVoid(dst) {
+		return CastAssertion
+	}
+	if IsStructInstance(dst) && IsStructInstance(src) && HasSameMembers(dst, src) {
+		return CastNominal
+	}
+	// Go named types follow Go's own conversion rules
+	if dst.compat != nil && src.compat != nil && types.ConvertibleTo(src.compat, dst.compat) {
+		return CastNominal
+	}
+	return CastInvalid
+}
+
+// IsGoBytes reports whether t is a Go []byte, as os.ReadFile returns,
+// which converts to str the way Go converts it.
+func IsGoBytes(t *TTyping) bool {
+	return IsGoArray(t) && t.compat != nil && types.Identical(t.compat, types.NewSlice(types.Typ[types.Byte]))
+}
+
+// HasSameMembers reports whether two structs declare
+// the same members, with the same types, in the same order.
+func HasSameMembers(a *TTyping, b *TTyping) bool {
+	if len(a.members) != len(b.members) {
+		return false
+	}
+	for i, member := range a.members {
+		other := b.members[i]
+		if member.Name != other.Name || !IsTheSameInstance(member.DataType, other.DataType) {
+			return false
+		}
+	}
+	return true
+}
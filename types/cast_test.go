@@ -0,0 +1,89 @@
+package types
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+)
+
+// Type checks a Go package of one file, whose declarations the tests
+// convert the way an import of the script does.
+func goPackage(t *testing.T, source string) *types.Package {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", "package p\n"+source, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := new(types.Config).Check("p", fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkg
+}
+
+// Converts the declaration name of pkg, the instance for a struct.
+func goDeclared(pkg *types.Package, name string) *TTyping {
+	converted := TFromGoTypes(pkg.Scope().Lookup(name).Type())
+	if IsStruct(converted) {
+		return ToInstance(converted)
+	}
+	return converted
+}
+
+func TestGetCastKind(t *testing.T) {
+	pkg := goPackage(t, `
+type Celsius float64
+type P struct{ X int }
+type Q struct{ X int }
+type Reader interface{ Read(p []byte) (int, error) }
+var Bytes []byte
+var Block [4]byte
+var Signed []int8
+`)
+	point := ToInstance(TStruct("Point", []*TPair{CreatePair("X", TInt64()), CreatePair("Y", TInt64())}))
+	vector := ToInstance(TStruct("Vector", []*TPair{CreatePair("X", TInt64()), CreatePair("Y", TInt64())}))
+	swapped := ToInstance(TStruct("Swapped", []*TPair{CreatePair("Y", TInt64()), CreatePair("X", TInt64())}))
+	tests := []struct {
+		name     string
+		dst      *TTyping
+		src      *TTyping
+		expected TCastKind
+	}{
+		{"i32 as i8", TInt08(), TInt32(), CastNumeric},
+		{"i8 as i64", TInt64(), TInt08(), CastNumeric},
+		{"num as i32", TInt32(), TNum(), CastNumeric},
+		{"i64 as num", TNum(), TInt64(), CastNumeric},
+		{"num as Celsius", goDeclared(pkg, "Celsius"), TNum(), CastNumeric},
+		{"str as [i8]", TArray(TInt08()), TStr(), CastStrToBytes},
+		{"[i8] as str", TStr(), TArray(TInt08()), CastBytesToStr},
+		{"str as [i16]", TArray(TInt16()), TStr(), CastInvalid},
+		{"[i32] as str", TStr(), TArray(TInt32()), CastInvalid},
+		{"Go []byte as str", TStr(), goDeclared(pkg, "Bytes"), CastBytesToStr},
+		{"Go [4]byte as str", TStr(), goDeclared(pkg, "Block"), CastInvalid},
+		{"Go []int8 as str", TStr(), goDeclared(pkg, "Signed"), CastInvalid},
+		{"str as str", TStr(), TStr(), CastIdentity},
+		{"i32 as any", TAny(), TInt32(), CastIdentity},
+		{"Point as Point", point, point, CastIdentity},
+		{"any as i32", TInt32(), TAny(), CastAssertion},
+		{"any as Point", point, TAny(), CastAssertion},
+		{"any as Reader", goDeclared(pkg, "Reader"), TAny(), CastAssertion},
+		{"any as void", TVoid(), TAny(), CastInvalid},
+		{"Vector as Point", point, vector, CastNominal},
+		{"Swapped as Point", point, swapped, CastInvalid},
+		{"Q as P", goDeclared(pkg, "P"), goDeclared(pkg, "Q"), CastNominal},
+		{"i32 as str", TStr(), TInt32(), CastInvalid},
+		{"str as i32", TInt32(), TStr(), CastInvalid},
+		{"bool as i32", TInt32(), TBool(), CastInvalid},
+		{"[i32] as [i64]", TArray(TInt64()), TArray(TInt32()), CastInvalid},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := GetCastKind(test.dst, test.src); actual != test.expected {
+				t.Errorf("cast kind %d, expected %d", actual, test.expected)
+			}
+		})
+	}
+}
@@ -9,6 +9,12 @@ func IsAny(ttype *TTyping) bool {
 	return ttype.typeId == TypeAny
 }
 
+// Any values imported from a Go interface type keep that interface
+// as their static Go type instead of "any".
+func IsGoInterface(ttype *TTyping) bool {
+	return IsAny(ttype) && ttype.compat != nil
+}
+
 func IsInt08(ttype *TTyping) bool {
 	return ttype.typeId == TypeI08
 }
@@ -56,6 +62,10 @@ func IsVoid(ttype *TTyping) bool {
 	return ttype.typeId == TypeNil
 }
 
+func IsInvalid(ttype *TTyping) bool {
+	return ttype.typeId == TypeInvalid
+}
+
 func IsError(ttype *TTyping) bool {
 	return ttype.typeId == TypeErr
 }
@@ -68,6 +78,16 @@ func IsMap(ttype *TTyping) bool {
 	return ttype.typeId == TypeMap
 }
 
+// Slices and maps imported from Go, which Parrot Script
+// converts to arrays and maps where they cross into a script.
+func IsGoArray(ttype *TTyping) bool {
+	return ttype.typeId == TypeGoArray
+}
+
+func IsGoMap(ttype *TTyping) bool {
+	return ttype.typeId == TypeGoMap
+}
+
 func IsStruct(ttype *TTyping) bool {
 	return ttype.typeId == TypeStruct
 }
@@ -76,10 +96,93 @@ func IsStructInstance(ttype *TTyping) bool {
 	return ttype.typeId == TypeStructInstance
 }
 
+// Instances of a struct declared in a script, or pointers to them,
+// as opposed to the ones imported from Go.
+func IsScriptStruct(ttype *TTyping) bool {
+	if IsPointer(ttype) && ttype.internal0 != nil {
+		ttype = ttype.internal0
+	}
+	return IsStructInstance(ttype) && ttype.compat == nil
+}
+
+// Returns the Go interface of a value imported from Go, if it has methods.
+func GoInterface(ttype *TTyping) *types.Interface {
+	if !IsStructInstance(ttype) || ttype.compat == nil {
+		return nil
+	}
+	iface, _ := ttype.compat.Underlying().(*types.Interface)
+	return iface
+}
+
+// Checks that the struct of the script src has the methods of the Go
+// interface dst. Go calls them with Go values, which the methods must
+// take as the script converts them, a []byte as [i8] and an int as i64.
+func ImplementsGo(dst *TTyping, src *TTyping) error {
+	iface := GoInterface(dst)
+	for i := 0; i < iface.NumMethods(); i++ {
+		goMethod := iface.Method(i)
+		if !goMethod.Exported() {
+			return fmt.Errorf("%s has unexported method %s", dst.ToString(), goMethod.Name())
+		}
+		if !src.HasMethod(goMethod.Name()) {
+			return fmt.Errorf("%s has no method %s", src.ToString(), goMethod.Name())
+		}
+		method := src.GetMethod(goMethod.Name()).DataType
+		goType := TFromGoTypes(goMethod.Type())
+		if goType.variadic || !sameInGo(goType, method) {
+			return fmt.Errorf("method %s is %s, %s needs %s", goMethod.Name(), method.ToString(), dst.ToString(), goType.ToString())
+		}
+		if method.pointer && !IsPointer(src) {
+			return fmt.Errorf("method %s has a pointer receiver, only %s* implements %s", goMethod.Name(), src.ToString(), dst.ToString())
+		}
+	}
+	return nil
+}
+
+// Reports whether the Go type goType becomes the type ttype in a script.
+func sameInGo(goType *TTyping, ttype *TTyping) bool {
+	switch {
+	case IsGoArray(goType):
+		return IsArray(ttype) && sameInGo(goType.internal0, ttype.internal0)
+	case IsGoMap(goType):
+		return IsMap(ttype) && sameInGo(goType.internal0, ttype.internal0) && sameInGo(goType.internal1, ttype.internal1)
+	case IsTuple(goType):
+		if !IsTuple(ttype) || len(goType.elements) != len(ttype.elements) {
+			return false
+		}
+		for index, element := range goType.elements {
+			if !sameInGo(element, ttype.elements[index]) {
+				return false
+			}
+		}
+		return true
+	case IsFunc(goType):
+		if !IsFunc(ttype) || len(goType.members) != len(ttype.members) || ttype.panics {
+			return false
+		}
+		for index, member := range goType.members {
+			if !sameInGo(member.DataType, ttype.members[index].DataType) {
+				return false
+			}
+		}
+		return sameInGo(goType.internal0, ttype.internal0)
+	default:
+		return IsTheSameInstance(goType, ttype)
+	}
+}
+
 func IsFunc(ttype *TTyping) bool {
 	return ttype.typeId == TypeFunc
 }
 
+func IsChan(ttype *TTyping) bool {
+	return ttype.typeId == TypeChan
+}
+
+func IsFuture(ttype *TTyping) bool {
+	return ttype.typeId == TypeFuture
+}
+
 func IsTuple(ttype *TTyping) bool {
 	return ttype.typeId == TypeTuple
 }
@@ -109,6 +212,8 @@ func IsValidKey(ttype *TTyping) bool {
 		TypeStr,
 		TypeBit:
 		return true
+	case TypeStructInstance:
+		return IsComparable(ttype)
 	case TypeAny:
 	case TypeNil:
 	case TypeArray:
@@ -120,6 +225,54 @@ func IsValidKey(ttype *TTyping) bool {
 	return false
 }
 
+// IsComparable returns true if values of the type can be compared
+// structurally with "==" and hashed, so they can be used as map keys.
+// Arrays, maps and functions are not comparable.
+func IsComparable(ttype *TTyping) bool {
+	return isComparableWithVisited(ttype, make(map[*TTyping]bool))
+}
+
+// GetNonComparableMember returns the first member of a struct
+// that prevents it from being compared, or nil if there is none.
+func GetNonComparableMember(ttype *TTyping) *TPair {
+	return getNonComparableMemberWithVisited(ttype, make(map[*TTyping]bool))
+}
+
+func isComparableWithVisited(ttype *TTyping, visited map[*TTyping]bool) bool {
+	if IsPointer(ttype) {
+		return true
+	}
+	switch ttype.typeId {
+	case TypeI08,
+		TypeI16,
+		TypeI32,
+		TypeI64,
+		TypeNum,
+		TypeStr,
+		TypeBit,
+		TypeErr,
+		TypeChan:
+		return true
+	case TypeStructInstance:
+		return getNonComparableMemberWithVisited(ttype, visited) == nil
+	}
+	return false
+}
+
+func getNonComparableMemberWithVisited(ttype *TTyping, visited map[*TTyping]bool) *TPair {
+	// Break cycles, the cycle itself is reported by the analyzer
+	if visited[ttype] {
+		return nil
+	}
+	visited[ttype] = true
+	for _, member := range ttype.members {
+		if !isComparableWithVisited(member.DataType, visited) {
+			return member
+		}
+	}
+	return nil
+}
+
 func IsValidElementType(ttype *TTyping) bool {
 	switch ttype.typeId {
 	case TypeI08:
@@ -212,12 +365,13 @@ func CanStore(dst *TTyping, src *TTyping) bool {
 		return true
 	}
 
-	// Handle array types
+	// Handle array types, elements must match exactly
+	// because every element type has its own wrapper
 	if IsArray(dst) && IsArray(src) {
 		if dst.internal0 == nil || src.internal0 == nil {
 			return false
 		}
-		return CanStore(dst.internal0, src.internal0)
+		return IsTheSameInstance(dst.internal0, src.internal0)
 	}
 
 	// Handle map types
@@ -228,7 +382,23 @@ func CanStore(dst *TTyping, src *TTyping) bool {
 			src.internal1 == nil {
 			return false
 		}
-		return CanStore(dst.internal0, src.internal0) && CanStore(dst.internal1, src.internal1)
+		return IsTheSameInstance(dst.internal0, src.internal0) && IsTheSameInstance(dst.internal1, src.internal1)
+	}
+
+	// Handle channel types, a channel can lose a direction but not gain one
+	if IsChan(dst) && IsChan(src) {
+		if src.direction != ChanBoth && src.direction != dst.direction {
+			return false
+		}
+		return IsTheSameInstance(dst.internal0, src.internal0)
+	}
+
+	// Handle future types, a future that may panic cannot pass for one that does not
+	if IsFuture(dst) && IsFuture(src) {
+		if src.panics && !dst.panics {
+			return false
+		}
+		return IsTheSameInstance(dst.internal0, src.internal0)
 	}
 
 	// Handle tuple types
@@ -262,8 +432,11 @@ func CanStore(dst *TTyping, src *TTyping) bool {
 		}
 		return CanStore(dst.internal0, src.internal0)
 	}
+	// A struct of the script implements a Go interface with its methods
+	if GoInterface(dst) != nil && IsScriptStruct(src) {
+		return ImplementsGo(dst, src) == nil
+	}
 	if dst.compat != nil && src.compat != nil {
-		fmt.Println(dst.compat, src.compat, types.AssignableTo(dst.compat, src.compat))
 		return types.AssignableTo(src.compat, dst.compat)
 	}
 
@@ -413,3 +586,99 @@ func CanDoArithmetic(opt string, a *TTyping, b *TTyping) bool {
 	}
 	return false
 }
+
+// ===================================
+//        Operator Methods           //
+// ===================================
+
+const (
+	OperatorAdd  = "Add"
+	OperatorSub  = "Sub"
+	OperatorMul  = "Mul"
+	OperatorDiv  = "Div"
+	OperatorMod  = "Mod"
+	OperatorEq   = "Eq"
+	OperatorLess = "Less"
+)
+
+// GetOperatorMethodName returns the reserved method name that
+// implements the given binary operator for struct instances.
+// "!=" is derived from "Eq", and ">", "<=", ">=" are derived from "Less".
+func GetOperatorMethodName(opt string) string {
+	switch opt {
+	case "+":
+		return OperatorAdd
+	case "-":
+		return OperatorSub
+	case "*":
+		return OperatorMul
+	case "/":
+		return OperatorDiv
+	case "%":
+		return OperatorMod
+	case "==", "!=":
+		return OperatorEq
+	case "<", ">", "<=", ">=":
+		return OperatorLess
+	}
+	return ""
+}
+
+func IsOperatorMethodName(name string) bool {
+	switch name {
+	case OperatorAdd,
+		OperatorSub,
+		OperatorMul,
+		OperatorDiv,
+		OperatorMod,
+		OperatorEq,
+		OperatorLess:
+		return true
+	}
+	return false
+}
+
+// IsComparisonMethodName returns true if the operator method must return bool.
+func IsComparisonMethodName(name string) bool {
+	return name == OperatorEq || name == OperatorLess
+}
+
+// GetOperatorMethod returns the method that overloads opt for the
+// given operands, or nil if the operands do not opt into the operator.
+func GetOperatorMethod(opt string, a *TTyping, b *TTyping) *TPair {
+	if !IsStructInstance(a) || !IsStructInstance(b) {
+		return nil
+	}
+	name := GetOperatorMethodName(opt)
+	if name == "" || !a.HasMethod(name) || len(a.GetMethod(name).DataType.members) != 1 {
+		return nil
+	}
+	return a.GetMethod(name)
+}
+
+// CanOverloadOperator checks the operand and return types of an operator method.
+func CanOverloadOperator(opt string, a *TTyping, b *TTyping) bool {
+	method := GetOperatorMethod(opt, a, b)
+	if method == nil || !IsFunc(method.DataType) {
+		return false
+	}
+	params := method.DataType.GetMembers()
+	if len(params) != 1 || method.DataType.Variadic() {
+		return false
+	}
+	// For ">" and "<=", the operands are swapped: b.Less(a)
+	operand := b
+	if opt == ">" || opt == "<=" {
+		if !IsTheSameInstance(a, b) {
+			return false
+		}
+		operand = a
+	}
+	if !CanStore(params[0].DataType, operand) {
+		return false
+	}
+	if IsComparisonMethodName(method.Name) {
+		return IsBool(method.DataType.GetReturnType())
+	}
+	return !IsVoid(method.DataType.GetReturnType())
+}
@@ -0,0 +1,113 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+const checkerSource = `
+type Stringer interface{ String() string }
+type Reader interface{ Read(p []byte) (int, error) }
+type Logger interface{ Log(args ...any) }
+type Hidden interface{ String() string; m() }
+
+var Bytes []byte
+var Names []string
+var Counts map[string]int
+var Format func(int) string
+var Parse func(string) (int, error)
+`
+
+// Declares a struct of the script with the given methods, on its
+// instance and on the pointer to it.
+func scriptStruct(name string, methods map[string]*TTyping) *TTyping {
+	instance := ToInstance(TStruct(name, []*TPair{}))
+	for method, dataType := range methods {
+		AddReceiverMethod(instance, method, method, dataType)
+	}
+	return instance
+}
+
+func method(parameters []*TTyping, returnType *TTyping, panics bool) *TTyping {
+	pairs := make([]*TPair, len(parameters))
+	for index, parameter := range parameters {
+		pairs[index] = CreatePair("p", parameter)
+	}
+	return TFunc(false, pairs, returnType, panics)
+}
+
+func TestImplementsGo(t *testing.T) {
+	pkg := goPackage(t, checkerSource)
+	readResult := TTuple([]*TTyping{TInt64(), TError()})
+	tests := []struct {
+		name    string
+		iface   string
+		src     *TTyping
+		pointer bool
+		err     string
+	}{
+		{"method", "Stringer", scriptStruct("Name", map[string]*TTyping{"String": method(nil, TStr(), false)}), false, ""},
+		{"extra methods", "Stringer", scriptStruct("Name", map[string]*TTyping{"String": method(nil, TStr(), false), "Len": method(nil, TInt64(), false)}), false, ""},
+		{"no method", "Stringer", scriptStruct("Name", map[string]*TTyping{"Len": method(nil, TInt64(), false)}), false, "Name{} has no method String"},
+		{"other result", "Stringer", scriptStruct("Name", map[string]*TTyping{"String": method(nil, TInt32(), false)}), false, "method String is func() i32"},
+		{"other parameters", "Stringer", scriptStruct("Name", map[string]*TTyping{"String": method([]*TTyping{TStr()}, TStr(), false)}), false, "method String is func(str) str"},
+		{"panics", "Stringer", scriptStruct("Name", map[string]*TTyping{"String": method(nil, TStr(), true)}), false, "method String is func() str panics"},
+		{"converted types", "Reader", scriptStruct("File", map[string]*TTyping{"Read": method([]*TTyping{TArray(TInt08())}, readResult, false)}), false, ""},
+		{"Go slice", "Reader", scriptStruct("File", map[string]*TTyping{"Read": method([]*TTyping{TGoArray(TInt08())}, readResult, false)}), false, "method Read is"},
+		{"variadic", "Logger", scriptStruct("Log", map[string]*TTyping{"Log": method([]*TTyping{TArray(TAny())}, TVoid(), false)}), false, "method Log is"},
+		{"unexported method", "Hidden", scriptStruct("Name", map[string]*TTyping{"String": method(nil, TStr(), false), "m": method(nil, TVoid(), false)}), false, "Hidden{} has unexported method m"},
+		{"pointer receiver", "Stringer", scriptStruct("Name", map[string]*TTyping{"String": SetPointerReceiver(method(nil, TStr(), false), true)}), false, "only Name{}* implements Stringer{}"},
+		{"pointer receiver of a pointer", "Stringer", scriptStruct("Name", map[string]*TTyping{"String": SetPointerReceiver(method(nil, TStr(), false), true)}), true, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			src := test.src
+			if test.pointer {
+				src = ToPointer(src)
+			}
+			err := ImplementsGo(goDeclared(pkg, test.iface), src)
+			if test.err == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("error %v, expected one with %q", err, test.err)
+			}
+		})
+	}
+}
+
+func TestSameInGo(t *testing.T) {
+	pkg := goPackage(t, checkerSource)
+	tests := []struct {
+		name     string
+		goType   *TTyping
+		ttype    *TTyping
+		expected bool
+	}{
+		{"[]byte as [i8]", goDeclared(pkg, "Bytes"), TArray(TInt08()), true},
+		{"[]byte as []i8", goDeclared(pkg, "Bytes"), TGoArray(TInt08()), false},
+		{"[]byte as [i16]", goDeclared(pkg, "Bytes"), TArray(TInt16()), false},
+		{"[]string as [str]", goDeclared(pkg, "Names"), TArray(TStr()), true},
+		{"map as map", goDeclared(pkg, "Counts"), THashMap(TStr(), TInt64()), true},
+		{"map of other values", goDeclared(pkg, "Counts"), THashMap(TStr(), TInt32()), false},
+		{"map as array", goDeclared(pkg, "Counts"), TArray(TInt64()), false},
+		{"function", goDeclared(pkg, "Format"), method([]*TTyping{TInt64()}, TStr(), false), true},
+		{"panicking function", goDeclared(pkg, "Format"), method([]*TTyping{TInt64()}, TStr(), true), false},
+		{"function of other arity", goDeclared(pkg, "Format"), method(nil, TStr(), false), false},
+		{"results", goDeclared(pkg, "Parse").internal0, TTuple([]*TTyping{TInt64(), TError()}), true},
+		{"fewer results", goDeclared(pkg, "Parse").internal0, TTuple([]*TTyping{TInt64()}), false},
+		{"results as a value", goDeclared(pkg, "Parse").internal0, TInt64(), false},
+		{"basic", TStr(), TStr(), true},
+		{"other basic", TStr(), TInt64(), false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := sameInGo(test.goType, test.ttype); actual != test.expected {
+				t.Errorf("same %v, expected %v", actual, test.expected)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package types
 
 import (
 	"fmt"
+	"go/types"
 	"strings"
 )
 
@@ -19,7 +20,8 @@ func (t *TTyping) DefaultValue() string {
 		return "false"
 	case TypeNil:
 		return ""
-	case TypeErr:
+	case TypeAny,
+		TypeErr:
 		return "nil"
 	case TypeTuple:
 		elements := make([]string, len(t.elements))
@@ -33,11 +35,13 @@ func (t *TTyping) DefaultValue() string {
 		return fmt.Sprintf("[]%s{}", t.internal0.ToGoType())
 	case TypeMap:
 		return fmt.Sprintf("make(map[%s]%s, 0)", t.internal0.ToGoType(), t.internal1.ToGoType())
-	case TypeFunc:
+	case TypeFunc,
+		TypeChan,
+		TypeFuture:
 		return "nil"
 	case TypeStruct,
 		TypeStructInstance:
-		return t.repr + "{}"
+		return t.structName() + "{}"
 	default:
 		if t.typeId&MASK != 0 {
 			return "nil"
@@ -46,6 +50,17 @@ func (t *TTyping) DefaultValue() string {
 	}
 }
 func (t *TTyping) ToString() string {
+	return t.toString(false)
+}
+
+// Spells a type the way the script does, structs by the name they are
+// declared with rather than their Go name. Types are still compared by
+// ToString, which tells apart structs of the same name.
+func (t *TTyping) ScriptString() string {
+	return t.toString(true)
+}
+
+func (t *TTyping) toString(script bool) string {
 	switch t.typeId {
 	case TypeAny,
 		TypeI08,
@@ -61,47 +76,73 @@ func (t *TTyping) ToString() string {
 	case TypeTuple:
 		elements := make([]string, len(t.elements))
 		for i, element := range t.elements {
-			elements[i] = element.ToString()
+			elements[i] = element.toString(script)
 		}
 		return "(" + strings.Join(elements, ", ") + ")"
 	case TypeArray:
-		return "[" + t.internal0.ToString() + "]"
+		return "[" + t.internal0.toString(script) + "]"
 	case TypeGoArray:
-		return "[]" + t.internal0.ToString() + "{}"
+		return "[]" + t.internal0.toString(script) + "{}"
 	case TypeMap:
-		return "map[" + t.internal0.ToString() + ":" + t.internal1.ToString() + "]" + "{}"
+		return "map[" + t.internal0.toString(script) + ":" + t.internal1.toString(script) + "]" + "{}"
+	case TypeChan:
+		switch t.direction {
+		case ChanSend:
+			return "send chan<" + t.internal0.toString(script) + ">"
+		case ChanRecv:
+			return "receive chan<" + t.internal0.toString(script) + ">"
+		}
+		return "chan<" + t.internal0.toString(script) + ">"
+	case TypeFuture:
+		if t.panics {
+			return "Future<" + t.internal0.toString(script) + " panics>"
+		}
+		return "Future<" + t.internal0.toString(script) + ">"
 	case TypeFunc:
 		parameters := make([]string, len(t.members))
 		for i, parameter := range t.members {
-			parameters[i] = parameter.DataType.ToString()
+			parameters[i] = parameter.DataType.toString(script)
 		}
-		returnType := t.internal0.ToString()
+		returnType := t.internal0.toString(script)
 		str := fmt.Sprintf("func(%s) %s", strings.Join(parameters, ","), returnType)
 		if t.panics {
 			str = str + " panics"
 		}
 		return str
 	case TypeStruct:
-		return "type" + "<" + "struct" + " " + t.repr + "{}" + ">"
+		return "type" + "<" + "struct" + " " + t.structRepr(script) + "{}" + ">"
 	case TypeStructInstance:
-		return t.repr + "{}"
+		return t.structRepr(script) + "{}"
+	case TypeParam,
+		TypeInvalid:
+		return t.repr
 	default:
 		if t.typeId&MASK != 0 {
-			return t.internal0.ToString() + "*"
+			return t.internal0.toString(script) + "*"
 		}
 		panic("invalid type or not implemented")
 	}
 }
 
+func (t *TTyping) structRepr(script bool) string {
+	if script && t.name != "" {
+		return t.name
+	}
+	return t.repr
+}
+
 func (t *TTyping) GoTypePure() string {
 	switch t.typeId {
 	case TypeAny:
 		return GoAny
-	case TypeI08,
-		TypeI16,
-		TypeI32,
-		TypeI64:
-		return GoInt
+	case TypeI08:
+		return GoInt8
+	case TypeI16:
+		return GoInt16
+	case TypeI32:
+		return GoInt32
+	case TypeI64:
+		return GoInt64
 	case TypeNum:
 		return GoFlt
 	case TypeStr:
@@ -126,6 +167,16 @@ func (t *TTyping) GoTypePure() string {
 		return "*Map" + t.internal0.ToNormalName() + t.internal1.ToNormalName()
 	case TypeGoMap:
 		return "map[" + t.internal0.GoTypePure() + "]" + t.internal1.GoTypePure()
+	case TypeChan:
+		switch t.direction {
+		case ChanSend:
+			return "chan<- " + t.internal0.GoTypePure()
+		case ChanRecv:
+			return "<-chan " + t.internal0.GoTypePure()
+		}
+		return "chan " + t.internal0.GoTypePure()
+	case TypeFuture:
+		return "*Future" + t.internal0.ToNormalName()
 	case TypeFunc:
 		returnType := t.internal0.GoTypePure()
 		parameters := make([]string, len(t.members))
@@ -143,7 +194,12 @@ func (t *TTyping) GoTypePure() string {
 		return fmt.Sprintf("func(%s) %s", strings.Join(parameters, ","), returnType)
 	case TypeStruct,
 		TypeStructInstance:
+		return t.structName()
+	case TypeParam:
 		return t.repr
+	case TypeInvalid:
+		// Never written, the file fails with the error that made it
+		return "any"
 	default:
 		if t.typeId&MASK != 0 {
 			return "*" + t.internal0.GoTypePure()
@@ -152,10 +208,34 @@ func (t *TTyping) GoTypePure() string {
 	}
 }
 
+// Go name of a struct, the one it is imported as if it comes from Go.
+func (t *TTyping) structName() string {
+	if t.goName != "" {
+		return t.goName
+	}
+	if t.typeId == TypeStructInstance && t.internal0 != nil && t.internal0.goName != "" {
+		return t.internal0.goName
+	}
+	return t.repr
+}
+
 func (t *TTyping) ToGoType() string {
 	return t.GoTypePure()
 }
 
+// Go type a value of this type has at runtime. Numbers imported from Go keep
+// their original basic type (e.g. "int" or "uint8"), which may differ from
+// the width Parrot Script tracks them with.
+func (t *TTyping) GoValueType() string {
+	if t.compat == nil {
+		return t.GoTypePure()
+	}
+	if basic, ok := t.compat.Underlying().(*types.Basic); ok && IsAnyNumber(t) {
+		return basic.Name()
+	}
+	return t.GoTypePure()
+}
+
 func (t *TTyping) ToNormalName() string {
 	switch t.typeId {
 	case TypeAny,
@@ -184,6 +264,16 @@ func (t *TTyping) ToNormalName() string {
 	case TypeMap,
 		TypeGoMap:
 		return "Map_" + t.internal0.ToNormalName() + "_" + t.internal1.ToNormalName()
+	case TypeChan:
+		switch t.direction {
+		case ChanSend:
+			return "SendChan_" + t.internal0.ToNormalName()
+		case ChanRecv:
+			return "RecvChan_" + t.internal0.ToNormalName()
+		}
+		return "Chan_" + t.internal0.ToNormalName()
+	case TypeFuture:
+		return "Future_" + t.internal0.ToNormalName()
 	case TypeFunc:
 		parameters_normal_name := ""
 		for i, parameter := range t.members {
@@ -194,7 +284,9 @@ func (t *TTyping) ToNormalName() string {
 		}
 		return "func" + "_" + t.internal0.ToNormalName() + "_" + parameters_normal_name
 	case TypeStruct,
-		TypeStructInstance:
+		TypeStructInstance,
+		TypeParam,
+		TypeInvalid:
 		return t.repr
 	default:
 		if t.typeId&MASK != 0 {
@@ -0,0 +1,235 @@
+package types
+
+import (
+	"fmt"
+	"go/types"
+)
+
+// Generic Go functions
+// A script cannot spell type arguments, so a call to a generic function
+// is instantiated from the types of its arguments. Each parameter type is
+// matched against the argument type, binding the type parameters it uses,
+// then the constraints are checked with go/types.
+
+func IsTypeParam(ttype *TTyping) bool {
+	return ttype.typeId == TypeParam
+}
+
+func IsGeneric(ttype *TTyping) bool {
+	return IsFunc(ttype) && len(ttype.typeParams) > 0
+}
+
+// Returns the core type of a type parameter, the underlying type that
+// every type of its constraint shares, such as []E for ~[]E.
+// Returns nil if the constraint has no core type, or only a basic one.
+func goCoreType(param *types.TypeParam) types.Type {
+	iface, ok := param.Constraint().Underlying().(*types.Interface)
+	if !ok || iface.NumEmbeddeds() != 1 {
+		return nil
+	}
+	core := iface.EmbeddedType(0)
+	if union, ok := core.(*types.Union); ok {
+		if union.Len() != 1 {
+			return nil
+		}
+		core = union.Term(0).Type()
+	}
+	switch core.Underlying().(type) {
+	case *types.Slice, *types.Map, *types.Chan, *types.Pointer, *types.Signature:
+		return core.Underlying()
+	}
+	return nil
+}
+
+// Instantiates the generic function funcType with the types of its arguments.
+// Returns the function with its type parameters replaced, and the type
+// arguments in the order of the type parameters.
+func Instantiate(funcType *TTyping, arguments []*TTyping) (*TTyping, []*TTyping, error) {
+	bindings := make(map[types.Type]*tBinding)
+	parameters := funcType.members
+	for index, argument := range arguments {
+		var parameter *TTyping
+		switch {
+		case funcType.variadic && index >= len(parameters)-1:
+			parameter = parameters[len(parameters)-1].DataType
+		case index < len(parameters):
+			parameter = parameters[index].DataType
+		default:
+			continue
+		}
+		if err := unify(parameter, argument, bindings, false); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, param := range funcType.typeParams {
+		if param.internal0 != nil {
+			bindings[param.compat] = &tBinding{substitute(param.internal0, bindings), true}
+		}
+	}
+
+	typeArguments := make([]*TTyping, 0, len(funcType.typeParams))
+	goArguments := make([]types.Type, 0, len(funcType.typeParams))
+	for _, param := range funcType.typeParams {
+		bound, ok := bindings[param.compat]
+		if !ok || hasTypeParam(bound.dataType) {
+			return nil, nil, fmt.Errorf("cannot infer type argument %s of %s", param.repr, funcType.ToString())
+		}
+		typeArguments = append(typeArguments, bound.dataType)
+		goArguments = append(goArguments, goTypeOf(bound.dataType))
+	}
+
+	// Types of the script, such as arrays, have no go/types counterpart,
+	// then Go checks the constraints when it compiles the instantiation
+	validate := true
+	for _, goArgument := range goArguments {
+		validate = validate && goArgument != nil
+	}
+	if signature, ok := funcType.compat.(*types.Signature); ok && validate {
+		if _, err := types.Instantiate(nil, signature, goArguments, true); err != nil {
+			return nil, nil, err
+		}
+	}
+	return substitute(funcType, bindings), typeArguments, nil
+}
+
+// A type parameter bound to a type. An exact binding comes from a type
+// that Go does not convert, such as the element type of a slice, while
+// a plain argument can widen the binding, as for max(1, 2.5).
+type tBinding struct {
+	dataType *TTyping
+	exact    bool
+}
+
+// Binds the type parameters that parameter uses from the argument type.
+func unify(parameter *TTyping, argument *TTyping, bindings map[types.Type]*tBinding, exact bool) error {
+	switch {
+	case IsTypeParam(parameter) && parameter.internal0 != nil:
+		// Bound from its core type once every argument is unified
+		return unify(parameter.internal0, argument, bindings, exact)
+	case IsTypeParam(parameter):
+		bound, ok := bindings[parameter.compat]
+		switch {
+		case !ok:
+			bindings[parameter.compat] = &tBinding{argument, exact}
+		case IsTheSameInstance(bound.dataType, argument):
+			bound.exact = bound.exact || exact
+		case !bound.exact && CanStore(argument, bound.dataType):
+			// Widened, the arguments bound so far are converted
+			bindings[parameter.compat] = &tBinding{argument, exact}
+		case !exact && CanStore(bound.dataType, argument):
+			// The argument is converted
+		default:
+			return fmt.Errorf("type parameter %s cannot be both %s and %s", parameter.repr, bound.dataType.ToString(), argument.ToString())
+		}
+	case IsGoArray(parameter) && (IsArray(argument) || IsGoArray(argument)):
+		return unify(parameter.internal0, argument.internal0, bindings, true)
+	case IsGoMap(parameter) && (IsMap(argument) || IsGoMap(argument)):
+		if err := unify(parameter.internal0, argument.internal0, bindings, true); err != nil {
+			return err
+		}
+		return unify(parameter.internal1, argument.internal1, bindings, true)
+	case IsChan(parameter) && IsChan(argument):
+		return unify(parameter.internal0, argument.internal0, bindings, true)
+	case IsFunc(parameter) && IsFunc(argument) && len(parameter.members) == len(argument.members):
+		for index, member := range parameter.members {
+			if err := unify(member.DataType, argument.members[index].DataType, bindings, true); err != nil {
+				return err
+			}
+		}
+		return unify(parameter.internal0, argument.internal0, bindings, true)
+	case IsPointer(parameter) && IsPointer(argument) && !IsVoidPointer(argument):
+		return unify(parameter.internal0, argument.internal0, bindings, true)
+	}
+	return nil
+}
+
+// Replaces the bound type parameters in t.
+func substitute(t *TTyping, bindings map[types.Type]*tBinding) *TTyping {
+	switch {
+	case IsTypeParam(t):
+		if bound, ok := bindings[t.compat]; ok {
+			return bound.dataType
+		}
+		return t
+	case IsGoArray(t):
+		return TGoArray(substitute(t.internal0, bindings))
+	case IsGoMap(t):
+		return TGoHashMap(substitute(t.internal0, bindings), substitute(t.internal1, bindings))
+	case IsChan(t):
+		return TChan(substitute(t.internal0, bindings), t.direction)
+	case IsTuple(t):
+		elements := make([]*TTyping, len(t.elements))
+		for index, element := range t.elements {
+			elements[index] = substitute(element, bindings)
+		}
+		return TTuple(elements)
+	case IsFunc(t):
+		members := make([]*TPair, len(t.members))
+		for index, member := range t.members {
+			members[index] = CreatePairWithNamespace(member.Name, member.Namespace, substitute(member.DataType, bindings))
+		}
+		fn := TFunc(t.variadic, members, substitute(t.internal0, bindings), t.panics)
+		return SetPointerReceiver(fn, t.pointer)
+	case IsPointer(t) && t.internal0 != nil && !IsVoidPointer(t):
+		return ToPointer(substitute(t.internal0, bindings))
+	}
+	return t
+}
+
+// Reports whether t still uses a type parameter, one that could not be inferred.
+func hasTypeParam(t *TTyping) bool {
+	switch {
+	case IsTypeParam(t):
+		return true
+	case IsGoArray(t), IsChan(t):
+		return hasTypeParam(t.internal0)
+	case IsGoMap(t):
+		return hasTypeParam(t.internal0) || hasTypeParam(t.internal1)
+	case IsPointer(t) && t.internal0 != nil && !IsVoidPointer(t):
+		return hasTypeParam(t.internal0)
+	}
+	return false
+}
+
+// Returns the go/types type of a type argument,
+// or nil if it is a type of the script that Go does not know of.
+func goTypeOf(t *TTyping) types.Type {
+	if t.compat != nil && !IsTypeParam(t) {
+		return t.compat
+	}
+	switch {
+	case IsInt08(t):
+		return types.Typ[types.Int8]
+	case IsInt16(t):
+		return types.Typ[types.Int16]
+	case IsInt32(t):
+		return types.Typ[types.Int32]
+	case IsInt64(t):
+		return types.Typ[types.Int64]
+	case IsNum(t):
+		return types.Typ[types.Float64]
+	case IsStr(t):
+		return types.Typ[types.String]
+	case IsBool(t):
+		return types.Typ[types.Bool]
+	case IsAny(t):
+		return types.Universe.Lookup("any").Type()
+	case IsError(t):
+		return types.Universe.Lookup("error").Type()
+	case IsGoArray(t):
+		if element := goTypeOf(t.internal0); element != nil {
+			return types.NewSlice(element)
+		}
+	case IsGoMap(t):
+		key := goTypeOf(t.internal0)
+		value := goTypeOf(t.internal1)
+		if key != nil && value != nil {
+			return types.NewMap(key, value)
+		}
+	case IsPointer(t) && t.internal0 != nil && !IsVoidPointer(t):
+		if element := goTypeOf(t.internal0); element != nil {
+			return types.NewPointer(element)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package types
+
+import (
+	"go/types"
+	"strings"
+	"testing"
+)
+
+const genericSource = `
+func Max[T int64 | float64](a, b T) T { return a }
+func Map[T, U any](s []T, f func(T) U) []U { return nil }
+func Keys[K comparable, V any](m map[K]V) []K { return nil }
+func Zero[T any]() T { var zero T; return zero }
+func Ptr[T any](p *T) T { return *p }
+`
+
+func TestInstantiate(t *testing.T) {
+	pkg := goPackage(t, genericSource)
+	point := ToInstance(TStruct("Point", []*TPair{CreatePair("X", TInt64())}))
+	tests := []struct {
+		name      string
+		function  string
+		arguments []*TTyping
+		expected  string
+		typeArgs  string
+		err       string
+	}{
+		{"same types", "Max", []*TTyping{TInt64(), TInt64()}, "func(i64,i64) i64", "i64", ""},
+		{"widened to the later argument", "Max", []*TTyping{TInt64(), TNum()}, "func(num,num) num", "num", ""},
+		{"widened integer", "Max", []*TTyping{TInt08(), TInt64()}, "func(i64,i64) i64", "i64", ""},
+		{"converted to the earlier argument", "Max", []*TTyping{TNum(), TInt32()}, "func(num,num) num", "num", ""},
+		{"conflicting types", "Max", []*TTyping{TInt64(), TStr()}, "", "", "type parameter T cannot be both i64 and str"},
+		{"unsatisfied constraint", "Max", []*TTyping{TStr(), TStr()}, "", "", "does not satisfy"},
+		{"array and function", "Map", []*TTyping{TArray(TInt64()), TFunc(false, []*TPair{CreatePair("n", TInt64())}, TStr(), false)}, "func([]i64{},func(i64) str) []str{}", "i64 str", ""},
+		{"element types are exact", "Map", []*TTyping{TArray(TInt64()), TFunc(false, []*TPair{CreatePair("n", TInt32())}, TStr(), false)}, "", "", "type parameter T cannot be both i64 and i32"},
+		{"map", "Keys", []*TTyping{THashMap(TStr(), TInt32())}, "", "str i32", ""},
+		{"pointer", "Ptr", []*TTyping{ToPointer(point)}, "func(Point{}*) Point{}", "Point{}", ""},
+		{"no argument", "Zero", []*TTyping{}, "", "", "cannot infer type argument T"},
+		{"argument of another type", "Map", []*TTyping{TStr(), TStr()}, "", "", "cannot infer type argument T"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			function := TFromGoObject(pkg.Scope().Lookup(test.function))
+			if !IsGeneric(function) {
+				t.Fatalf("%s is not generic", test.function)
+			}
+			instance, typeArguments, err := Instantiate(function, test.arguments)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("error %v, expected one with %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			// A Go map has no spelling, the map case only checks the type arguments
+			if test.expected != "" && instance.ToString() != test.expected {
+				t.Errorf("instantiated as %s, expected %s", instance.ToString(), test.expected)
+			}
+			spelled := make([]string, len(typeArguments))
+			for index, typeArgument := range typeArguments {
+				spelled[index] = typeArgument.ToString()
+			}
+			if strings.Join(spelled, " ") != test.typeArgs {
+				t.Errorf("type arguments %v, expected %s", spelled, test.typeArgs)
+			}
+		})
+	}
+}
+
+// An exact binding, such as the element type of an array, is not widened
+// by a later argument, while a plain one is.
+func TestUnify(t *testing.T) {
+	pkg := goPackage(t, genericSource)
+	param := TFromGoObject(pkg.Scope().Lookup("Max")).members[0].DataType
+	tests := []struct {
+		name      string
+		arguments []*TTyping
+		exact     []bool
+		expected  string
+		err       bool
+	}{
+		{"plain then wider", []*TTyping{TInt32(), TInt64()}, []bool{false, false}, "i64", false},
+		{"plain then narrower", []*TTyping{TInt64(), TInt32()}, []bool{false, false}, "i64", false},
+		{"exact then wider", []*TTyping{TInt32(), TInt64()}, []bool{true, false}, "", true},
+		{"exact then narrower", []*TTyping{TInt64(), TInt32()}, []bool{true, false}, "i64", false},
+		{"plain then exact narrower", []*TTyping{TInt64(), TInt32()}, []bool{false, true}, "", true},
+		{"plain then exact same", []*TTyping{TInt64(), TInt64()}, []bool{false, true}, "i64", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bindings := make(map[types.Type]*tBinding)
+			var err error
+			for index, argument := range test.arguments {
+				if err = unify(param, argument, bindings, test.exact[index]); err != nil {
+					break
+				}
+			}
+			if test.err {
+				if err == nil {
+					t.Fatalf("bound to %s, expected an error", bindings[param.compat].dataType.ToString())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bound := bindings[param.compat].dataType.ToString(); bound != test.expected {
+				t.Errorf("bound to %s, expected %s", bound, test.expected)
+			}
+		})
+	}
+}
@@ -30,6 +30,10 @@ const (
 	TypeTuple
 	TypeGoArray // For go array
 	TypeGoMap   // For go map
+	TypeChan
+	TypeFuture
+	TypeParam   // For go type parameter
+	TypeInvalid // For a value whose analysis failed
 	MASK
 )
 
@@ -67,6 +71,20 @@ const (
 
 // ============== END ================
 
+// ===================================
+//        Channel Direction          //
+// ===================================
+
+type TChanDir int
+
+const (
+	ChanBoth TChanDir = iota
+	ChanSend          // Send only, from Go "chan<- T"
+	ChanRecv          // Receive only, from Go "<-chan T"
+)
+
+// ============== END ================
+
 type TPair struct {
 	Name      string
 	Namespace string
@@ -99,10 +117,15 @@ type TTyping struct {
 	methods        []*TPair   // Type methods
 	variadic       bool       // Function variadic
 	panics         bool       // Function panics
+	pointer        bool       // Method has a pointer receiver
+	direction      TChanDir   // Channel direction
 	hasConstructor bool
 	instance0      *TTyping // Instance of this type
 	instance1      *TTyping // Instance of this type
 	compat         types.Type
+	goName         string     // Go spelling of a struct imported from Go
+	name           string     // Name of a struct in the script that declares it
+	typeParams     []*TTyping // Function type parameters
 }
 
 func (t *TTyping) Variadic() bool {
@@ -113,6 +136,18 @@ func (t *TTyping) Panics() bool {
 	return t.panics
 }
 
+func (t *TTyping) PointerReceiver() bool {
+	return t.pointer
+}
+
+func (t *TTyping) CanSend() bool {
+	return t.direction != ChanRecv
+}
+
+func (t *TTyping) CanReceive() bool {
+	return t.direction != ChanSend
+}
+
 func (t *TTyping) HasConstructor() bool {
 	return t.hasConstructor
 }
@@ -246,6 +281,12 @@ func TError() *TTyping {
 	return CreateTyping("error", TypeErr)
 }
 
+// The type of a declaration whose analysis failed, its uses report no
+// errors of their own.
+func TInvalid() *TTyping {
+	return CreateTyping("invalid", TypeInvalid)
+}
+
 func TTuple(elements []*TTyping) *TTyping {
 	typing := CreateTyping("[OVERRIDEME]", TypeTuple)
 	typing.elements = elements
@@ -323,6 +364,20 @@ func THashMap(key *TTyping, val *TTyping) *TTyping {
 	return typing
 }
 
+func TChan(element *TTyping, direction TChanDir) *TTyping {
+	typing := CreateTyping("[OVERRIDEME]", TypeChan)
+	typing.internal0 = element
+	typing.direction = direction
+	return typing
+}
+
+func TFuture(result *TTyping, panics bool) *TTyping {
+	typing := CreateTyping("[OVERRIDEME]", TypeFuture)
+	typing.internal0 = result
+	typing.panics = panics
+	return typing
+}
+
 func TStruct(name string, attributes []*TPair) *TTyping {
 	typing := CreateTyping(name, TypeStruct)
 	typing.hasConstructor = true // Struct has constructor, if it was defined by user and not from go
@@ -339,17 +394,98 @@ func TFunc(variadic bool, attributes []*TPair, returnType *TTyping, panics bool)
 	return typing
 }
 
+// A type parameter of a generic Go function. Its core type, the one
+// every type of its constraint shares such as []E for ~[]E, is kept
+// in internal0 so that arguments can be matched against it.
+func TParam(param *types.TypeParam, core *TTyping) *TTyping {
+	typing := CreateTyping(param.Obj().Name(), TypeParam)
+	typing.internal0 = core
+	typing.compat = param
+	return typing
+}
+
+func SetPointerReceiver(typing *TTyping, pointer bool) *TTyping {
+	typing.pointer = pointer
+	return typing
+}
+
+// Returns a copy of a struct imported from Go, spelled with the name it
+// is imported as. The struct converted from Go is shared by the files of
+// a compile, each of them imports it under a name of its own.
+func WithGoName(typing *TTyping, name string) *TTyping {
+	named := *typing
+	named.goName = name
+	named.instance0 = nil
+	named.instance1 = nil
+	return &named
+}
+
+// Names a struct of the script as the script spells it, its repr is
+// the Go name it is declared with.
+func SetScriptName(typing *TTyping, name string) *TTyping {
+	typing.name = name
+	return typing
+}
+
+// Returns the Go type a type converted from Go stands for, nil for a
+// type of the script. A pointer stands for the type it points to.
+func GoType(typing *TTyping) types.Type {
+	if IsPointer(typing) && typing.internal0 != nil {
+		typing = typing.internal0
+	}
+	return typing.compat
+}
+
 func SetCompat(typing *TTyping, compat types.Type) *TTyping {
 	typing.compat = compat
 	return typing
 }
 
-func TFromGoTypes(t types.Type) *TTyping {
+// Converts Go types to typings. A named type is converted once, so that
+// the methods that refer to it resolve to the typing it is converted to.
+// A compile holds one converter, the packages loaded by another compile
+// have other named types.
+type TGoTypes struct {
+	named map[*types.Named]*TTyping
+}
+
+func CreateGoTypes() *TGoTypes {
+	return &TGoTypes{named: make(map[*types.Named]*TTyping)}
+}
+
+// Converts the type of a Go object. A type name is the struct type
+// itself, anything else is a value of its type.
+func (g *TGoTypes) FromObject(object types.Object) *TTyping {
+	typing := g.FromType(object.Type())
+	if _, ok := object.(*types.TypeName); ok && IsStructInstance(typing) {
+		return typing.internal0
+	}
+	return typing
+}
+
+func (g *TGoTypes) FromType(t types.Type) *TTyping {
 	// Use a map to track types being processed to detect recursion
-	return tFromGoTypesWithVisited(t, make(map[types.Type]bool))
+	return g.tFromGoTypesWithVisited(t, make(map[types.Type]bool))
 }
 
-func tFromGoTypesWithVisited(t types.Type, visited map[types.Type]bool) *TTyping {
+// Converts the type of a Go object on its own, see TGoTypes.
+func TFromGoObject(object types.Object) *TTyping {
+	return CreateGoTypes().FromObject(object)
+}
+
+// Converts a Go type on its own, see TGoTypes.
+func TFromGoTypes(t types.Type) *TTyping {
+	return CreateGoTypes().FromType(t)
+}
+
+func (g *TGoTypes) tFromGoTypesWithVisited(t types.Type, visited map[types.Type]bool) *TTyping {
+	// An alias, such as os.FileMode for fs.FileMode, is the type it names
+	t = types.Unalias(t)
+
+	if named, ok := t.(*types.Named); ok && g.named[named] != nil {
+		return goValue(g.named[named])
+	}
+
 	// Check if we've already seen this type in the current recursion path
 	if visited[t] {
 		return SetCompat(TAny(), t.Underlying()) // Break recursion by returning Any
@@ -368,8 +504,10 @@ func tFromGoTypesWithVisited(t types.Type, visited map[types.Type]bool) *TTyping
 		return SetCompat(TError(), t.Underlying())
 	}
 
-	// Handle `any` or `interface{}` with no methods
-	if iface, ok := t.Underlying().(*types.Interface); ok && iface.NumMethods() == 0 {
+	// Handle `any` or `interface{}` with no methods,
+	// a type parameter has its constraint as underlying type
+	_, isParam := t.(*types.TypeParam)
+	if iface, ok := t.Underlying().(*types.Interface); ok && iface.NumMethods() == 0 && !isParam {
 		return SetCompat(TAny(), t.Underlying())
 	}
 
@@ -417,22 +555,33 @@ func tFromGoTypesWithVisited(t types.Type, visited map[types.Type]bool) *TTyping
 		case *types.Array:
 			elem = a.Elem()
 		}
-		et := tFromGoTypesWithVisited(elem, visited)
+		et := g.tFromGoTypesWithVisited(elem, visited)
 		if et == nil {
 			return SetCompat(TAny(), t.Underlying()) // Fallback to Any for unknown element types
 		}
 		return SetCompat(TGoArray(et), t.Underlying())
 
+	case *types.Chan:
+		et := g.tFromGoTypesWithVisited(tt.Elem(), visited)
+		direction := ChanBoth
+		switch tt.Dir() {
+		case types.SendOnly:
+			direction = ChanSend
+		case types.RecvOnly:
+			direction = ChanRecv
+		}
+		return SetCompat(TChan(et, direction), t.Underlying())
+
 	case *types.Pointer:
-		et := tFromGoTypesWithVisited(tt.Elem(), visited)
+		et := g.tFromGoTypesWithVisited(tt.Elem(), visited)
 		if et == nil {
 			return SetCompat(TAny(), t.Underlying()) // Fallback to Any for unknown pointer types
 		}
 		return SetCompat(ToPointer(et), t.Underlying())
 
 	case *types.Map:
-		kt := tFromGoTypesWithVisited(tt.Key(), visited)
-		vt := tFromGoTypesWithVisited(tt.Elem(), visited)
+		kt := g.tFromGoTypesWithVisited(tt.Key(), visited)
+		vt := g.tFromGoTypesWithVisited(tt.Elem(), visited)
 		if kt == nil || vt == nil {
 			return SetCompat(TAny(), t.Underlying()) // Fallback to Any for invalid map types
 		}
@@ -443,7 +592,7 @@ func tFromGoTypesWithVisited(t types.Type, visited map[types.Type]bool) *TTyping
 		params := make([]*TPair, 0, tt.Params().Len())
 		for i := 0; i < tt.Params().Len(); i++ {
 			param := tt.Params().At(i)
-			pt := tFromGoTypesWithVisited(param.Type(), visited)
+			pt := g.tFromGoTypesWithVisited(param.Type(), visited)
 			if pt == nil {
 				continue // Skip unsafe/undocumented parameter types
 			}
@@ -457,7 +606,7 @@ func tFromGoTypesWithVisited(t types.Type, visited map[types.Type]bool) *TTyping
 			// For variadic parameters, use only the element type
 			if tt.Variadic() && i == tt.Params().Len()-1 {
 				if slice, ok := param.Type().(*types.Slice); ok {
-					pt = tFromGoTypesWithVisited(slice.Elem(), visited)
+					pt = g.tFromGoTypesWithVisited(slice.Elem(), visited)
 					if pt == nil {
 						pt = SetCompat(TAny(), t.Underlying()) // Fallback if element type is unknown
 					}
@@ -472,7 +621,7 @@ func tFromGoTypesWithVisited(t types.Type, visited map[types.Type]bool) *TTyping
 		case 0:
 			ret = TVoid()
 		case 1:
-			rt := tFromGoTypesWithVisited(tt.Results().At(0).Type(), visited)
+			rt := g.tFromGoTypesWithVisited(tt.Results().At(0).Type(), visited)
 			if rt == nil {
 				ret = SetCompat(TAny(), t.Underlying()) // Fallback for unknown return type
 			} else {
@@ -482,7 +631,7 @@ func tFromGoTypesWithVisited(t types.Type, visited map[types.Type]bool) *TTyping
 			tuple := make([]*TTyping, 0, tt.Results().Len())
 			hasInvalidType := false
 			for i := 0; i < tt.Results().Len(); i++ {
-				rt := tFromGoTypesWithVisited(tt.Results().At(i).Type(), visited)
+				rt := g.tFromGoTypesWithVisited(tt.Results().At(i).Type(), visited)
 				if rt == nil {
 					hasInvalidType = true
 					break
@@ -497,7 +646,18 @@ func tFromGoTypesWithVisited(t types.Type, visited map[types.Type]bool) *TTyping
 			}
 		}
 
-		return SetCompat(TFunc(tt.Variadic(), params, ret, false), t.Underlying())
+		fn := SetCompat(TFunc(tt.Variadic(), params, ret, false), t.Underlying())
+		for i := 0; i < tt.TypeParams().Len(); i++ {
+			fn.typeParams = append(fn.typeParams, g.tFromGoTypesWithVisited(tt.TypeParams().At(i), visited))
+		}
+		return fn
+
+	case *types.TypeParam:
+		var core *TTyping
+		if coreType := goCoreType(tt); coreType != nil {
+			core = g.tFromGoTypesWithVisited(coreType, visited)
+		}
+		return TParam(tt, core)
 
 	case *types.Struct:
 		members := make([]*TPair, 0, tt.NumFields())
@@ -508,7 +668,7 @@ func tFromGoTypesWithVisited(t types.Type, visited map[types.Type]bool) *TTyping
 				continue
 			}
 
-			ft := tFromGoTypesWithVisited(field.Type(), visited)
+			ft := g.tFromGoTypesWithVisited(field.Type(), visited)
 			if ft == nil {
 				continue // Skip unsafe/undocumented field types
 			}
@@ -528,31 +688,16 @@ func tFromGoTypesWithVisited(t types.Type, visited map[types.Type]bool) *TTyping
 	case *types.Named:
 		// If it's the error interface, already handled above
 		under := tt.Underlying()
-		st := tFromGoTypesWithVisited(under, visited)
+		st := g.tFromGoTypesWithVisited(under, visited)
 		if st == nil {
 			return SetCompat(TAny(), t.Underlying()) // Fallback for unknown named types
 		}
 		st.repr = tt.Obj().Name()
-
-		// Add only exported methods from Named type
-		methods := make([]*TPair, 0, tt.NumMethods())
-		for i := 0; i < tt.NumMethods(); i++ {
-			m := tt.Method(i)
-			// Skip unexported methods (internal implementation details)
-			if !m.Exported() {
-				continue
-			}
-
-			mt := tFromGoTypesWithVisited(m.Type(), visited)
-			if mt == nil {
-				continue // Skip unsafe/undocumented method types
-			}
-			methods = append(methods, CreatePair(m.Name(), mt))
-		}
-		st.methods = methods
-
-		return SetCompat(st, t.Underlying())
-
+		st.compat = tt
+		// Methods that refer to the type itself resolve to this typing
+		g.named[tt] = st
+		setMethods(st, g.goMethods(tt, visited))
+		return goValue(st)
 	case *types.Interface:
 		// Create interface with only exported methods
 		if tt.NumMethods() > 0 {
@@ -564,7 +709,7 @@ func tFromGoTypesWithVisited(t types.Type, visited map[types.Type]bool) *TTyping
 					continue
 				}
 
-				mt := tFromGoTypesWithVisited(m.Type(), visited)
+				mt := g.tFromGoTypesWithVisited(m.Type(), visited)
 				if mt == nil {
 					continue
 				}
@@ -586,6 +731,52 @@ func tFromGoTypesWithVisited(t types.Type, visited map[types.Type]bool) *TTyping
 	}
 }
 
+// A value of a Go struct is an instance of it.
+func goValue(typing *TTyping) *TTyping {
+	if IsStruct(typing) {
+		return ToInstance(typing)
+	}
+	return typing
+}
+
+// Collects the exported methods of a named Go type: the ones of its
+// pointer, which include the ones promoted from embedded fields.
+// Methods that only the pointer has are marked as pointer receivers.
+func (g *TGoTypes) goMethods(named *types.Named, visited map[types.Type]bool) []*TPair {
+	values := types.NewMethodSet(named)
+	all := values
+	if _, ok := named.Underlying().(*types.Interface); !ok {
+		all = types.NewMethodSet(types.NewPointer(named))
+	}
+	methods := make([]*TPair, 0, all.Len())
+	for i := 0; i < all.Len(); i++ {
+		m := all.At(i).Obj()
+		// Skip unexported methods (internal implementation details)
+		if !m.Exported() {
+			continue
+		}
+
+		mt := g.tFromGoTypesWithVisited(m.Type(), visited)
+		if mt == nil {
+			continue // Skip unsafe/undocumented method types
+		}
+		SetPointerReceiver(mt, values.Lookup(m.Pkg(), m.Name()) == nil)
+		methods = append(methods, CreatePairWithNamespace(m.Name(), m.Name(), mt))
+	}
+	return methods
+}
+
+// Sets the methods of typing and of the instances made from it so far.
+func setMethods(typing *TTyping, methods []*TPair) {
+	typing.methods = methods
+	if typing.instance0 != nil {
+		setMethods(typing.instance0, methods)
+	}
+	if typing.instance1 != nil {
+		typing.instance1.methods = methods
+	}
+}
+
 func ToInstance(typing *TTyping) *TTyping {
 	if !IsStruct(typing) {
 		panic("invalid type or not implemented")
@@ -602,6 +793,8 @@ func ToInstance(typing *TTyping) *TTyping {
 	typing.instance0.variadic = false
 	typing.instance0.panics = false
 	typing.instance0.hasConstructor = typing.hasConstructor
+	typing.instance0.compat = typing.compat
+	typing.instance0.name = typing.name
 	return typing.instance0
 }
 
@@ -614,7 +807,7 @@ func ToPointer(typing *TTyping) *TTyping {
 	typing.instance1.internal0 = typing
 	typing.instance1.internal1 = nil
 	typing.instance1.elements = nil
-	typing.instance1.methods = typing.methods
+	typing.instance1.methods = append(make([]*TPair, 0, len(typing.methods)), typing.methods...)
 	typing.instance1.members = typing.members
 	typing.instance1.variadic = false
 	typing.instance1.panics = false
@@ -622,9 +815,45 @@ func ToPointer(typing *TTyping) *TTyping {
 	return typing.instance1
 }
 
-func WhichBigger(a *TTyping, b *TTyping) *TTyping {
-	if a.typeId > b.typeId {
-		return a
+// Records a method on both the value and the pointer type,
+// so that instances and pointers to them share one method set.
+func AddReceiverMethod(receiver *TTyping, name string, namespace string, dataType *TTyping) {
+	value := receiver
+	if IsPointer(receiver) && receiver.internal0 != nil {
+		value = receiver.internal0
+	}
+	value.AddMethod(name, namespace, dataType)
+	ToPointer(value).AddMethod(name, namespace, dataType)
+}
+
+// Rank of a numeric type in the widening order i8 < i16 < i32 < i64 < num.
+func numericRank(t *TTyping) int {
+	switch {
+	case IsInt08(t):
+		return 1
+	case IsInt16(t):
+		return 2
+	case IsInt32(t):
+		return 3
+	case IsInt64(t):
+		return 4
+	case IsNum(t):
+		return 5
 	}
-	return b
+	return 0
+}
+
+// Result type of an arithmetic operator over two numbers: the wider operand,
+// where any integer mixed with num yields num. Both operands are converted to
+// this type before the operation, so the result never depends on Go's rules.
+func BinaryResult(a *TTyping, b *TTyping) *TTyping {
+	if numericRank(b) > numericRank(a) {
+		return b
+	}
+	return a
+}
+
+// Deprecated: WhichBigger compared type codes, it is now BinaryResult.
+func WhichBigger(a *TTyping, b *TTyping) *TTyping {
+	return BinaryResult(a, b)
 }